@@ -1,18 +1,179 @@
 // Package dynamo contains controls and objects for DynamoDB CRUD operations.
 // Operations in this package are abstracted from all other application logic
 // and are designed to be used with any DynamoDB table and any object schema.
-// This file contains objects for implementing an exponential backoff
-// algorithm for DynamoDB error handling.
+// This file contains the retry policies used to back off and retry throttled
+// requests and unprocessed batch items.
 package dynamo
 
 import (
+	"context"
+	"errors"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"time"
 )
 
+// RetryPolicy creates the Retriers that decide when failed requests are retried.
+// Policies are used by concurrent operations and must not be modified by Start.
+type RetryPolicy interface {
+	// Start returns a Retrier for one operation.
+	Start() Retrier
+}
+
+// Retrier tracks the retries of one operation. Retriers are used by a single goroutine.
+type Retrier interface {
+	// Wait waits before the next attempt of the operation. Returns ErrRetriesExhausted
+	// if no more attempts may be made, or ctx's error if ctx is done first.
+	Wait(ctx context.Context) error
+}
+
+// Clock tells the time and waits for the Backoff policy.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// systemClock is the Clock of the time package.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Jitter selects how a Backoff randomizes its waits.
+type Jitter int
+
+const (
+	// FullJitter waits a random time between 0 and the exponential delay.
+	FullJitter Jitter = iota
+	// EqualJitter waits half the exponential delay plus a random time up to the other half.
+	EqualJitter
+	// DecorrelatedJitter waits a random time between Base and 3 times the previous wait.
+	DecorrelatedJitter
+)
+
+// Backoff is a RetryPolicy that waits exponentially longer between attempts,
+// randomized by Jitter. Zero limits are not enforced.
+type Backoff struct {
+	Jitter Jitter
+	// Base is the delay of the first retry.
+	Base time.Duration
+	// Cap is the maximum wait between attempts.
+	Cap time.Duration
+	// MaxAttempts is the maximum number of attempts, including the first.
+	MaxAttempts int
+	// MaxElapsed is the maximum time from the first attempt to the last.
+	MaxElapsed time.Duration
+	// Clock defaults to the system clock.
+	Clock Clock
+}
+
+// DefaultRetryPolicy returns the default Backoff: full jitter from a base of
+// 50 milliseconds, up to 10 attempts within 1 minute.
+func DefaultRetryPolicy() *Backoff {
+	return &Backoff{
+		Jitter:      FullJitter,
+		Base:        50 * time.Millisecond,
+		Cap:         10 * time.Second,
+		MaxAttempts: 10,
+		MaxElapsed:  time.Minute,
+	}
+}
+
+// Start returns a Retrier for one operation.
+func (b *Backoff) Start() Retrier {
+	clock := b.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return &backoffRetrier{policy: *b, clock: clock, start: clock.Now(), prev: b.Base}
+}
+
+// delay returns the wait before the given retry, counted from 1, after a previous wait of prev.
+func (b *Backoff) delay(retry int, prev time.Duration) time.Duration {
+	capped := func(d time.Duration) time.Duration {
+		if b.Cap > 0 && d > b.Cap {
+			return b.Cap
+		}
+		return d
+	}
+	random := func(d time.Duration) time.Duration {
+		if d <= 0 {
+			return 0
+		}
+		return rand.N(d + 1)
+	}
+
+	if b.Jitter == DecorrelatedJitter {
+		prev = min(max(prev, b.Base), math.MaxInt64/3)
+		return capped(b.Base + random(3*prev-b.Base))
+	}
+	exp := time.Duration(math.MaxInt64)
+	if retry-1 < 62 && b.Base <= time.Duration(math.MaxInt64>>(retry-1)) {
+		exp = b.Base << (retry - 1)
+	}
+	exp = capped(exp)
+	if b.Jitter == EqualJitter {
+		return exp/2 + random(exp-exp/2)
+	}
+	return random(exp)
+}
+
+// backoffRetrier is the Retrier of a Backoff.
+type backoffRetrier struct {
+	policy   Backoff
+	clock    Clock
+	start    time.Time
+	attempts int
+	prev     time.Duration
+}
+
+// Wait waits before the next attempt.
+func (r *backoffRetrier) Wait(ctx context.Context) error {
+	r.attempts++
+	if r.policy.MaxAttempts > 0 && r.attempts >= r.policy.MaxAttempts {
+		return ErrRetriesExhausted
+	}
+	delay := r.policy.delay(r.attempts, r.prev)
+	r.prev = delay
+	if r.policy.MaxElapsed > 0 && r.clock.Now().Sub(r.start)+delay > r.policy.MaxElapsed {
+		return ErrRetriesExhausted
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.clock.After(delay):
+		return nil
+	}
+}
+
+// retryThrottled calls fn, retrying throttled requests with policy. Returns
+// fn's last error, or ctx's error if ctx is done while waiting.
+func retryThrottled(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	r := policy.Start()
+	for {
+		err := fn()
+		if err == nil || !errors.Is(handleErr(err), ErrRateLimitExceeded) {
+			return err
+		}
+		if werr := r.Wait(ctx); werr != nil {
+			if errors.Is(werr, ErrRetriesExhausted) {
+				return err
+			}
+			return werr
+		}
+	}
+}
+
 // FailConfig stores parameters for the exponential backoff algorithm.
 // Attempt, Elapsed, MaxRetiresReached should always be initialized to 0, 0, false.
+//
+// Deprecated: FailConfig's retry state is shared by every operation using it.
+// Use a RetryPolicy such as Backoff. As a RetryPolicy, a FailConfig retries
+// with full jitter from a base of Base ms for up to Cap ms, and its state
+// fields are not used.
 type FailConfig struct {
 	Base              float64
 	Cap               float64
@@ -23,24 +184,47 @@ type FailConfig struct {
 
 // DefaultFailConfig is the default configuration for the exponential backoff alogrithm
 // with a base wait time of 50 miliseconds, and max wait time of 1 minute (60000 ms).
+//
+// Deprecated: Use DefaultRetryPolicy.
 var DefaultFailConfig = &FailConfig{50, 60000, 0, 0, false}
 
+// Start returns a Retrier for one operation. A nil FailConfig uses DefaultRetryPolicy
+// and a FailConfig without a Cap does not retry.
+func (fc *FailConfig) Start() Retrier {
+	if fc == nil {
+		return DefaultRetryPolicy().Start()
+	}
+	b := &Backoff{
+		Jitter:     FullJitter,
+		Base:       time.Duration(fc.Base * float64(time.Millisecond)),
+		MaxElapsed: time.Duration(fc.Cap * float64(time.Millisecond)),
+	}
+	if fc.Cap <= 0 {
+		b.MaxAttempts = 1
+	}
+	return b.Start()
+}
+
 // ExponentialBackoff implements the exponential backoff algorithm for request retries
 // and returns true when the max number of retries has been reached (fc.Elapsed > fc.Cap).
+//
+// Deprecated: Use a Retrier returned by a RetryPolicy.
 func (fc *FailConfig) ExponentialBackoff() {
-	if fc.Elapsed == fc.Cap {
+	if fc.Elapsed >= fc.Cap {
 		fc.MaxRetriesReached = true // max retries reached
 		return
 	}
 
 	fc.Attempt += 1.0
 	// exponential backoff with full jitter
-	wait := float64(rand.Intn(int(fc.Base * math.Pow(2.0, fc.Attempt))))
+	wait := 0.0
+	if n := int(fc.Base * math.Pow(2.0, fc.Attempt)); n > 0 {
+		wait = float64(rand.IntN(n))
+	}
 
 	if fc.Elapsed+wait > fc.Cap {
 		// wait until cap is reached
-		time.Sleep(time.Duration(wait - (wait + fc.Elapsed - fc.Cap)))
-		fc.Elapsed = fc.Cap
+		wait = fc.Cap - fc.Elapsed
 	}
 
 	time.Sleep(time.Duration(wait) * time.Millisecond)
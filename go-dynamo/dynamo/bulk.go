@@ -0,0 +1,561 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains bulk operations, which read and write any number of items
+// by splitting them into batches of the sizes allowed by the DynamoDB API and
+// processing the batches concurrently. Bulk operations may span several tables.
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// Maximum number of items per BatchWriteItem and BatchGetItem request.
+const (
+	MaxBatchWriteItems = 25
+	MaxBatchGetItems   = 100
+)
+
+// DefaultBulkConcurrency is the default number of batches a bulk operation processes at once.
+const DefaultBulkConcurrency = 4
+
+// BulkOptions configures a bulk operation.
+type BulkOptions struct {
+	// Concurrency is the maximum number of batches processed at once.
+	// Defaults to DefaultBulkConcurrency.
+	Concurrency int
+	// Retry configures the retries of throttled requests and unprocessed items
+	// of each batch. Defaults to the client's RetryPolicy.
+	Retry RetryPolicy
+}
+
+// BulkResult is the outcome of one item of a bulk write.
+type BulkResult struct {
+	// Index is the item's position in the request.
+	Index int
+	// Err is nil if the item was written.
+	Err error
+}
+
+// BulkWriteReport reports the outcome of each item of a bulk write.
+type BulkWriteReport struct {
+	// Results holds one result per item, in request order.
+	Results []BulkResult
+	// Failed is the number of items that were not written.
+	Failed int
+}
+
+// BulkGetResult is the outcome of one query of a bulk read.
+type BulkGetResult[T any] struct {
+	// Index is the query's position in the request.
+	Index int
+	// Item is the item read, or the zero value of T if not found.
+	Item  T
+	Found bool
+	// Err is nil if the item was read or does not exist.
+	Err error
+}
+
+// BulkGetReport reports the outcome of each query of a bulk read.
+type BulkGetReport[T any] struct {
+	// Results holds one result per query, in request order.
+	Results []BulkGetResult[T]
+	// Failed is the number of queries that were not read.
+	Failed int
+}
+
+// BatchItem is a put or delete of one item in a multi-table bulk write.
+type BatchItem struct {
+	TableName string
+	request   string // P, D
+	Item      interface{}
+	Query     *Query
+}
+
+// GetRequest returns the item's request type: P (put) or D (delete).
+func (b *BatchItem) GetRequest() string {
+	return b.request
+}
+
+// NewBatchPutItem initializes a new BatchItem object for put requests.
+func NewBatchPutItem(tableName string, item interface{}) BatchItem {
+	return BatchItem{TableName: tableName, request: "P", Item: item}
+}
+
+// NewBatchDeleteItem initializes a new BatchItem object for delete requests.
+func NewBatchDeleteItem(tableName string, q *Query) BatchItem {
+	return BatchItem{TableName: tableName, request: "D", Query: q}
+}
+
+// TableGet holds the queries for one table of a multi-table bulk read.
+type TableGet struct {
+	Queries []*Query
+	// Expr's projection, if any, selects the attributes read.
+	Expr Expression
+}
+
+// BulkWriteCreate puts any number of items in the table, in batches of up to
+// MaxBatchWriteItems items. Failures are reported per item: an item that cannot
+// be marshalled, that repeats the key of an earlier item (ErrDuplicateKey), that
+// targets a table with a VersionAttribute (ErrVersionedTable), or whose batch
+// fails or remains unprocessed after retries is not written.
+// An error is returned only if the table is unknown.
+func (d *DynamoDB) BulkWriteCreate(ctx context.Context, tableName string, items []interface{}, opts BulkOptions) (*BulkWriteReport, error) {
+	batch := make([]BatchItem, len(items))
+	for i, item := range items {
+		batch[i] = NewBatchPutItem(tableName, item)
+	}
+	return d.bulkWriteTable(ctx, tableName, batch, opts)
+}
+
+// BulkWriteDelete deletes the items identified by any number of queries from
+// the table, in batches of up to MaxBatchWriteItems items. Failures are reported
+// per query as in BulkWriteCreate. An error is returned only if the table is unknown.
+func (d *DynamoDB) BulkWriteDelete(ctx context.Context, tableName string, queries []*Query, opts BulkOptions) (*BulkWriteReport, error) {
+	batch := make([]BatchItem, len(queries))
+	for i, q := range queries {
+		batch[i] = NewBatchDeleteItem(tableName, q)
+	}
+	return d.bulkWriteTable(ctx, tableName, batch, opts)
+}
+
+// bulkWriteTable writes items to a single table and returns its report.
+func (d *DynamoDB) bulkWriteTable(ctx context.Context, tableName string, items []BatchItem, opts BulkOptions) (*BulkWriteReport, error) {
+	reports, err := d.BatchWriteTables(ctx, items, opts)
+	if err != nil {
+		return nil, err
+	}
+	if reports[tableName] == nil {
+		return &BulkWriteReport{Results: []BulkResult{}}, nil
+	}
+	return reports[tableName], nil
+}
+
+// BatchWriteTables puts and deletes any number of items across tables. Items
+// of different tables share batches, so up to MaxBatchWriteItems items are
+// written in one request. Failures are reported per item as in BulkWriteCreate,
+// grouped by table name; each result's Index is the item's position in items.
+// An error is returned only if a table is unknown or a request type is invalid.
+func (d *DynamoDB) BatchWriteTables(ctx context.Context, items []BatchItem, opts BulkOptions) (map[string]*BulkWriteReport, error) {
+	// get tables
+	tables := make([]*Table, len(items))
+	for i, item := range items {
+		t := d.tables[item.TableName]
+		if t == nil {
+			return nil, NewTableNotFoundErr(item.TableName)
+		}
+		if item.request != "P" && item.request != "D" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRequestType, item.request)
+		}
+		tables[i] = t
+	}
+
+	results := d.bulkWrite(ctx, tables, opts, func(i int) (*dynamodb.WriteRequest, map[string]*dynamodb.AttributeValue, error) {
+		if tables[i].VersionAttribute != "" {
+			return nil, nil, fmt.Errorf("%w: %s", ErrVersionedTable, tables[i].TableName)
+		}
+		if items[i].request == "D" {
+			if items[i].Query == nil {
+				return nil, nil, fmt.Errorf("%w: nil query", ErrMissingKey)
+			}
+			keys, err := keyMaker(items[i].Query, tables[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			return &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: keys}}, keys, nil
+		}
+		av, err := dynamodbattribute.MarshalMap(items[i].Item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
+		}
+		keys, err := tables[i].keysOf(av)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: av}}, keys, nil
+	})
+
+	// group results by table
+	reports := make(map[string]*BulkWriteReport)
+	for i, r := range results {
+		report := reports[items[i].TableName]
+		if report == nil {
+			report = &BulkWriteReport{Results: []BulkResult{}}
+			reports[items[i].TableName] = report
+		}
+		report.add(r)
+	}
+	return reports, nil
+}
+
+// tableKey identifies an item across tables.
+type tableKey struct {
+	table string
+	key   string
+}
+
+// bulkWrite writes one item per table in tables; tables[i] is the table of
+// item i and request returns its write request and key.
+func (d *DynamoDB) bulkWrite(ctx context.Context, tables []*Table, opts BulkOptions, request func(i int) (*dynamodb.WriteRequest, map[string]*dynamodb.AttributeValue, error)) []BulkResult {
+	results := make([]BulkResult, len(tables))
+
+	// build requests, skipping failed items and duplicate keys
+	type pending struct {
+		index int
+		key   tableKey
+		wr    *dynamodb.WriteRequest
+	}
+	reqs := []pending{}
+	seen := make(map[tableKey]bool)
+	for i, t := range tables {
+		results[i].Index = i
+		wr, keys, err := request(i)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		k := tableKey{table: t.TableName, key: t.keyString(keys)}
+		if seen[k] {
+			results[i].Err = ErrDuplicateKey
+			continue
+		}
+		seen[k] = true
+		reqs = append(reqs, pending{index: i, key: k, wr: wr})
+	}
+
+	chunks := chunk(reqs, MaxBatchWriteItems)
+	runChunks(len(chunks), opts.concurrency(), func(c int) {
+		wrs := make(map[string][]*dynamodb.WriteRequest)
+		for _, p := range chunks[c] {
+			wrs[p.key.table] = append(wrs[p.key.table], p.wr)
+		}
+		unprocessed, err := d.writeBatch(ctx, wrs, d.retryPolicy(opts.Retry))
+		failed := make(map[tableKey]bool)
+		for name, reqs := range unprocessed {
+			t := d.tables[name]
+			for _, wr := range reqs {
+				failed[tableKey{table: name, key: t.keyString(writeRequestKey(t, wr))}] = true
+			}
+		}
+		for _, p := range chunks[c] {
+			if failed[p.key] {
+				results[p.index].Err = err
+			}
+		}
+	})
+	return results
+}
+
+// BulkGet reads the items identified by any number of queries from the table
+// and returns them as values of type T, in batches of up to MaxBatchGetItems keys.
+// Results are reported per query in request order; queries repeating a key
+// read the same item. An error is returned only if the table is unknown.
+func BulkGet[T any](ctx context.Context, d *DynamoDB, tableName string, queries []*Query, expr Expression, opts BulkOptions) (*BulkGetReport[T], error) {
+	reports, err := d.BatchGetTables(ctx, map[string]TableGet{tableName: {Queries: queries, Expr: expr}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalGetReport[T](reports[tableName]), nil
+}
+
+// BatchGetTables reads the items identified by any number of queries across
+// tables. Keys of different tables share batches, so up to MaxBatchGetItems
+// items are read in one request. Results are reported per query as in BulkGet,
+// grouped by table name; each result's Index is the query's position in the
+// table's Queries. Items are returned as Attribute Value maps, which
+// UnmarshalGetReport converts to typed values.
+// An error is returned only if a table is unknown.
+func (d *DynamoDB) BatchGetTables(ctx context.Context, gets map[string]TableGet, opts BulkOptions) (map[string]*BulkGetReport[map[string]*dynamodb.AttributeValue], error) {
+	reports := make(map[string]*BulkGetReport[map[string]*dynamodb.AttributeValue])
+	for name, get := range gets {
+		if d.tables[name] == nil {
+			return nil, NewTableNotFoundErr(name)
+		}
+		reports[name] = &BulkGetReport[map[string]*dynamodb.AttributeValue]{
+			Results: make([]BulkGetResult[map[string]*dynamodb.AttributeValue], len(get.Queries)),
+		}
+	}
+
+	// group queries by key
+	type pending struct {
+		t       *Table
+		key     map[string]*dynamodb.AttributeValue
+		indexes []int
+	}
+	keys := []*pending{}
+	byKey := make(map[tableKey]*pending)
+	for name, get := range gets {
+		t := d.tables[name]
+		results := reports[name].Results
+		for i, q := range get.Queries {
+			results[i].Index = i
+			if q == nil {
+				results[i].Err = fmt.Errorf("%w: nil query", ErrMissingKey)
+				continue
+			}
+			key, err := keyMaker(q, t)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			k := tableKey{table: name, key: t.keyString(key)}
+			if p := byKey[k]; p != nil {
+				p.indexes = append(p.indexes, i)
+				continue
+			}
+			p := &pending{t: t, key: key, indexes: []int{i}}
+			byKey[k] = p
+			keys = append(keys, p)
+		}
+	}
+
+	chunks := chunk(keys, MaxBatchGetItems)
+	runChunks(len(chunks), opts.concurrency(), func(c int) {
+		requests := make(map[string]*dynamodb.KeysAndAttributes)
+		added := make(map[string][]string)
+		for _, p := range chunks[c] {
+			ka := requests[p.t.TableName]
+			if ka == nil {
+				ka = &dynamodb.KeysAndAttributes{}
+				ka.ProjectionExpression, ka.ExpressionAttributeNames, added[p.t.TableName] = projectionWithKeys(p.t, gets[p.t.TableName].Expr)
+				requests[p.t.TableName] = ka
+			}
+			ka.Keys = append(ka.Keys, p.key)
+		}
+		responses, unprocessed, err := d.getBatch(ctx, requests, d.retryPolicy(opts.Retry))
+
+		found := make(map[tableKey]map[string]*dynamodb.AttributeValue)
+		for name, avs := range responses {
+			t := d.tables[name]
+			for _, av := range avs {
+				found[tableKey{table: name, key: t.keyString(av)}] = av
+			}
+		}
+		failed := make(map[tableKey]bool)
+		for name, ka := range unprocessed {
+			t := d.tables[name]
+			for _, key := range ka.Keys {
+				failed[tableKey{table: name, key: t.keyString(key)}] = true
+			}
+		}
+		for _, p := range chunks[c] {
+			k := tableKey{table: p.t.TableName, key: p.t.keyString(p.key)}
+			for _, i := range p.indexes {
+				r := &reports[p.t.TableName].Results[i]
+				switch av := found[k]; {
+				case failed[k]:
+					r.Err = err
+				case av != nil:
+					r.Item, r.Found = withoutAttributes(av, added[p.t.TableName]), true
+				}
+			}
+		}
+	})
+
+	for _, report := range reports {
+		for _, r := range report.Results {
+			if r.Err != nil {
+				report.Failed++
+			}
+		}
+	}
+	return reports, nil
+}
+
+// UnmarshalGetReport returns a copy of r with each item read unmarshalled as a
+// value of type T. Items that cannot be unmarshalled are reported as failed.
+func UnmarshalGetReport[T any](r *BulkGetReport[map[string]*dynamodb.AttributeValue]) *BulkGetReport[T] {
+	report := &BulkGetReport[T]{Results: make([]BulkGetResult[T], len(r.Results))}
+	for i, res := range r.Results {
+		report.Results[i] = BulkGetResult[T]{Index: res.Index, Found: res.Found, Err: res.Err}
+		if res.Found {
+			report.Results[i].Item, report.Results[i].Err = unmarshalItem[T](res.Item)
+			report.Results[i].Found = report.Results[i].Err == nil
+		}
+		if report.Results[i].Err != nil {
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// add appends a result to the report.
+func (r *BulkWriteReport) add(res BulkResult) {
+	r.Results = append(r.Results, res)
+	if res.Err != nil {
+		r.Failed++
+	}
+}
+
+// projectionWithKeys returns the projection of expr with any of t's key
+// attributes it omits added, so that items read can be matched to their keys,
+// the projection's attribute names and the key attributes added.
+// Returns nil values if expr has no projection.
+func projectionWithKeys(t *Table, expr Expression) (*string, map[string]*string, []string) {
+	proj := expr.Projection()
+	if proj == nil {
+		return nil, nil, nil
+	}
+	names := make(map[string]*string)
+	for k, v := range expr.Names() {
+		names[k] = v
+	}
+
+	// top level attributes of the projection
+	projected := make(map[string]bool)
+	for _, path := range strings.Split(*proj, ",") {
+		attr := strings.TrimSpace(path)
+		if i := strings.IndexAny(attr, ".["); i >= 0 {
+			attr = attr[:i]
+		}
+		if name, ok := names[attr]; ok {
+			attr = aws.StringValue(name)
+		}
+		projected[attr] = true
+	}
+
+	projection, added := *proj, []string{}
+	add := func(placeholder, attr string) {
+		if attr == "" || projected[attr] {
+			return
+		}
+		names[placeholder] = aws.String(attr)
+		projection += ", " + placeholder
+		added = append(added, attr)
+	}
+	add(partitionKeyName, t.PrimaryKeyName)
+	add(sortKeyName, t.SortKeyName)
+	return &projection, names, added
+}
+
+// withoutAttributes returns a copy of av without the given attributes, or av
+// itself if there are none.
+func withoutAttributes(av map[string]*dynamodb.AttributeValue, attrs []string) map[string]*dynamodb.AttributeValue {
+	if len(attrs) == 0 {
+		return av
+	}
+	item := make(map[string]*dynamodb.AttributeValue, len(av))
+	for k, v := range av {
+		item[k] = v
+	}
+	for _, attr := range attrs {
+		delete(item, attr)
+	}
+	return item
+}
+
+// writeBatch writes a batch of write requests, retrying throttled requests and
+// unprocessed items with policy. Returns the requests that were not written
+// and the reason.
+func (d *DynamoDB) writeBatch(ctx context.Context, requests map[string][]*dynamodb.WriteRequest, policy RetryPolicy) (map[string][]*dynamodb.WriteRequest, error) {
+	retry := policy.Start()
+	for {
+		if err := ctx.Err(); err != nil {
+			return requests, err
+		}
+		result, err := d.svc.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{RequestItems: requests})
+		if err != nil && !errors.Is(handleErr(err), ErrRateLimitExceeded) {
+			return requests, fmt.Errorf("d.svc.BatchWriteItemWithContext: %w", handleErr(err))
+		}
+		if err == nil {
+			if len(result.UnprocessedItems) == 0 {
+				return nil, nil
+			}
+			requests = result.UnprocessedItems
+		}
+
+		if err := retry.Wait(ctx); errors.Is(err, ErrRetriesExhausted) {
+			return requests, fmt.Errorf("d.svc.BatchWriteItemWithContext: %w", ErrRateLimitExceeded)
+		} else if err != nil {
+			return requests, err
+		}
+	}
+}
+
+// getBatch reads a batch of keys, retrying throttled requests and unprocessed
+// keys with policy. Returns the items read, the keys that were not read
+// and the reason.
+func (d *DynamoDB) getBatch(ctx context.Context, requests map[string]*dynamodb.KeysAndAttributes, policy RetryPolicy) (map[string][]map[string]*dynamodb.AttributeValue, map[string]*dynamodb.KeysAndAttributes, error) {
+	responses := make(map[string][]map[string]*dynamodb.AttributeValue)
+	retry := policy.Start()
+	for {
+		if err := ctx.Err(); err != nil {
+			return responses, requests, err
+		}
+		result, err := d.svc.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: requests})
+		if err != nil && !errors.Is(handleErr(err), ErrRateLimitExceeded) {
+			return responses, requests, fmt.Errorf("d.svc.BatchGetItemWithContext: %w", handleErr(err))
+		}
+		if err == nil {
+			for name, items := range result.Responses {
+				responses[name] = append(responses[name], items...)
+			}
+			if len(result.UnprocessedKeys) == 0 {
+				return responses, nil, nil
+			}
+			requests = result.UnprocessedKeys
+		}
+
+		if err := retry.Wait(ctx); errors.Is(err, ErrRetriesExhausted) {
+			return responses, requests, fmt.Errorf("d.svc.BatchGetItemWithContext: %w", ErrRateLimitExceeded)
+		} else if err != nil {
+			return responses, requests, err
+		}
+	}
+}
+
+// writeRequestKey returns the key of the item written by wr.
+func writeRequestKey(t *Table, wr *dynamodb.WriteRequest) map[string]*dynamodb.AttributeValue {
+	if wr.PutRequest != nil {
+		return wr.PutRequest.Item
+	}
+	return wr.DeleteRequest.Key
+}
+
+// concurrency returns the configured concurrency, or the default.
+func (o BulkOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return DefaultBulkConcurrency
+	}
+	return o.Concurrency
+}
+
+// chunk splits s into slices of at most size elements.
+func chunk[E any](s []E, size int) [][]E {
+	chunks := [][]E{}
+	for len(s) > size {
+		chunks = append(chunks, s[:size])
+		s = s[size:]
+	}
+	if len(s) > 0 {
+		chunks = append(chunks, s)
+	}
+	return chunks
+}
+
+// runChunks calls fn for each chunk index in [0, n), with at most
+// concurrency calls running at once, and waits for all calls to return.
+func runChunks(n, concurrency int, fn func(c int)) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for c := 0; c < n; c++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(c int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(c)
+		}(c)
+	}
+	wg.Wait()
+}
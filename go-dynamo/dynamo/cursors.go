@@ -0,0 +1,130 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains the CursorCodec for converting LastKey maps into opaque
+// pagination cursors that can be handed to API clients.
+package dynamo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// CursorCodec encodes LastKey maps as URL-safe cursors and decodes them back into start keys.
+// Cursors are bound to the table and index they were read from.
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec returns a new CursorCodec. If secret is not empty, cursors are signed
+// with HMAC-SHA256 and cursors that are unsigned or have been modified are rejected.
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// cursor is the JSON payload of an encoded cursor.
+type cursor struct {
+	Table string                  `json:"t"`
+	Index string                  `json:"i,omitempty"`
+	Key   map[string]cursorKeyVal `json:"k"`
+}
+
+// cursorKeyVal holds a key attribute value. Key attributes are always strings, numbers or binary.
+type cursorKeyVal struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// Encode returns an opaque cursor for the lastKey of a page read from the table and index.
+// Returns an empty string if lastKey is empty, as there are no more pages.
+// ex: next, err := c.Encode(res.LastKey, "my_table", "")
+func (c *CursorCodec) Encode(lastKey map[string]*dynamodb.AttributeValue, tableName, indexName string) (string, error) {
+	if len(lastKey) == 0 {
+		return "", nil
+	}
+
+	cur := cursor{Table: tableName, Index: indexName, Key: make(map[string]cursorKeyVal)}
+	for name, av := range lastKey {
+		if av == nil || (av.S == nil && av.N == nil && av.B == nil) {
+			return "", fmt.Errorf("%w: key attribute %s is not a string, number or binary value", ErrInvalidCursor, name)
+		}
+		cur.Key[name] = cursorKeyVal{S: av.S, N: av.N, B: av.B}
+	}
+
+	payload, err := json.Marshal(cur)
+	if err != nil {
+		return "", fmt.Errorf("json.Marshal: %w", err)
+	}
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	if len(c.secret) == 0 {
+		return enc, nil
+	}
+	return enc + "." + base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode returns the start key encoded in cursor. Returns ErrInvalidCursor if the cursor is
+// malformed, its signature does not match, or it was not issued for the table and index.
+// Returns nil if cursor is empty, which starts reading from the first page.
+func (c *CursorCodec) Decode(cursorStr, tableName, indexName string) (map[string]*dynamodb.AttributeValue, error) {
+	if cursorStr == "" {
+		return nil, nil
+	}
+
+	enc, sig, signed := strings.Cut(cursorStr, ".")
+	if signed != (len(c.secret) > 0) {
+		return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if signed {
+		mac, err := base64.RawURLEncoding.DecodeString(sig)
+		if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+			return nil, fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+		}
+	}
+
+	var cur cursor
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cur); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if cur.Table != tableName || cur.Index != indexName {
+		return nil, fmt.Errorf("%w: cursor was not issued for table %s index %q", ErrInvalidCursor, tableName, indexName)
+	}
+	if len(cur.Key) == 0 {
+		return nil, fmt.Errorf("%w: empty key", ErrInvalidCursor)
+	}
+
+	key := make(map[string]*dynamodb.AttributeValue)
+	for name, v := range cur.Key {
+		n := 0
+		for _, set := range []bool{v.S != nil, v.N != nil, v.B != nil} {
+			if set {
+				n++
+			}
+		}
+		if n != 1 {
+			return nil, fmt.Errorf("%w: key attribute %s must have exactly one value", ErrInvalidCursor, name)
+		}
+		key[name] = &dynamodb.AttributeValue{S: v.S, N: v.N, B: v.B}
+	}
+	return key, nil
+}
+
+// sign returns the HMAC-SHA256 of payload.
+func (c *CursorCodec) sign(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
@@ -4,13 +4,12 @@
 // This file contains CRUD operations for working with DynamoDB.
 package dynamo
 
-/* TO DO:
-- add expression logic to Create, Read, Delete operations
-*/
-
 import (
-	"errors"
+	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"time"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/ggarcia209/go-aws/goaws"
@@ -19,6 +18,7 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 )
 
 const ErrRequestThrottled = "ERR_REQUEST_THROTTLED"
@@ -26,33 +26,73 @@ const ErrRequestThrottled = "ERR_REQUEST_THROTTLED"
 type DynamoDbLogic interface {
 	ListTables() ([]string, int, error)
 	CreateTable(table *Table) error
+	UpdateTable(tableName, billingMode string, readCapacity, writeCapacity int64) error
+	DescribeTable(tableName string) (*TableDescription, error)
+	WaitUntilActive(ctx context.Context, tableName string, pollInterval time.Duration) error
+	WaitUntilDeleted(ctx context.Context, tableName string, pollInterval time.Duration) error
+	LoadTables(tableNames ...string) ([]*Table, error)
 	CreateItem(item interface{}, tableName string) error
-	DeleteTable(svc *dynamodb.DynamoDB, tableName string) error
+	CreateItemWithExpr(item interface{}, tableName string, expr Expression, returnValues string, out interface{}) error
+	DeleteTable(tableName string) error
 	GetItem(q *Query, tableName string, item interface{}, expr Expression) (interface{}, error)
 	UpdateItem(q *Query, tableName string, expr Expression) error
+	UpdateItemWithReturn(q *Query, tableName string, expr Expression, returnValues string, out interface{}) error
 	DeleteItem(q *Query, tableName string) error
-	BatchWriteCreate(tableName string, fc *FailConfig, items []interface{}) error
-	BatchWriteDelete(tableName string, fc *FailConfig, queries []*Query) error
-	BatchGet(tableName string, fc *FailConfig, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error)
-	ScanItems(tableName string, model interface{}, startKey interface{}, expr Expression) ([]interface{}, error)
+	DeleteItemWithExpr(q *Query, tableName string, expr Expression, returnValues string, out interface{}) error
+	DeleteItemByKey(item interface{}, tableName string) error
+	BatchWriteCreate(tableName string, retry RetryPolicy, items []interface{}) error
+	BatchWriteDelete(tableName string, retry RetryPolicy, queries []*Query) error
+	BulkWriteCreate(ctx context.Context, tableName string, items []interface{}, opts BulkOptions) (*BulkWriteReport, error)
+	BulkWriteDelete(ctx context.Context, tableName string, queries []*Query, opts BulkOptions) (*BulkWriteReport, error)
+	BatchGet(tableName string, retry RetryPolicy, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error)
+	BatchWriteTables(ctx context.Context, items []BatchItem, opts BulkOptions) (map[string]*BulkWriteReport, error)
+	BatchGetTables(ctx context.Context, gets map[string]TableGet, opts BulkOptions) (map[string]*BulkGetReport[map[string]*dynamodb.AttributeValue], error)
+	ScanItems(tableName string, model any, startKey any, expr Expression, perPage *int64) (*ScanResults, error)
+	ScanIndex(tableName, indexName string, model any, startKey any, expr Expression, perPage *int64) (*ScanResults, error)
+	QueryItems(tableName string, model any, startKey any, expr Expression, perPage *int64) (*QueryResults, error)
+	QueryIndex(tableName, indexName string, model any, startKey any, expr Expression, perPage *int64) (*QueryResults, error)
 	TxWrite(items []TransactionItem, requestToken string) ([]TransactionItem, error)
+	TxWriteWithRetry(ctx context.Context, items []TransactionItem, requestToken string, retry RetryPolicy) (*TxReport, error)
+	TxRead(items []TransactionItem, dest map[string]interface{}) ([]TransactionItem, error)
 }
 
 type DynamoDB struct {
-	svc        *dynamodb.DynamoDB
-	tables     map[string]*Table
-	failConfig *FailConfig
+	svc    dynamodbiface.DynamoDBAPI
+	tables map[string]*Table
+	retry  RetryPolicy
+}
+
+// NewDynamoDB returns a DynamoDB for the given tables. Tables may also be
+// discovered from the database with LoadTables. retry is the default RetryPolicy
+// of throttled requests and unprocessed batch items; DefaultRetryPolicy is used if nil.
+func NewDynamoDB(sess goaws.Session, tables []*Table, retry RetryPolicy) *DynamoDB {
+	return NewDynamoDBWithClient(dynamodb.New(sess.GetSession()), tables, retry)
 }
 
-func NewDynamoDB(sess goaws.Session, tables []*Table, failConfig *FailConfig) *DynamoDB {
+// NewDynamoDBWithClient returns a DynamoDB using the given client,
+// such as an in-memory implementation for tests.
+func NewDynamoDBWithClient(svc dynamodbiface.DynamoDBAPI, tables []*Table, retry RetryPolicy) *DynamoDB {
 	tm := make(map[string]*Table)
 	for _, t := range tables {
 		tm[t.TableName] = t
 	}
 	return &DynamoDB{
-		svc:        dynamodb.New(sess.GetSession()),
-		tables:     tm,
-		failConfig: failConfig,
+		svc:    svc,
+		tables: tm,
+		retry:  retry,
+	}
+}
+
+// retryPolicy returns policy, or the client's RetryPolicy if policy is nil,
+// or DefaultRetryPolicy if neither is set.
+func (d *DynamoDB) retryPolicy(policy RetryPolicy) RetryPolicy {
+	switch {
+	case policy != nil:
+		return policy
+	case d.retry != nil:
+		return d.retry
+	default:
+		return DefaultRetryPolicy()
 	}
 }
 
@@ -99,32 +139,63 @@ func (d *DynamoDB) ListTables() ([]string, int, error) {
 	return names, t, nil
 }
 
-// CreateTable creates a new table with the parameters passed to the Table struct.
-// NOTE: CreateTable creates Table in * On-Demand * billing mode.
+// CreateTable creates a new table with the parameters passed to the Table struct,
+// including any global and local secondary indexes and the settings in Table.Options.
+// NOTE: CreateTable creates Table in * On-Demand * billing mode unless Options specify otherwise.
 func (d *DynamoDB) CreateTable(table *Table) error {
+	if err := table.Validate(); err != nil {
+		return err
+	}
+
+	opts := table.Options
+	if opts == nil {
+		opts = &TableOptions{}
+	}
+
 	input := &dynamodb.CreateTableInput{
-		AttributeDefinitions: []*dynamodb.AttributeDefinition{
-			{ // Primary Key
-				AttributeName: aws.String(table.PrimaryKeyName),
-				AttributeType: aws.String(table.PrimaryKeyType),
-			},
-			{
-				AttributeName: aws.String(table.SortKeyName),
-				AttributeType: aws.String(table.SortKeyType),
-			},
-		},
-		BillingMode: aws.String("PAY_PER_REQUEST"),
-		KeySchema: []*dynamodb.KeySchemaElement{
-			{
-				AttributeName: aws.String(table.PrimaryKeyName),
-				KeyType:       aws.String("HASH"),
-			},
-			{
-				AttributeName: aws.String(table.SortKeyName),
-				KeyType:       aws.String("RANGE"),
-			},
-		},
-		TableName: aws.String(table.TableName),
+		AttributeDefinitions: attributeDefinitions(table),
+		BillingMode:          aws.String(opts.billingMode()),
+		KeySchema:            keySchema(table.PrimaryKeyName, table.SortKeyName),
+		TableName:            aws.String(table.TableName),
+	}
+	for _, idx := range table.GlobalIndexes {
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndex{
+			IndexName:             aws.String(idx.IndexName),
+			KeySchema:             indexKeySchema(table, idx),
+			Projection:            indexProjection(idx),
+			ProvisionedThroughput: provisionedThroughput(opts.billingMode(), opts.ReadCapacity, opts.WriteCapacity),
+		})
+	}
+	for _, idx := range table.LocalIndexes {
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndex{
+			IndexName:  aws.String(idx.IndexName),
+			KeySchema:  indexKeySchema(table, idx),
+			Projection: indexProjection(idx),
+		})
+	}
+
+	input.ProvisionedThroughput = provisionedThroughput(opts.billingMode(), opts.ReadCapacity, opts.WriteCapacity)
+	if opts.StreamViewType != "" {
+		input.StreamSpecification = &dynamodb.StreamSpecification{
+			StreamEnabled:  aws.Bool(true),
+			StreamViewType: aws.String(opts.StreamViewType),
+		}
+	}
+	if opts.SSEEnabled || opts.KMSKeyID != "" {
+		input.SSESpecification = &dynamodb.SSESpecification{Enabled: aws.Bool(true)}
+		if opts.KMSKeyID != "" {
+			input.SSESpecification.SSEType = aws.String(dynamodb.SSETypeKms)
+			input.SSESpecification.KMSMasterKeyId = aws.String(opts.KMSKeyID)
+		}
+	}
+	if opts.TableClass != "" {
+		input.TableClass = aws.String(opts.TableClass)
+	}
+	if opts.DeletionProtection {
+		input.DeletionProtectionEnabled = aws.Bool(true)
+	}
+	for _, k := range sortedKeys(opts.Tags) {
+		input.Tags = append(input.Tags, &dynamodb.Tag{Key: aws.String(k), Value: aws.String(opts.Tags[k])})
 	}
 
 	if _, err := d.svc.CreateTable(input); err != nil {
@@ -136,29 +207,181 @@ func (d *DynamoDB) CreateTable(table *Table) error {
 	return nil
 }
 
+// UpdateTable switches the billing mode of an existing table, or changes its provisioned capacity.
+// In "PROVISIONED" billing mode the capacity is applied to the table and each of its global indexes;
+// readCapacity and writeCapacity are ignored in "PAY_PER_REQUEST" mode.
+func (d *DynamoDB) UpdateTable(tableName, billingMode string, readCapacity, writeCapacity int64) error {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	input := &dynamodb.UpdateTableInput{
+		BillingMode:           aws.String(billingMode),
+		ProvisionedThroughput: provisionedThroughput(billingMode, readCapacity, writeCapacity),
+		TableName:             aws.String(t.TableName),
+	}
+	if input.ProvisionedThroughput != nil {
+		for _, idx := range t.GlobalIndexes {
+			input.GlobalSecondaryIndexUpdates = append(input.GlobalSecondaryIndexUpdates, &dynamodb.GlobalSecondaryIndexUpdate{
+				Update: &dynamodb.UpdateGlobalSecondaryIndexAction{
+					IndexName:             aws.String(idx.IndexName),
+					ProvisionedThroughput: input.ProvisionedThroughput,
+				},
+			})
+		}
+	}
+
+	if _, err := d.svc.UpdateTable(input); err != nil {
+		return fmt.Errorf("d.svc.UpdateTable: %w", handleErr(err))
+	}
+
+	if t.Options == nil {
+		t.Options = &TableOptions{}
+	}
+	t.Options.BillingMode, t.Options.ReadCapacity, t.Options.WriteCapacity = billingMode, readCapacity, writeCapacity
+
+	return nil
+}
+
+// provisionedThroughput returns the throughput for the billing mode,
+// or nil if capacity is not provisioned.
+func provisionedThroughput(billingMode string, readCapacity, writeCapacity int64) *dynamodb.ProvisionedThroughput {
+	if billingMode != dynamodb.BillingModeProvisioned {
+		return nil
+	}
+	return &dynamodb.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(readCapacity),
+		WriteCapacityUnits: aws.Int64(writeCapacity),
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// attributeDefinitions returns a definition for each key attribute of the table and its indexes.
+func attributeDefinitions(table *Table) []*dynamodb.AttributeDefinition {
+	defs := []*dynamodb.AttributeDefinition{}
+	seen := make(map[string]bool)
+	add := func(name, typ string) {
+		if name == "" || seen[name] {
+			return
+		}
+		seen[name] = true
+		defs = append(defs, &dynamodb.AttributeDefinition{
+			AttributeName: aws.String(name),
+			AttributeType: aws.String(typ),
+		})
+	}
+
+	add(table.PrimaryKeyName, table.PrimaryKeyType)
+	add(table.SortKeyName, table.SortKeyType)
+	for _, idx := range append(append([]*Index{}, table.GlobalIndexes...), table.LocalIndexes...) {
+		add(idx.PrimaryKeyName, idx.PrimaryKeyType)
+		add(idx.SortKeyName, idx.SortKeyType)
+	}
+	return defs
+}
+
+// keySchema returns a key schema with the given Partition Key and optional Sort Key.
+func keySchema(pKeyName, sKeyName string) []*dynamodb.KeySchemaElement {
+	ks := []*dynamodb.KeySchemaElement{{
+		AttributeName: aws.String(pKeyName),
+		KeyType:       aws.String("HASH"),
+	}}
+	if sKeyName != "" {
+		ks = append(ks, &dynamodb.KeySchemaElement{
+			AttributeName: aws.String(sKeyName),
+			KeyType:       aws.String("RANGE"),
+		})
+	}
+	return ks
+}
+
+// indexKeySchema returns the key schema of a secondary index.
+func indexKeySchema(table *Table, idx *Index) []*dynamodb.KeySchemaElement {
+	if idx.PrimaryKeyName == "" {
+		return keySchema(table.PrimaryKeyName, idx.SortKeyName)
+	}
+	return keySchema(idx.PrimaryKeyName, idx.SortKeyName)
+}
+
+// indexProjection returns the projection of a secondary index.
+func indexProjection(idx *Index) *dynamodb.Projection {
+	p := &dynamodb.Projection{ProjectionType: aws.String(idx.ProjectionType)}
+	if idx.ProjectionType == "" {
+		p.ProjectionType = aws.String(dynamodb.ProjectionTypeAll)
+	}
+	if len(idx.NonKeyAttributes) > 0 {
+		p.NonKeyAttributes = aws.StringSlice(idx.NonKeyAttributes)
+	}
+	return p
+}
+
 // CreateItem puts a new item in the table.
+// Returns ErrMissingKey if the item has no value for a key attribute.
+// In tables with a VersionAttribute, the item's version is set to 1 and
+// a *VersionConflictErr is returned if the item already exists.
 func (d *DynamoDB) CreateItem(item interface{}, tableName string) error {
+	return d.CreateItemWithExpr(item, tableName, NewExpression(), ReturnValuesNone, nil)
+}
+
+// CreateItemWithExpr puts a new item in the table if the expression's condition,
+// if any, holds. If returnValues is ReturnValuesAllOld, the attributes of the
+// replaced item, if any, are unmarshalled into out.
+// The expression may only have a condition; other kinds return ErrInvalidExpression.
+// A failed condition returns a *ConditionCheckFailedErr holding the current item.
+func (d *DynamoDB) CreateItemWithExpr(item interface{}, tableName string, expr Expression, returnValues string, out interface{}) error {
 	// check if table exists
 	t := d.tables[tableName]
 	if t == nil {
 		return NewTableNotFoundErr(tableName)
 	}
 
+	if err := checkWriteExpr(expr, false); err != nil {
+		return err
+	}
 	av, err := dynamodbattribute.MarshalMap(item)
 	if err != nil {
 		return fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
 	}
-
-	input := &dynamodb.PutItemInput{
-		Item:      av,
-		TableName: aws.String(tableName),
+	if _, err := t.keysOf(av); err != nil {
+		return err
 	}
 
-	if _, err = d.svc.PutItem(input); err != nil {
-		return fmt.Errorf("d.svc.PutItem: %w", err)
+	w := newWriteExpr(expr)
+	if t.VersionAttribute != "" {
+		av[t.VersionAttribute] = versionAV(1)
+		w.requireNew(t)
+	}
+	input := &dynamodb.PutItemInput{
+		ConditionExpression:                 w.condition,
+		ExpressionAttributeNames:            w.names,
+		ExpressionAttributeValues:           w.values,
+		Item:                                av,
+		ReturnValues:                        returnValuesInput(returnValues),
+		ReturnValuesOnConditionCheckFailure: w.onConditionCheckFailure(),
+		TableName:                           aws.String(tableName),
+	}
+
+	var result *dynamodb.PutItemOutput
+	err = retryThrottled(context.Background(), d.retryPolicy(nil), func() (err error) {
+		result, err = d.svc.PutItem(input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("d.svc.PutItem: %w", versionErr(t, 0, err))
 	}
 
-	return nil
+	return unmarshalReturnValues(result.Attributes, out)
 }
 
 // GetItem reads an item from the database.
@@ -171,23 +394,16 @@ func (d *DynamoDB) GetItem(q *Query, tableName string, item interface{}, expr Ex
 		return nil, NewTableNotFoundErr(tableName)
 	}
 
-	key := keyMaker(q, t)
-	input := &dynamodb.GetItemInput{
-		TableName: aws.String(t.TableName),
-		Key:       key,
-	}
-	if expr.Projection() != nil {
-		input.ExpressionAttributeNames = expr.Names()
-		input.ProjectionExpression = expr.Projection()
+	keys, err := keyMaker(q, t)
+	if err != nil {
+		return nil, err
 	}
-
-	result, err := d.svc.GetItem(input)
+	result, err := d.getItem(keys, t, expr)
 	if err != nil {
-		return nil, fmt.Errorf("d.svc.GetItem: %w", handleErr(err))
+		return nil, err
 	}
 
-	err = dynamodbattribute.UnmarshalMap(result.Item, &item)
-	if err != nil {
+	if err = dynamodbattribute.UnmarshalMap(result, &item); err != nil {
 		return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
 	}
 
@@ -196,39 +412,64 @@ func (d *DynamoDB) GetItem(q *Query, tableName string, item interface{}, expr Ex
 
 // UpdateItem updates the specified item's attribute defined in the
 // Query object with the UpdateValue defined in the Query.
+// The expression's condition, if any, must hold for the update to apply.
+// In tables with a VersionAttribute, the item's version must equal the Query's
+// Version and is incremented, or a *VersionConflictErr is returned.
 func (d *DynamoDB) UpdateItem(q *Query, tableName string, expr Expression) error {
+	return d.UpdateItemWithReturn(q, tableName, expr, ReturnValuesUpdatedNew, nil)
+}
+
+// UpdateItemWithReturn updates the specified item as UpdateItem does and
+// unmarshals the attributes selected by returnValues, such as ReturnValuesAllNew
+// or ReturnValuesUpdatedOld, into out.
+// The expression may only have condition and update expressions; other kinds
+// return ErrInvalidExpression.
+// A failed condition returns a *ConditionCheckFailedErr holding the current item.
+func (d *DynamoDB) UpdateItemWithReturn(q *Query, tableName string, expr Expression, returnValues string, out interface{}) error {
 	// get table
 	t := d.tables[tableName]
 	if t == nil {
 		return NewTableNotFoundErr(tableName)
 	}
 
-	input := &dynamodb.UpdateItemInput{
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		TableName:                 aws.String(t.TableName),
-		Key:                       keyMaker(q, t),
-		ReturnValues:              aws.String("UPDATED_NEW"),
-		UpdateExpression:          expr.Update(),
-	}
-	if expr.Condition() != nil {
-		input.ConditionExpression = expr.Condition()
-	}
-	if expr.Filter() != nil {
-		input.ConditionExpression = expr.Filter()
+	if err := checkWriteExpr(expr, true); err != nil {
+		return err
 	}
-	if expr.KeyCondition() != nil {
-		input.ConditionExpression = expr.KeyCondition()
+	keys, err := keyMaker(q, t)
+	if err != nil {
+		return err
 	}
-	if expr.Projection() != nil {
-		input.ConditionExpression = expr.Projection()
+	version, err := expectedVersion(t, q)
+	if err != nil {
+		return err
 	}
 
-	if _, err := d.svc.UpdateItem(input); err != nil {
-		return fmt.Errorf("d.svc.UpdateItem: %w", handleErr(err))
+	w := newWriteExpr(expr)
+	if t.VersionAttribute != "" {
+		w.requireVersion(t, version)
+		w.incrementVersion(t, version)
+	}
+	input := &dynamodb.UpdateItemInput{
+		ConditionExpression:                 w.condition,
+		ExpressionAttributeNames:            w.names,
+		ExpressionAttributeValues:           w.values,
+		TableName:                           aws.String(t.TableName),
+		Key:                                 keys,
+		ReturnValues:                        returnValuesInput(returnValues),
+		ReturnValuesOnConditionCheckFailure: w.onConditionCheckFailure(),
+		UpdateExpression:                    w.update,
+	}
+
+	var result *dynamodb.UpdateItemOutput
+	err = retryThrottled(context.Background(), d.retryPolicy(nil), func() (err error) {
+		result, err = d.svc.UpdateItem(input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("d.svc.UpdateItem: %w", versionErr(t, version, err))
 	}
 
-	return nil
+	return unmarshalReturnValues(result.Attributes, out)
 }
 
 // DeleteTable deletes the selected table.
@@ -251,28 +492,44 @@ func (d *DynamoDB) DeleteTable(tableName string) error {
 	return nil
 }
 
-// DeleteItem deletes the specified item defined in the Query
+// DeleteItem deletes the specified item defined in the Query.
+// In tables with a VersionAttribute, the item's version must equal the Query's
+// Version, or a *VersionConflictErr is returned.
 func (d *DynamoDB) DeleteItem(q *Query, tableName string) error {
+	return d.DeleteItemWithExpr(q, tableName, NewExpression(), ReturnValuesNone, nil)
+}
+
+// DeleteItemWithExpr deletes the specified item if the expression's condition,
+// if any, holds. If returnValues is ReturnValuesAllOld, the attributes of the
+// deleted item, if any, are unmarshalled into out.
+// The expression may only have a condition; other kinds return ErrInvalidExpression.
+// A failed condition returns a *ConditionCheckFailedErr holding the current item.
+func (d *DynamoDB) DeleteItemWithExpr(q *Query, tableName string, expr Expression, returnValues string, out interface{}) error {
 	// get table
 	t := d.tables[tableName]
 	if t == nil {
 		return NewTableNotFoundErr(tableName)
 	}
 
-	input := &dynamodb.DeleteItemInput{
-		Key:       keyMaker(q, t),
-		TableName: aws.String(t.TableName),
+	if err := checkWriteExpr(expr, false); err != nil {
+		return err
 	}
-
-	if _, err := d.svc.DeleteItem(input); err != nil {
-		return fmt.Errorf("d.svc.DeleteItem: %w", handleErr(err))
+	keys, err := keyMaker(q, t)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	version, err := expectedVersion(t, q)
+	if err != nil {
+		return err
+	}
+	return d.deleteItem(keys, t, version, expr, returnValues, out)
 }
 
-// BatchWriteCreate writes a list of items to the database.
-func (d *DynamoDB) BatchWriteCreate(tableName string, fc *FailConfig, items []interface{}) error {
+// BatchWriteCreate writes a list of up to 25 items to the database.
+// Use BulkWriteCreate to write any number of items, or BatchWriteTables to
+// write to several tables at once.
+// Returns ErrVersionedTable if the table has a VersionAttribute.
+func (d *DynamoDB) BatchWriteCreate(tableName string, retry RetryPolicy, items []interface{}) error {
 	if len(items) > 25 {
 		return ErrCollectionSizeExceeded
 	}
@@ -282,6 +539,9 @@ func (d *DynamoDB) BatchWriteCreate(tableName string, fc *FailConfig, items []in
 	if t == nil {
 		return NewTableNotFoundErr(tableName)
 	}
+	if t.VersionAttribute != "" {
+		return fmt.Errorf("%w: %s", ErrVersionedTable, tableName)
+	}
 
 	// create map of RequestItems
 	reqItems := make(map[string][]*dynamodb.WriteRequest)
@@ -306,43 +566,19 @@ func (d *DynamoDB) BatchWriteCreate(tableName string, fc *FailConfig, items []in
 	// populate reqItems map
 	reqItems[t.TableName] = wrs
 
-	// generate input from reqItems map
-	input := &dynamodb.BatchWriteItemInput{
-		RequestItems: reqItems,
-	}
-
-	// batch write and error handling with exponential backoff retries for HTTP 5xx errors
-	var result *dynamodb.BatchWriteItemOutput
-	var err error
-	for {
-		result, err = d.batchWriteUtil(input)
-		if err != nil {
-			switch {
-			case errors.Is(err, ErrRateLimitExceeded):
-				input = &dynamodb.BatchWriteItemInput{
-					RequestItems: result.UnprocessedItems,
-				}
-				fc.ExponentialBackoff() // waits
-				if fc.MaxRetriesReached {
-					return fmt.Errorf("d.batchWriteUtil: %w", err)
-				}
-			default:
-				return fmt.Errorf("d.batchWriteUtil: %w", err)
-			}
-		}
-
-		if len(result.UnprocessedItems) == 0 {
-			fc.Reset() // reset configuration after loop
-			break
-		}
-
+	// batch write and error handling with exponential backoff retries for throttled requests
+	if _, err := d.writeBatch(context.Background(), reqItems, d.retryPolicy(retry)); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// BatchWriteDelete deletes a list of items from the database.
-func (d *DynamoDB) BatchWriteDelete(tableName string, fc *FailConfig, queries []*Query) error {
+// BatchWriteDelete deletes a list of up to 25 items from the database.
+// Use BulkWriteDelete to delete any number of items, or BatchWriteTables to
+// delete from several tables at once.
+// Returns ErrVersionedTable if the table has a VersionAttribute.
+func (d *DynamoDB) BatchWriteDelete(tableName string, retry RetryPolicy, queries []*Query) error {
 	if len(queries) > 25 {
 		return ErrCollectionSizeExceeded
 	}
@@ -352,6 +588,9 @@ func (d *DynamoDB) BatchWriteDelete(tableName string, fc *FailConfig, queries []
 	if t == nil {
 		return NewTableNotFoundErr(tableName)
 	}
+	if t.VersionAttribute != "" {
+		return fmt.Errorf("%w: %s", ErrVersionedTable, tableName)
+	}
 
 	// create map of RequestItems
 	reqItems := make(map[string][]*dynamodb.WriteRequest)
@@ -364,53 +603,33 @@ func (d *DynamoDB) BatchWriteDelete(tableName string, fc *FailConfig, queries []
 		}
 
 		// create put request, reformat as write request, and add to list
-		dr := &dynamodb.DeleteRequest{Key: keyMaker(q, t)}
+		keys, err := keyMaker(q, t)
+		if err != nil {
+			return err
+		}
+		dr := &dynamodb.DeleteRequest{Key: keys}
 		wr := &dynamodb.WriteRequest{DeleteRequest: dr}
 		wrs = append(wrs, wr)
 	}
 	// populate reqItems map
 	reqItems[t.TableName] = wrs
 
-	// generate input from reqItems map
-	input := &dynamodb.BatchWriteItemInput{
-		RequestItems: reqItems,
-	}
-
-	// batch write and error handling with exponential backoff retries for HTTP 5xx errors
-	var result *dynamodb.BatchWriteItemOutput
-	var err error
-	for {
-		result, err = d.batchWriteUtil(input)
-		if err != nil {
-			switch {
-			case errors.Is(err, ErrRateLimitExceeded):
-				input = &dynamodb.BatchWriteItemInput{
-					RequestItems: result.UnprocessedItems,
-				}
-				fc.ExponentialBackoff() // waits
-				if fc.MaxRetriesReached {
-					return fmt.Errorf("d.batchWriteUtil: %w", err)
-				}
-			default:
-				return fmt.Errorf("d.batchWriteUtil: %w", err)
-			}
-		}
-
-		if len(result.UnprocessedItems) == 0 {
-			fc.Reset() // reset configuration after loop
-			break
-		}
-
+	// batch write and error handling with exponential backoff retries for throttled requests
+	if _, err := d.writeBatch(context.Background(), reqItems, d.retryPolicy(retry)); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// BatchGet retrieves a list of items from the database
+// BatchGet retrieves a list of up to 100 items from the database.
 // refObjs must be non-nil pointers of the same type,
 // 1 for each query/object returned.
+// Items are matched to their queries by key and returned in query order:
+// the item of queries[i] is unmarshalled into refObjs[i] and returned at
+// index i, or nil is returned at index i if the item does not exist.
 //   - Returns err if len(queries) != len(refObjs).
-func (d *DynamoDB) BatchGet(tableName string, fc *FailConfig, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error) {
+func (d *DynamoDB) BatchGet(tableName string, retry RetryPolicy, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error) {
 	if len(queries) > 100 {
 		return nil, ErrCollectionSizeExceeded
 	}
@@ -425,76 +644,26 @@ func (d *DynamoDB) BatchGet(tableName string, fc *FailConfig, queries []*Query,
 		return nil, NewTableNotFoundErr(tableName)
 	}
 
-	items := []interface{}{}
-
-	// create map of RequestItems
-	reqItems := make(map[string]*dynamodb.KeysAndAttributes)
-	keys := []map[string]*dynamodb.AttributeValue{}
-
-	// create Get requests for each query
-	for _, q := range queries {
-		if q == nil {
-			continue
-		}
-
-		item := keyMaker(q, t)
-		keys = append(keys, item)
-	}
-	// populate reqItems map
-	ka := &dynamodb.KeysAndAttributes{Keys: keys}
-	reqItems[t.TableName] = ka
-
-	// generate input from reqItems map
-	input := &dynamodb.BatchGetItemInput{
-		RequestItems: reqItems,
+	results, err := d.batchGetItems(t, retry, queries, expr)
+	if err != nil {
+		return nil, err
 	}
 
-	// batch write and error handling with exponential backoff retries for HTTP 5xx errors
-	var result *dynamodb.BatchGetItemOutput
-	var err error
-	for {
-		result, err = d.batchGetUtil(input)
-		if err != nil {
-			switch {
-			case errors.Is(err, ErrRateLimitExceeded):
-				input = &dynamodb.BatchGetItemInput{
-					RequestItems: result.UnprocessedKeys,
-				}
-				fc.ExponentialBackoff() // waits
-				if fc.MaxRetriesReached {
-					return nil, fmt.Errorf("d.batchGetUtil: %w", err)
-				}
-			default:
-				return nil, fmt.Errorf("d.batchGetUtil: %w", err)
-			}
-		}
-
-		for i, r := range result.Responses[t.TableName] {
-			ref := refObjs[i]
-			if err := dynamodbattribute.UnmarshalMap(r, &ref); err != nil {
-				return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap, %w", err)
-			}
-			items = append(items, ref)
+	items := make([]interface{}, len(queries))
+	for i, r := range results {
+		if !r.Found {
+			continue
 		}
-
-		if len(result.UnprocessedKeys) == 0 {
-			fc.Reset() // reset configuration after loop
-			break
+		ref := refObjs[i]
+		if err := dynamodbattribute.UnmarshalMap(r.Item, &ref); err != nil {
+			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap, %w", err)
 		}
-
+		items[i] = ref
 	}
 
 	return items, nil
 }
 
-func (d *DynamoDB) batchWriteUtil(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
-	result, err := d.svc.BatchWriteItem(input)
-	if err != nil {
-		return nil, fmt.Errorf("d.svc.BatchWriteItem: %w", handleErr(err))
-	}
-	return result, nil
-}
-
 type ScanResults struct {
 	Results []any                               `json:"results"`
 	PerPage int64                               `json:"per_page,omitempy"`
@@ -503,53 +672,28 @@ type ScanResults struct {
 
 // ScanItems scans the given Table for items matching the given expression parameters.
 func (d *DynamoDB) ScanItems(tableName string, model any, startKey any, expr Expression, perPage *int64) (*ScanResults, error) {
+	return d.ScanIndex(tableName, "", model, startKey, expr, perPage)
+}
+
+// ScanIndex scans the named secondary index of the given Table for items matching
+// the given expression parameters. The table itself is scanned if indexName is empty.
+// A new value of model's type is allocated for each item returned.
+func (d *DynamoDB) ScanIndex(tableName, indexName string, model any, startKey any, expr Expression, perPage *int64) (*ScanResults, error) {
 	// get table
 	t := d.tables[tableName]
 	if t == nil {
 		return nil, NewTableNotFoundErr(tableName)
 	}
 
-	items := make([]any, 0)
-
-	// Build the query input parameters
-	input := &dynamodb.ScanInput{
-		ExpressionAttributeNames:  expr.Names(),
-		ExpressionAttributeValues: expr.Values(),
-		FilterExpression:          expr.Filter(),
-		ProjectionExpression:      expr.Projection(),
-		TableName:                 aws.String(t.TableName),
-		Limit:                     perPage,
-	}
-
-	if startKey != nil {
-		av, err := dynamodbattribute.MarshalMap(startKey)
-		if err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
-		}
-		input.ExclusiveStartKey = av
-	}
-
-	// Make the DynamoDB Query API call
-	result, err := d.svc.Scan(input)
+	result, err := d.scan(context.Background(), t, indexName, startKey, expr, perPage)
 	if err != nil {
-		return nil, fmt.Errorf("d.svc.Scan: %w", handleErr(err))
+		return nil, err
 	}
 
 	// get results
-	for _, res := range result.Items {
-		item := model
-		if err = dynamodbattribute.UnmarshalMap(res, &item); err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
-		}
-		items = append(items, item)
-	}
-
-	for _, res := range result.Items {
-		item := model
-		err = dynamodbattribute.UnmarshalMap(res, &item)
-		if err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
-		}
+	items, err := unmarshalModels(result.Items, model)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalModels: %w", err)
 	}
 
 	scanResult := &ScanResults{
@@ -571,17 +715,101 @@ type QueryResults struct {
 
 // QueryItems queries the given Table for items matching the given expression parameters.
 func (d *DynamoDB) QueryItems(tableName string, model any, startKey any, expr Expression, perPage *int64) (*QueryResults, error) {
+	return d.QueryIndex(tableName, "", model, startKey, expr, perPage)
+}
+
+// QueryIndex queries the named secondary index of the given Table for items matching
+// the given expression parameters. The table itself is queried if indexName is empty.
+// A new value of model's type is allocated for each item returned.
+func (d *DynamoDB) QueryIndex(tableName, indexName string, model any, startKey any, expr Expression, perPage *int64) (*QueryResults, error) {
 	// get table
 	t := d.tables[tableName]
 	if t == nil {
 		return nil, NewTableNotFoundErr(tableName)
 	}
 
-	items := make([]any, 0)
+	result, err := d.query(context.Background(), t, indexName, startKey, expr, perPage)
+	if err != nil {
+		return nil, err
+	}
 
-	// Build the query input parameters
-	input := &dynamodb.QueryInput{
-		KeyConditionExpression:    expr.KeyCondition(),
+	// get results
+	items, err := unmarshalModels(result.Items, model)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalModels: %w", err)
+	}
+
+	queryResult := &QueryResults{
+		Results: items,
+		LastKey: result.LastEvaluatedKey,
+	}
+
+	if perPage != nil {
+		queryResult.PerPage = *perPage
+	}
+
+	return queryResult, nil
+}
+
+// getItem reads the item identified by keys from t.
+// Returns an empty map if the item is not found.
+func (d *DynamoDB) getItem(keys map[string]*dynamodb.AttributeValue, t *Table, expr Expression) (map[string]*dynamodb.AttributeValue, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key:       keys,
+	}
+	if expr.Projection() != nil {
+		input.ExpressionAttributeNames = expr.Names()
+		input.ProjectionExpression = expr.Projection()
+	}
+
+	var result *dynamodb.GetItemOutput
+	err := retryThrottled(context.Background(), d.retryPolicy(nil), func() (err error) {
+		result, err = d.svc.GetItem(input)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.GetItem: %w", handleErr(err))
+	}
+	return result.Item, nil
+}
+
+// deleteItem deletes the item identified by keys from t if expr's condition holds.
+// The item must have the given version if t is versioned.
+func (d *DynamoDB) deleteItem(keys map[string]*dynamodb.AttributeValue, t *Table, version int64, expr Expression, returnValues string, out interface{}) error {
+	w := newWriteExpr(expr)
+	if t.VersionAttribute != "" {
+		w.requireVersion(t, version)
+	}
+	input := &dynamodb.DeleteItemInput{
+		ConditionExpression:                 w.condition,
+		ExpressionAttributeNames:            w.names,
+		ExpressionAttributeValues:           w.values,
+		Key:                                 keys,
+		ReturnValues:                        returnValuesInput(returnValues),
+		ReturnValuesOnConditionCheckFailure: w.onConditionCheckFailure(),
+		TableName:                           aws.String(t.TableName),
+	}
+
+	var result *dynamodb.DeleteItemOutput
+	err := retryThrottled(context.Background(), d.retryPolicy(nil), func() (err error) {
+		result, err = d.svc.DeleteItem(input)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("d.svc.DeleteItem: %w", versionErr(t, version, err))
+	}
+
+	return unmarshalReturnValues(result.Attributes, out)
+}
+
+// scan reads one page of items from t, or from the named index of t.
+func (d *DynamoDB) scan(ctx context.Context, t *Table, indexName string, startKey any, expr Expression, perPage *int64) (*dynamodb.ScanOutput, error) {
+	if indexName != "" && t.index(indexName) == nil {
+		return nil, NewIndexNotFoundErr(t.TableName, indexName)
+	}
+
+	input := &dynamodb.ScanInput{
 		ExpressionAttributeNames:  expr.Names(),
 		ExpressionAttributeValues: expr.Values(),
 		FilterExpression:          expr.Filter(),
@@ -590,58 +818,104 @@ func (d *DynamoDB) QueryItems(tableName string, model any, startKey any, expr Ex
 		Limit:                     perPage,
 	}
 
-	if startKey != nil {
-		av, err := dynamodbattribute.MarshalMap(startKey)
-		if err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
-		}
-		input.ExclusiveStartKey = av
+	if indexName != "" {
+		input.IndexName = aws.String(indexName)
 	}
 
-	// Make the DynamoDB Query API call
-	result, err := d.svc.Query(input)
+	sk, err := marshalStartKey(startKey)
 	if err != nil {
-		return nil, fmt.Errorf("d.svc.Scan: %w", handleErr(err))
+		return nil, err
 	}
+	input.ExclusiveStartKey = sk
 
-	// get results
-	for _, res := range result.Items {
-		item := model
-		if err = dynamodbattribute.UnmarshalMap(res, &item); err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
-		}
-		items = append(items, item)
+	result, err := d.svc.ScanWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.ScanWithContext: %w", handleErr(err))
 	}
+	return result, nil
+}
 
-	for _, res := range result.Items {
-		item := model
-		err = dynamodbattribute.UnmarshalMap(res, &item)
-		if err != nil {
-			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
-		}
+// query reads one page of items matching the expression's key condition
+// from t, or from the named index of t.
+func (d *DynamoDB) query(ctx context.Context, t *Table, indexName string, startKey any, expr Expression, perPage *int64) (*dynamodb.QueryOutput, error) {
+	if indexName != "" && t.index(indexName) == nil {
+		return nil, NewIndexNotFoundErr(t.TableName, indexName)
+	}
 
-		// get next page
-		input.ExclusiveStartKey = result.LastEvaluatedKey
+	input := &dynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		TableName:                 aws.String(t.TableName),
+		Limit:                     perPage,
 	}
 
-	queryResult := &QueryResults{
-		Results: items,
-		LastKey: result.LastEvaluatedKey,
+	if indexName != "" {
+		input.IndexName = aws.String(indexName)
 	}
 
-	if perPage != nil {
-		queryResult.PerPage = *perPage
+	sk, err := marshalStartKey(startKey)
+	if err != nil {
+		return nil, err
 	}
+	input.ExclusiveStartKey = sk
 
-	return queryResult, nil
+	result, err := d.svc.QueryWithContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.QueryWithContext: %w", handleErr(err))
+	}
+	return result, nil
 }
 
-func (d *DynamoDB) batchGetUtil(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
-	result, err := d.svc.BatchGetItem(input)
+// batchGetItems reads the items identified by queries from t, retrying
+// throttled requests and unprocessed keys with retry. Results are
+// returned in query order; nil queries are reported as not found.
+func (d *DynamoDB) batchGetItems(t *Table, retry RetryPolicy, queries []*Query, expr Expression) ([]BulkGetResult[map[string]*dynamodb.AttributeValue], error) {
+	reports, err := d.BatchGetTables(context.Background(), map[string]TableGet{t.TableName: {Queries: queries, Expr: expr}}, BulkOptions{Retry: retry})
 	if err != nil {
-		return nil, fmt.Errorf("d.svc.BatchGetItem: %w", handleErr(err))
+		return nil, err
+	}
+	results := reports[t.TableName].Results
+	for i, r := range results {
+		switch {
+		case queries[i] == nil:
+			results[i] = BulkGetResult[map[string]*dynamodb.AttributeValue]{Index: i}
+		case r.Err != nil:
+			return nil, r.Err
+		}
 	}
-	return result, nil
+	return results, nil
+}
+
+// unmarshalModels unmarshals each item into a new value of model's type.
+// Pointer models return pointers to new values; all other models return values.
+func unmarshalModels(avs []map[string]*dynamodb.AttributeValue, model any) ([]any, error) {
+	items := make([]any, 0, len(avs))
+	if model == nil {
+		model = map[string]any{}
+	}
+
+	mt := reflect.TypeOf(model)
+	isPtr := mt.Kind() == reflect.Pointer
+	if isPtr {
+		mt = mt.Elem()
+	}
+
+	for _, av := range avs {
+		item := reflect.New(mt)
+		if err := dynamodbattribute.UnmarshalMap(av, item.Interface()); err != nil {
+			return nil, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+		}
+		if isPtr {
+			items = append(items, item.Interface())
+			continue
+		}
+		items = append(items, item.Elem().Interface())
+	}
+
+	return items, nil
 }
 
 func handleErr(err error) error {
@@ -657,7 +931,11 @@ func handleErr(err error) error {
 			case dynamodb.ErrCodeRequestLimitExceeded:
 				return ErrRateLimitExceeded
 			case dynamodb.ErrCodeConditionalCheckFailedException:
-				return NewConditionCheckFailedErr(aerr.Message())
+				e := NewConditionCheckFailedErr(aerr.Message())
+				if ccf, ok := err.(*dynamodb.ConditionalCheckFailedException); ok {
+					e.item = ccf.Item
+				}
+				return e
 			case dynamodb.ErrCodeInternalServerError:
 				return err
 			default:
@@ -671,6 +949,42 @@ func handleErr(err error) error {
 	return nil
 }
 
+// returnValuesInput returns the ReturnValues setting of a write input.
+func returnValuesInput(returnValues string) *string {
+	if returnValues == "" || returnValues == ReturnValuesNone {
+		return nil
+	}
+	return aws.String(returnValues)
+}
+
+// unmarshalReturnValues unmarshals the attributes returned by a write into out,
+// if set. out is left unchanged if no attributes were returned.
+func unmarshalReturnValues(attrs map[string]*dynamodb.AttributeValue, out interface{}) error {
+	if out == nil || len(attrs) == 0 {
+		return nil
+	}
+	if err := dynamodbattribute.UnmarshalMap(attrs, out); err != nil {
+		return fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+	}
+	return nil
+}
+
+// marshalStartKey marshals a start key into an AttributeValue map.
+// LastKey maps from previous results are used as is.
+func marshalStartKey(startKey any) (map[string]*dynamodb.AttributeValue, error) {
+	switch sk := startKey.(type) {
+	case nil:
+		return nil, nil
+	case map[string]*dynamodb.AttributeValue:
+		return sk, nil
+	}
+	av, err := dynamodbattribute.MarshalMap(startKey)
+	if err != nil {
+		return nil, fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
+	}
+	return av, nil
+}
+
 // marshalMap marshals an interface object into an AttributeValue map
 func marshalMap(input interface{}) (map[string]*dynamodb.AttributeValue, error) {
 	marshal, err := dynamodbattribute.MarshalMap(input)
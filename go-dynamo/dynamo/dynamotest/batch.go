@@ -0,0 +1,175 @@
+package dynamotest
+
+// This file contains batch operations.
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// BatchWriteItem puts or deletes up to 25 items in one or more tables.
+func (db *DB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("BatchWriteItem"); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, wrs := range input.RequestItems {
+		total += len(wrs)
+	}
+	if total == 0 {
+		return nil, validationErr("The batch write request list for a table cannot be null or empty")
+	}
+	if total > maxBatchWriteItems {
+		return nil, validationErr("Too many items requested for the BatchWriteItem call")
+	}
+
+	// validate every request before applying any of them
+	for _, name := range sortedNames(input.RequestItems) {
+		t, err := db.table(aws.String(name))
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[string]bool)
+		for _, wr := range input.RequestItems[name] {
+			var key item
+			switch {
+			case wr.PutRequest != nil && wr.DeleteRequest == nil:
+				if err := t.validateItem(wr.PutRequest.Item); err != nil {
+					return nil, validationErr("%s", err.Error())
+				}
+				key = wr.PutRequest.Item
+			case wr.DeleteRequest != nil && wr.PutRequest == nil:
+				if err := t.keys.validateKey(wr.DeleteRequest.Key); err != nil {
+					return nil, validationErr("%s", err.Error())
+				}
+				key = wr.DeleteRequest.Key
+			default:
+				return nil, validationErr("Supplied AttributeValue has more than one datatypes set, must contain exactly one of the supported datatypes")
+			}
+			k := t.keys.encode(key)
+			if seen[k] {
+				return nil, validationErr("Provided list of item keys contains duplicates")
+			}
+			seen[k] = true
+		}
+	}
+
+	out := &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}
+	processed := 0
+	for _, name := range sortedNames(input.RequestItems) {
+		t := db.tables[name]
+		for _, wr := range input.RequestItems[name] {
+			if db.MaxBatchWriteProcessed > 0 && processed == db.MaxBatchWriteProcessed {
+				out.UnprocessedItems[name] = append(out.UnprocessedItems[name], wr)
+				continue
+			}
+			processed++
+			if wr.PutRequest != nil {
+				t.put(item(cloneItem(wr.PutRequest.Item)))
+				continue
+			}
+			t.remove(wr.DeleteRequest.Key)
+		}
+	}
+	return out, nil
+}
+
+// BatchGetItem returns up to 100 items from one or more tables.
+func (db *DB) BatchGetItem(input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("BatchGetItem"); err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, ka := range input.RequestItems {
+		if ka != nil {
+			total += len(ka.Keys)
+		}
+	}
+	if total == 0 {
+		return nil, validationErr("The list of keys for a table cannot be null or empty")
+	}
+	if total > maxBatchGetItems {
+		return nil, validationErr("Too many items requested for the BatchGetItem call")
+	}
+
+	projections := make(map[string][]docPath)
+	for _, name := range sortedNames(input.RequestItems) {
+		t, err := db.table(aws.String(name))
+		if err != nil {
+			return nil, err
+		}
+		ka := input.RequestItems[name]
+		if ka == nil || len(ka.Keys) == 0 {
+			return nil, validationErr("The list of keys for a table cannot be null or empty")
+		}
+		attrs := newExprAttrs(ka.ExpressionAttributeNames, nil)
+		if projections[name], err = parseOptionalProjection(ka.ProjectionExpression, attrs); err != nil {
+			return nil, err
+		}
+		if err := attrs.checkUnused(); err != nil {
+			return nil, validationErr("%s", err.Error())
+		}
+		seen := make(map[string]bool)
+		for _, key := range ka.Keys {
+			if err := t.keys.validateKey(key); err != nil {
+				return nil, validationErr("%s", err.Error())
+			}
+			k := t.keys.encode(key)
+			if seen[k] {
+				return nil, validationErr("Provided list of item keys contains duplicates")
+			}
+			seen[k] = true
+		}
+	}
+
+	out := &dynamodb.BatchGetItemOutput{
+		Responses:       map[string][]map[string]*dynamodb.AttributeValue{},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{},
+	}
+	processed := 0
+	for _, name := range sortedNames(input.RequestItems) {
+		t, ka := db.tables[name], input.RequestItems[name]
+		out.Responses[name] = []map[string]*dynamodb.AttributeValue{}
+		for _, key := range ka.Keys {
+			if db.MaxBatchGetProcessed > 0 && processed == db.MaxBatchGetProcessed {
+				if out.UnprocessedKeys[name] == nil {
+					out.UnprocessedKeys[name] = &dynamodb.KeysAndAttributes{
+						ConsistentRead:           ka.ConsistentRead,
+						ExpressionAttributeNames: ka.ExpressionAttributeNames,
+						ProjectionExpression:     ka.ProjectionExpression,
+					}
+				}
+				out.UnprocessedKeys[name].Keys = append(out.UnprocessedKeys[name].Keys, key)
+				continue
+			}
+			processed++
+			if it := t.get(key); it != nil {
+				out.Responses[name] = append(out.Responses[name], applyProjection(it, projections[name]))
+			}
+		}
+	}
+	return out, nil
+}
+
+// BatchWriteItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, _ ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.BatchWriteItem(input)
+}
+
+// BatchGetItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, _ ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.BatchGetItem(input)
+}
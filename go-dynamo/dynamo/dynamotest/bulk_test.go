@@ -0,0 +1,261 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func TestBulkWriteAndGet(t *testing.T) {
+	svc, db := New(testTable)
+	db.MaxBatchWriteProcessed = 10
+	db.MaxBatchGetProcessed = 30
+	opts := dynamo.BulkOptions{Concurrency: 3, Retry: fastRetry}
+	ctx := context.Background()
+
+	items := []interface{}{}
+	queries := []*dynamo.Query{}
+	for i := 0; i < 60; i++ {
+		items = append(items, record{Partition: "A", UUID: fmt.Sprintf("%03d", i), Count: i})
+		queries = append(queries, dynamo.CreateNewQueryObj("A", fmt.Sprintf("%03d", i)))
+	}
+	items = append(items, record{Partition: "A", UUID: "001"}, record{Partition: "A"})
+
+	report, err := svc.BulkWriteCreate(ctx, TableName, items, opts)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if report.Failed != 2 || len(report.Results) != 62 {
+		t.Errorf("FAIL - DATA: %d failed of %d; want: 2 of 62", report.Failed, len(report.Results))
+	}
+	if err := report.Results[60].Err; !errors.Is(err, dynamo.ErrDuplicateKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrDuplicateKey)
+	}
+	if err := report.Results[61].Err; !errors.Is(err, dynamo.ErrMissingKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrMissingKey)
+	}
+	if n := len(db.Items(TableName)); n != 60 {
+		t.Errorf("FAIL: %d items; want: 60", n)
+	}
+
+	// duplicate and missing keys are reported in query order
+	queries = append(queries, dynamo.CreateNewQueryObj("A", "999"), dynamo.CreateNewQueryObj("A", "002"))
+	got, err := dynamo.BulkGet[record](ctx, svc, TableName, queries, dynamo.NewExpression(), opts)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if got.Failed != 0 || len(got.Results) != 62 {
+		t.Fatalf("FAIL - DATA: %d failed of %d; want: 0 of 62", got.Failed, len(got.Results))
+	}
+	for i, r := range got.Results[:60] {
+		if r.Index != i || !r.Found || r.Item.Count != i {
+			t.Errorf("FAIL - DATA: %+v; want count: %d", r, i)
+		}
+	}
+	if r := got.Results[60]; r.Found || r.Err != nil {
+		t.Errorf("FAIL - DATA: %+v; want not found", r)
+	}
+	if r := got.Results[61]; !r.Found || r.Item.Count != 2 {
+		t.Errorf("FAIL - DATA: %+v; want count: 2", r)
+	}
+
+	report, err = svc.BulkWriteDelete(ctx, TableName, queries, opts)
+	if err != nil || report.Failed != 1 {
+		t.Errorf("FAIL: %+v, %v", report, err)
+	}
+	if n := len(db.Items(TableName)); n != 0 {
+		t.Errorf("FAIL: %d items; want: 0", n)
+	}
+}
+
+func TestBulkWriteFailures(t *testing.T) {
+	items := []interface{}{}
+	for i := 0; i < 60; i++ {
+		items = append(items, record{Partition: "A", UUID: fmt.Sprintf("%03d", i)})
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var tests = []struct {
+		name       string
+		ctx        context.Context
+		fail       error
+		wantFailed int
+		wantErr    error
+	}{
+		// the first batch fails, the others are written
+		{name: "batch error", ctx: context.Background(), fail: awserr.New(dynamodb.ErrCodeInternalServerError, "internal error", nil), wantFailed: 25},
+		// throttled batches are retried
+		{name: "throttled", ctx: context.Background(), fail: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)},
+		{name: "cancelled", ctx: cancelled, wantFailed: 60, wantErr: context.Canceled},
+	}
+	for _, test := range tests {
+		svc, db := New(testTable)
+		if test.fail != nil {
+			db.FailNext("BatchWriteItem", 1, test.fail)
+		}
+		report, err := svc.BulkWriteCreate(test.ctx, TableName, items, dynamo.BulkOptions{Concurrency: 1, Retry: fastRetry})
+		if err != nil {
+			t.Fatalf("FAIL: %s: %v", test.name, err)
+		}
+		if report.Failed != test.wantFailed || len(db.Items(TableName)) != 60-test.wantFailed {
+			t.Errorf("FAIL - DATA: %s: %d failed; want: %d", test.name, report.Failed, test.wantFailed)
+		}
+		if test.wantErr != nil && !errors.Is(report.Results[0].Err, test.wantErr) {
+			t.Errorf("FAIL: %s: %v; want: %v", test.name, report.Results[0].Err, test.wantErr)
+		}
+	}
+
+	svc, _ := New(testTable)
+	if _, err := svc.BulkWriteCreate(context.Background(), "missing", items, dynamo.BulkOptions{}); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+}
+
+func TestBatchWriteAndGetTables(t *testing.T) {
+	docs, err := dynamo.NewTableFromStruct("docs", doc{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	docs.VersionAttribute = ""
+	svc, db := New(testTable, docs)
+	seed(t, svc)
+	opts := dynamo.BulkOptions{Retry: fastRetry}
+	ctx := context.Background()
+
+	items := []dynamo.BatchItem{
+		dynamo.NewBatchPutItem(TableName, record{Partition: "A", UUID: "010", Count: 1}),
+		dynamo.NewBatchPutItem("docs", doc{ID: "d1", Body: "one"}),
+		dynamo.NewBatchDeleteItem(TableName, dynamo.CreateNewQueryObj("A", "001")),
+		dynamo.NewBatchPutItem("docs", doc{ID: "d1", Body: "again"}),
+		dynamo.NewBatchPutItem("docs", doc{Body: "no key"}),
+		dynamo.NewBatchDeleteItem(TableName, nil),
+		dynamo.NewBatchDeleteItem(TableName, dynamo.CreateNewQueryObj("C", nil)),
+	}
+	reports, err := svc.BatchWriteTables(ctx, items, opts)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	var tests = []struct {
+		table       string
+		wantIndexes []int
+		wantFailed  int
+		wantItems   int
+	}{
+		{table: TableName, wantIndexes: []int{0, 2, 5, 6}, wantFailed: 2, wantItems: 5},
+		{table: "docs", wantIndexes: []int{1, 3, 4}, wantFailed: 2, wantItems: 1},
+	}
+	for _, test := range tests {
+		report := reports[test.table]
+		if report == nil || report.Failed != test.wantFailed || len(report.Results) != len(test.wantIndexes) {
+			t.Errorf("FAIL - DATA: %s: %+v", test.table, report)
+			continue
+		}
+		for i, r := range report.Results {
+			if r.Index != test.wantIndexes[i] {
+				t.Errorf("FAIL - DATA: %s: index %d; want: %d", test.table, r.Index, test.wantIndexes[i])
+			}
+		}
+		if n := len(db.Items(test.table)); n != test.wantItems {
+			t.Errorf("FAIL: %s: %d items; want: %d", test.table, n, test.wantItems)
+		}
+	}
+	if err := reports["docs"].Results[1].Err; !errors.Is(err, dynamo.ErrDuplicateKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrDuplicateKey)
+	}
+	// a missing key value fails its item only, not the batch
+	if err := reports[TableName].Results[3].Err; !errors.Is(err, dynamo.ErrMissingKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrMissingKey)
+	}
+
+	// items are matched to queries by key even if the projection omits the keys
+	eb := dynamo.NewExprBuilder()
+	eb.SetProjection([]string{"count"})
+	withKey := dynamo.NewExprBuilder()
+	withKey.SetProjection([]string{"id", "body"})
+	got, err := svc.BatchGetTables(ctx, map[string]dynamo.TableGet{
+		TableName: {Queries: []*dynamo.Query{
+			dynamo.CreateNewQueryObj("B", "004"),
+			dynamo.CreateNewQueryObj("A", "001"),
+			dynamo.CreateNewQueryObj("A", "010"),
+			dynamo.CreateNewQueryObj(nil, "004"),
+		}, Expr: buildExpr(t, eb)},
+		"docs": {Queries: []*dynamo.Query{dynamo.CreateNewQueryObj("d1", nil)}, Expr: buildExpr(t, withKey)},
+	}, opts)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	records := dynamo.UnmarshalGetReport[record](got[TableName])
+	if records.Failed != 1 || len(records.Results) != 4 {
+		t.Fatalf("FAIL - DATA: %+v", records)
+	}
+	if err := records.Results[3].Err; !errors.Is(err, dynamo.ErrMissingKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrMissingKey)
+	}
+	for i, want := range []struct {
+		found bool
+		count int
+	}{{found: true, count: 10}, {}, {found: true, count: 1}} {
+		r := records.Results[i]
+		if r.Found != want.found || r.Item.Count != want.count || r.Item.UUID != "" || r.Item.CountMap != nil {
+			t.Errorf("FAIL - DATA: %+v; want: %+v", r, want)
+		}
+	}
+	d1 := dynamo.UnmarshalGetReport[doc](got["docs"]).Results[0]
+	if !d1.Found || d1.Item.Body != "one" || d1.Item.Version != 0 {
+		t.Errorf("FAIL - DATA: %+v", d1)
+	}
+
+	if _, err := svc.BatchWriteTables(ctx, []dynamo.BatchItem{dynamo.NewBatchPutItem("missing", record{})}, opts); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+	if _, err := svc.BatchWriteTables(ctx, []dynamo.BatchItem{{TableName: TableName}}, opts); !errors.Is(err, dynamo.ErrInvalidRequestType) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidRequestType)
+	}
+	if _, err := svc.BatchGetTables(ctx, map[string]dynamo.TableGet{"missing": {}}, opts); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+}
+
+func TestBatchWriteVersionedTable(t *testing.T) {
+	docs, err := dynamo.NewTableFromStruct("docs", doc{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	svc, db := New(testTable, docs)
+	if err := svc.CreateItem(doc{ID: "d1", Body: "one"}, "docs"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	// batch writes cannot check versions, so items of versioned tables fail
+	reports, err := svc.BatchWriteTables(context.Background(), []dynamo.BatchItem{
+		dynamo.NewBatchPutItem("docs", doc{ID: "d1", Body: "overwrite"}),
+		dynamo.NewBatchPutItem(TableName, record{Partition: "A", UUID: "001"}),
+		dynamo.NewBatchDeleteItem("docs", dynamo.CreateNewQueryObj("d1", nil)),
+	}, dynamo.BulkOptions{Retry: fastRetry})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if r := reports[TableName]; r.Failed != 0 {
+		t.Errorf("FAIL - DATA: %+v", r)
+	}
+	if r := reports["docs"]; r.Failed != 2 || !errors.Is(r.Results[0].Err, dynamo.ErrVersionedTable) || !errors.Is(r.Results[1].Err, dynamo.ErrVersionedTable) {
+		t.Errorf("FAIL - DATA: %+v; want: %v", r, dynamo.ErrVersionedTable)
+	}
+	if err := svc.BatchWriteCreate("docs", nil, []interface{}{doc{ID: "d2"}}); !errors.Is(err, dynamo.ErrVersionedTable) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrVersionedTable)
+	}
+	if err := svc.BatchWriteDelete("docs", nil, []*dynamo.Query{dynamo.CreateNewQueryObj("d1", nil)}); !errors.Is(err, dynamo.ErrVersionedTable) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrVersionedTable)
+	}
+
+	got, err := dynamo.GetItem[doc](svc, dynamo.CreateNewQueryObj("d1", nil), "docs", dynamo.NewExpression())
+	if err != nil || got.Body != "one" || got.Version != 1 || len(db.Items("docs")) != 1 {
+		t.Errorf("FAIL: %+v, %v", got, err)
+	}
+}
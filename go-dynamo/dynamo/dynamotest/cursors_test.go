@@ -0,0 +1,103 @@
+package dynamotest
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func TestCursorPagination(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+	codec := dynamo.NewCursorCodec([]byte("secret"))
+
+	// page through the table, handing only the cursor between requests
+	got, next := 0, ""
+	for page := 0; ; page++ {
+		startKey, err := codec.Decode(next, TableName, "")
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		res, err := svc.ScanItems(TableName, &record{}, startKey, dynamo.NewExpression(), aws.Int64(2))
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		got += len(res.Results)
+		if next, err = codec.Encode(res.LastKey, TableName, ""); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		if strings.ContainsAny(next, "+/=") {
+			t.Errorf("FAIL - DATA: cursor %s is not URL safe", next)
+		}
+		if next == "" {
+			break
+		}
+		if page > 5 {
+			t.Fatalf("FAIL: scan did not terminate")
+		}
+	}
+	if got != 5 {
+		t.Errorf("FAIL: %d items; want: 5", got)
+	}
+}
+
+func TestCursorDecode(t *testing.T) {
+	key := map[string]*dynamodb.AttributeValue{
+		"partition": {S: aws.String("A")},
+		"uuid":      {S: aws.String("001")},
+		"year":      {N: aws.String("2021")},
+	}
+	signed := dynamo.NewCursorCodec([]byte("secret"))
+	unsigned := dynamo.NewCursorCodec(nil)
+	cur, err := signed.Encode(key, TableName, "gsi1")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	plain, err := unsigned.Encode(key, TableName, "gsi1")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	var tests = []struct {
+		codec   *dynamo.CursorCodec
+		cursor  string
+		table   string
+		index   string
+		wantErr bool
+	}{
+		{codec: signed, cursor: cur, table: TableName, index: "gsi1"},
+		{codec: unsigned, cursor: plain, table: TableName, index: "gsi1"},
+		{codec: signed, cursor: cur, table: "other", index: "gsi1", wantErr: true}, // wrong table
+		{codec: signed, cursor: cur, table: TableName, wantErr: true},              // wrong index
+		{codec: signed, cursor: plain, table: TableName, index: "gsi1", wantErr: true},
+		{codec: unsigned, cursor: cur, table: TableName, index: "gsi1", wantErr: true},
+		{codec: dynamo.NewCursorCodec([]byte("other")), cursor: cur, table: TableName, index: "gsi1", wantErr: true},
+		{codec: signed, cursor: "x" + cur, table: TableName, index: "gsi1", wantErr: true},
+		{codec: unsigned, cursor: "not json", table: TableName, index: "gsi1", wantErr: true},
+	}
+	for i, test := range tests {
+		got, err := test.codec.Decode(test.cursor, test.table, test.index)
+		if test.wantErr {
+			if !errors.Is(err, dynamo.ErrInvalidCursor) {
+				t.Errorf("FAIL: %d: %v; want: %v", i, err, dynamo.ErrInvalidCursor)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FAIL: %d: %v", i, err)
+			continue
+		}
+		if len(got) != 3 || aws.StringValue(got["uuid"].S) != "001" || aws.StringValue(got["year"].N) != "2021" {
+			t.Errorf("FAIL - DATA: %d: %v", i, got)
+		}
+	}
+
+	// only key attribute types can be encoded
+	if _, err := unsigned.Encode(map[string]*dynamodb.AttributeValue{"pk": {BOOL: aws.Bool(true)}}, TableName, ""); !errors.Is(err, dynamo.ErrInvalidCursor) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidCursor)
+	}
+}
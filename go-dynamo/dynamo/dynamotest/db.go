@@ -0,0 +1,508 @@
+// Package dynamotest provides an in-memory implementation of the DynamoDB API
+// for testing code built on the dynamo package without a live database.
+// This file contains the DB type and table level operations.
+package dynamotest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+const (
+	// maxBatchWriteItems is the maximum number of requests in a BatchWriteItem call.
+	maxBatchWriteItems = 25
+	// maxBatchGetItems is the maximum number of keys in a BatchGetItem call.
+	maxBatchGetItems = 100
+	// maxTransactItems is the maximum number of actions in a transaction.
+	maxTransactItems = 100
+	// idempotencyWindow is how long a ClientRequestToken is remembered.
+	idempotencyWindow = 10 * time.Minute
+
+	errCodeValidation = "ValidationException"
+)
+
+// DB is an in-memory implementation of dynamodbiface.DynamoDBAPI.
+// Operations not implemented by DB panic.
+type DB struct {
+	dynamodbiface.DynamoDBAPI
+
+	mu     sync.Mutex
+	tables map[string]*table
+	// deleting holds the descriptions of deleted tables until StatusDelay has passed
+	deleting map[string]deletedTable
+	tokens   map[string]txToken
+	faults   map[string][]error
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+	// MaxBatchWriteProcessed limits the number of write requests processed per
+	// BatchWriteItem call; the remainder are returned as UnprocessedItems. 0 means no limit.
+	MaxBatchWriteProcessed int
+	// MaxBatchGetProcessed limits the number of keys processed per
+	// BatchGetItem call; the remainder are returned as UnprocessedKeys. 0 means no limit.
+	MaxBatchGetProcessed int
+	// StatusDelay is how long DescribeTable reports new tables as CREATING
+	// and deleted tables as DELETING. Items can be used immediately.
+	StatusDelay time.Duration
+}
+
+// NewDB returns a new DB containing the given tables.
+// It panics if a table definition is invalid.
+func NewDB(tables ...*dynamo.Table) *DB {
+	db := &DB{
+		tables:   make(map[string]*table),
+		deleting: make(map[string]deletedTable),
+		tokens:   make(map[string]txToken),
+		faults:   make(map[string][]error),
+		Now:      time.Now,
+	}
+	// tables are created exactly as dynamo.CreateTable creates them
+	svc := dynamo.NewDynamoDBWithClient(db, nil, nil)
+	for _, t := range tables {
+		if err := svc.CreateTable(t); err != nil {
+			panic(fmt.Sprintf("dynamotest: create table %s: %v", t.TableName, err))
+		}
+	}
+	return db
+}
+
+// New returns a dynamo.DynamoDB backed by a new DB containing the given tables.
+// Throttled requests are not retried unless an operation is given a RetryPolicy.
+func New(tables ...*dynamo.Table) (*dynamo.DynamoDB, *DB) {
+	db := NewDB(tables...)
+	return dynamo.NewDynamoDBWithClient(db, tables, &dynamo.Backoff{MaxAttempts: 1}), db
+}
+
+// FailNext causes the next n calls to the named operation (e.g. "BatchWriteItem")
+// to return err without being applied.
+func (db *DB) FailNext(op string, n int, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for i := 0; i < n; i++ {
+		db.faults[op] = append(db.faults[op], err)
+	}
+}
+
+// Items returns a copy of every item stored in the table, in key order.
+func (db *DB) Items(tableName string) []map[string]*dynamodb.AttributeValue {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	t := db.tables[tableName]
+	if t == nil {
+		return nil
+	}
+	out := []map[string]*dynamodb.AttributeValue{}
+	for _, it := range t.sorted(nil) {
+		out = append(out, cloneItem(it))
+	}
+	return out
+}
+
+// fault returns the next injected error for op, if any. db.mu must be held.
+func (db *DB) fault(op string) error {
+	errs := db.faults[op]
+	if len(errs) == 0 {
+		return nil
+	}
+	db.faults[op] = errs[1:]
+	return errs[0]
+}
+
+// table returns the named table. db.mu must be held.
+func (db *DB) table(name *string) (*table, error) {
+	t := db.tables[aws.StringValue(name)]
+	if t == nil {
+		return nil, &dynamodb.ResourceNotFoundException{
+			Message_: aws.String("Requested resource not found: Table: " + aws.StringValue(name) + " not found"),
+		}
+	}
+	return t, nil
+}
+
+func validationErr(format string, args ...any) error {
+	return awserr.New(errCodeValidation, fmt.Sprintf(format, args...), nil)
+}
+
+// CreateTable creates a new table. Tables are ACTIVE immediately.
+func (db *DB) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("CreateTable"); err != nil {
+		return nil, err
+	}
+
+	name := aws.StringValue(input.TableName)
+	if len(name) < 3 {
+		return nil, validationErr("TableName must be at least 3 characters long")
+	}
+	if _, ok := db.tables[name]; ok || db.isDeleting(name) {
+		return nil, &dynamodb.ResourceInUseException{Message_: aws.String("Table already exists: " + name)}
+	}
+	t, err := newTable(input)
+	if err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	if err := validateThroughput(input.BillingMode, input.ProvisionedThroughput); err != nil {
+		return nil, err
+	}
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		if err := validateThroughput(input.BillingMode, gsi.ProvisionedThroughput); err != nil {
+			return nil, err
+		}
+	}
+
+	now := db.Now()
+	t.desc = &dynamodb.TableDescription{
+		AttributeDefinitions: input.AttributeDefinitions,
+		CreationDateTime:     aws.Time(now),
+		KeySchema:            input.KeySchema,
+		TableArn:             aws.String("arn:aws:dynamodb:local:000000000000:table/" + name),
+		TableName:            aws.String(name),
+		TableStatus:          aws.String(dynamodb.TableStatusActive),
+		ItemCount:            aws.Int64(0),
+	}
+	billing := aws.StringValue(input.BillingMode)
+	if billing == "" {
+		billing = dynamodb.BillingModeProvisioned
+	}
+	t.desc.BillingModeSummary = &dynamodb.BillingModeSummary{BillingMode: aws.String(billing)}
+	t.desc.ProvisionedThroughput = throughputDescription(input.ProvisionedThroughput)
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		t.desc.GlobalSecondaryIndexes = append(t.desc.GlobalSecondaryIndexes, &dynamodb.GlobalSecondaryIndexDescription{
+			IndexName:             gsi.IndexName,
+			IndexStatus:           aws.String(dynamodb.IndexStatusActive),
+			KeySchema:             gsi.KeySchema,
+			Projection:            gsi.Projection,
+			ProvisionedThroughput: throughputDescription(gsi.ProvisionedThroughput),
+		})
+	}
+	for _, lsi := range input.LocalSecondaryIndexes {
+		t.desc.LocalSecondaryIndexes = append(t.desc.LocalSecondaryIndexes, &dynamodb.LocalSecondaryIndexDescription{
+			IndexName:  lsi.IndexName,
+			KeySchema:  lsi.KeySchema,
+			Projection: lsi.Projection,
+		})
+	}
+	t.desc.StreamSpecification = input.StreamSpecification
+	if input.StreamSpecification != nil && aws.BoolValue(input.StreamSpecification.StreamEnabled) {
+		t.desc.LatestStreamArn = aws.String(*t.desc.TableArn + "/stream/" + now.UTC().Format("2006-01-02T15:04:05.000"))
+	}
+	if input.SSESpecification != nil && aws.BoolValue(input.SSESpecification.Enabled) {
+		t.desc.SSEDescription = &dynamodb.SSEDescription{
+			KMSMasterKeyArn: input.SSESpecification.KMSMasterKeyId,
+			SSEType:         input.SSESpecification.SSEType,
+			Status:          aws.String(dynamodb.SSEStatusEnabled),
+		}
+	}
+	if input.TableClass != nil {
+		t.desc.TableClassSummary = &dynamodb.TableClassSummary{TableClass: input.TableClass}
+	}
+	t.desc.DeletionProtectionEnabled = input.DeletionProtectionEnabled
+	t.tags = input.Tags
+
+	db.tables[name] = t
+	return &dynamodb.CreateTableOutput{TableDescription: db.describe(t)}, nil
+}
+
+// UpdateTable changes the billing mode, provisioned throughput, stream,
+// encryption, table class or deletion protection of a table.
+// Global secondary indexes can be updated but not created or deleted.
+func (db *DB) UpdateTable(input *dynamodb.UpdateTableInput) (*dynamodb.UpdateTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("UpdateTable"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	billing := t.desc.BillingModeSummary.BillingMode
+	if input.BillingMode != nil {
+		billing = input.BillingMode
+	}
+	throughput := input.ProvisionedThroughput
+	if throughput == nil && aws.StringValue(billing) == dynamodb.BillingModeProvisioned && t.desc.ProvisionedThroughput != nil {
+		throughput = &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  t.desc.ProvisionedThroughput.ReadCapacityUnits,
+			WriteCapacityUnits: t.desc.ProvisionedThroughput.WriteCapacityUnits,
+		}
+	}
+	if err := validateThroughput(billing, throughput); err != nil {
+		return nil, err
+	}
+	gsis := make(map[string]*dynamodb.ProvisionedThroughput)
+	for _, u := range input.GlobalSecondaryIndexUpdates {
+		if u.Create != nil || u.Delete != nil || u.Update == nil {
+			return nil, validationErr("dynamotest: only global secondary index updates are supported")
+		}
+		name := aws.StringValue(u.Update.IndexName)
+		if idx := t.indexes[name]; idx == nil || !idx.global {
+			return nil, &dynamodb.ResourceNotFoundException{Message_: aws.String("Requested resource not found: Index: " + name)}
+		}
+		gsis[name] = u.Update.ProvisionedThroughput
+	}
+	for _, gsi := range t.desc.GlobalSecondaryIndexes {
+		if _, ok := gsis[aws.StringValue(gsi.IndexName)]; !ok && aws.StringValue(billing) == dynamodb.BillingModeProvisioned && gsi.ProvisionedThroughput == nil {
+			return nil, validationErr("One or more parameter values were invalid: ProvisionedThroughput must be specified for index: %s", aws.StringValue(gsi.IndexName))
+		}
+		if err := validateThroughput(billing, gsis[aws.StringValue(gsi.IndexName)]); err != nil {
+			return nil, err
+		}
+	}
+
+	desc := *t.desc
+	desc.BillingModeSummary = &dynamodb.BillingModeSummary{BillingMode: billing}
+	desc.ProvisionedThroughput = throughputDescription(throughput)
+	desc.GlobalSecondaryIndexes = nil
+	for _, gsi := range t.desc.GlobalSecondaryIndexes {
+		g := *gsi
+		if pt, ok := gsis[aws.StringValue(g.IndexName)]; ok {
+			g.ProvisionedThroughput = throughputDescription(pt)
+		} else if aws.StringValue(billing) == dynamodb.BillingModePayPerRequest {
+			g.ProvisionedThroughput = nil
+		}
+		desc.GlobalSecondaryIndexes = append(desc.GlobalSecondaryIndexes, &g)
+	}
+	if input.StreamSpecification != nil {
+		desc.StreamSpecification = input.StreamSpecification
+		if aws.BoolValue(input.StreamSpecification.StreamEnabled) {
+			desc.LatestStreamArn = aws.String(*t.desc.TableArn + "/stream/" + db.Now().UTC().Format("2006-01-02T15:04:05.000"))
+		}
+	}
+	if input.SSESpecification != nil {
+		desc.SSEDescription = &dynamodb.SSEDescription{
+			KMSMasterKeyArn: input.SSESpecification.KMSMasterKeyId,
+			SSEType:         input.SSESpecification.SSEType,
+			Status:          aws.String(dynamodb.SSEStatusDisabled),
+		}
+		if aws.BoolValue(input.SSESpecification.Enabled) {
+			desc.SSEDescription.Status = aws.String(dynamodb.SSEStatusEnabled)
+		}
+	}
+	if input.TableClass != nil {
+		desc.TableClassSummary = &dynamodb.TableClassSummary{TableClass: input.TableClass}
+	}
+	if input.DeletionProtectionEnabled != nil {
+		desc.DeletionProtectionEnabled = input.DeletionProtectionEnabled
+	}
+	t.desc = &desc
+	return &dynamodb.UpdateTableOutput{TableDescription: t.describe()}, nil
+}
+
+// ListTagsOfResource returns the tags of the table with the given ARN.
+func (db *DB) ListTagsOfResource(input *dynamodb.ListTagsOfResourceInput) (*dynamodb.ListTagsOfResourceOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("ListTagsOfResource"); err != nil {
+		return nil, err
+	}
+
+	for _, t := range db.tables {
+		if aws.StringValue(t.desc.TableArn) == aws.StringValue(input.ResourceArn) {
+			return &dynamodb.ListTagsOfResourceOutput{Tags: t.tags}, nil
+		}
+	}
+	return nil, &dynamodb.ResourceNotFoundException{Message_: aws.String("Requested resource not found: ResourcArn: " + aws.StringValue(input.ResourceArn) + " not found")}
+}
+
+// validateThroughput checks that throughput is set if and only if the billing mode is provisioned.
+func validateThroughput(billingMode *string, pt *dynamodb.ProvisionedThroughput) error {
+	if aws.StringValue(billingMode) == dynamodb.BillingModePayPerRequest {
+		if pt != nil {
+			return validationErr("One or more parameter values were invalid: Neither ReadCapacityUnits nor WriteCapacityUnits can be specified when BillingMode is PAY_PER_REQUEST")
+		}
+		return nil
+	}
+	if pt == nil {
+		return validationErr("One or more parameter values were invalid: ReadCapacityUnits and WriteCapacityUnits must both be specified when BillingMode is PROVISIONED")
+	}
+	if aws.Int64Value(pt.ReadCapacityUnits) < 1 || aws.Int64Value(pt.WriteCapacityUnits) < 1 {
+		return validationErr("One or more parameter values were invalid: Provisioned throughput must be at least 1 capacity unit")
+	}
+	return nil
+}
+
+// throughputDescription describes provisioned throughput, or returns nil if pt is nil.
+func throughputDescription(pt *dynamodb.ProvisionedThroughput) *dynamodb.ProvisionedThroughputDescription {
+	if pt == nil {
+		return nil
+	}
+	return &dynamodb.ProvisionedThroughputDescription{
+		ReadCapacityUnits:  pt.ReadCapacityUnits,
+		WriteCapacityUnits: pt.WriteCapacityUnits,
+	}
+}
+
+// DeleteTable deletes a table and all of its items.
+func (db *DB) DeleteTable(input *dynamodb.DeleteTableInput) (*dynamodb.DeleteTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("DeleteTable"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if aws.BoolValue(t.desc.DeletionProtectionEnabled) {
+		return nil, validationErr("Resource cannot be deleted as it is currently protected against deletion. Disable deletion protection first.")
+	}
+	desc := t.describe()
+	desc.TableStatus = aws.String(dynamodb.TableStatusDeleting)
+	delete(db.tables, t.name())
+	if db.StatusDelay > 0 {
+		db.deleting[t.name()] = deletedTable{desc: desc, at: db.Now()}
+	}
+	return &dynamodb.DeleteTableOutput{TableDescription: desc}, nil
+}
+
+// DescribeTable returns the description of a table.
+func (db *DB) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("DescribeTable"); err != nil {
+		return nil, err
+	}
+
+	if db.isDeleting(aws.StringValue(input.TableName)) {
+		desc := *db.deleting[aws.StringValue(input.TableName)].desc
+		return &dynamodb.DescribeTableOutput{Table: &desc}, nil
+	}
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.DescribeTableOutput{Table: db.describe(t)}, nil
+}
+
+// deletedTable is a table that is still being deleted.
+type deletedTable struct {
+	desc *dynamodb.TableDescription
+	at   time.Time
+}
+
+// describe returns the description of t, which is CREATING until StatusDelay
+// has passed since it was created. db.mu must be held.
+func (db *DB) describe(t *table) *dynamodb.TableDescription {
+	desc := t.describe()
+	if db.Now().Before(aws.TimeValue(desc.CreationDateTime).Add(db.StatusDelay)) {
+		desc.TableStatus = aws.String(dynamodb.TableStatusCreating)
+		gsis := []*dynamodb.GlobalSecondaryIndexDescription{}
+		for _, gsi := range desc.GlobalSecondaryIndexes {
+			g := *gsi
+			g.IndexStatus = aws.String(dynamodb.IndexStatusCreating)
+			gsis = append(gsis, &g)
+		}
+		desc.GlobalSecondaryIndexes = gsis
+	}
+	return desc
+}
+
+// isDeleting reports whether the named table is still being deleted. db.mu must be held.
+func (db *DB) isDeleting(name string) bool {
+	dt, ok := db.deleting[name]
+	if !ok {
+		return false
+	}
+	if !db.Now().Before(dt.at.Add(db.StatusDelay)) {
+		delete(db.deleting, name)
+		return false
+	}
+	return true
+}
+
+// ListTables lists table names in alphabetical order.
+func (db *DB) ListTables(input *dynamodb.ListTablesInput) (*dynamodb.ListTablesOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("ListTables"); err != nil {
+		return nil, err
+	}
+
+	names := sortedNames(db.tables)
+	start := aws.StringValue(input.ExclusiveStartTableName)
+	if start != "" {
+		names = names[sort.SearchStrings(names, start):]
+		if len(names) > 0 && names[0] == start {
+			names = names[1:]
+		}
+	}
+	limit := 100
+	if input.Limit != nil {
+		limit = int(*input.Limit)
+	}
+	out := &dynamodb.ListTablesOutput{TableNames: []*string{}}
+	for i, n := range names {
+		if i == limit {
+			out.LastEvaluatedTableName = out.TableNames[i-1]
+			break
+		}
+		out.TableNames = append(out.TableNames, aws.String(n))
+	}
+	return out, nil
+}
+
+// CreateTableWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) CreateTableWithContext(ctx aws.Context, input *dynamodb.CreateTableInput, _ ...request.Option) (*dynamodb.CreateTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.CreateTable(input)
+}
+
+// UpdateTableWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) UpdateTableWithContext(ctx aws.Context, input *dynamodb.UpdateTableInput, _ ...request.Option) (*dynamodb.UpdateTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.UpdateTable(input)
+}
+
+// ListTagsOfResourceWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) ListTagsOfResourceWithContext(ctx aws.Context, input *dynamodb.ListTagsOfResourceInput, _ ...request.Option) (*dynamodb.ListTagsOfResourceOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.ListTagsOfResource(input)
+}
+
+// DeleteTableWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) DeleteTableWithContext(ctx aws.Context, input *dynamodb.DeleteTableInput, _ ...request.Option) (*dynamodb.DeleteTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.DeleteTable(input)
+}
+
+// DescribeTableWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.DescribeTable(input)
+}
+
+// ListTablesWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) ListTablesWithContext(ctx aws.Context, input *dynamodb.ListTablesInput, _ ...request.Option) (*dynamodb.ListTablesOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.ListTables(input)
+}
+
+// canceledErr wraps a context error the way the SDK does.
+func canceledErr(err error) error {
+	return awserr.New(request.CanceledErrorCode, "request context canceled", err)
+}
@@ -0,0 +1,408 @@
+package dynamotest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+const TableName = "go-dynamo-test"
+
+var testTable = &dynamo.Table{
+	TableName:      TableName,
+	PrimaryKeyName: "partition",
+	PrimaryKeyType: "S",
+	SortKeyName:    "uuid",
+	SortKeyType:    "S",
+}
+
+type record struct {
+	Partition string         `json:"partition"`
+	UUID      string         `json:"uuid"`
+	Count     int            `json:"count"`
+	CountMap  map[string]int `json:"count-map"`
+	Price     float32        `json:"price"`
+	Tags      []string       `json:"tags,omitempty"`
+}
+
+var _ dynamo.DynamoDbLogic = (*dynamo.DynamoDB)(nil)
+
+func seed(t *testing.T, svc *dynamo.DynamoDB) {
+	t.Helper()
+	records := []record{
+		{Partition: "A", UUID: "001", Count: 3, Price: 19.95},
+		{Partition: "A", UUID: "002", Count: 5, Price: 9.95},
+		{Partition: "A", UUID: "003", Count: 7, Price: 4.5},
+		{Partition: "B", UUID: "004", Count: 10, Price: 10.00, CountMap: map[string]int{"M": 6, "XL": 1}},
+		{Partition: "C", UUID: "005", Count: 0, Price: 0.00},
+	}
+	for _, r := range records {
+		if err := svc.CreateItem(r, TableName); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+}
+
+func TestCreateAndGetItem(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	var tests = []struct {
+		pk, sk    string
+		wantUUID  string
+		wantCount int
+	}{
+		{pk: "A", sk: "001", wantUUID: "001", wantCount: 3},
+		{pk: "B", sk: "004", wantUUID: "004", wantCount: 10},
+		{pk: "A", sk: "999", wantUUID: "", wantCount: 0}, // not found
+	}
+	for _, test := range tests {
+		item, err := svc.GetItem(dynamo.CreateNewQueryObj(test.pk, test.sk), TableName, &record{}, dynamo.NewExpression())
+		if err != nil {
+			t.Errorf("FAIL: %v", err)
+			continue
+		}
+		r := item.(*record)
+		if r.UUID != test.wantUUID || r.Count != test.wantCount {
+			t.Errorf("FAIL - DATA: %+v; want: %s, %d", r, test.wantUUID, test.wantCount)
+		}
+	}
+}
+
+func TestGetItemWithProjection(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	eb := dynamo.NewExprBuilder()
+	eb.SetProjection([]string{"count", "count-map.M"})
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	item, err := svc.GetItem(dynamo.CreateNewQueryObj("B", "004"), TableName, &record{}, expr)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	r := item.(*record)
+	if r.Count != 10 || r.UUID != "" || len(r.CountMap) != 1 || r.CountMap["M"] != 6 {
+		t.Errorf("FAIL - DATA: %+v", r)
+	}
+}
+
+func TestTableNotFound(t *testing.T) {
+	svc, db := New(testTable)
+
+	err := svc.CreateItem(record{Partition: "A", UUID: "001"}, "missing")
+	var tnf *dynamo.TableNotFoundErr
+	if !errors.As(err, &tnf) {
+		t.Errorf("FAIL: %v; want: TableNotFoundErr", err)
+	}
+
+	// tables known to the client but not the database
+	other := &dynamo.Table{TableName: "other", PrimaryKeyName: "id", PrimaryKeyType: "S"}
+	svc = dynamo.NewDynamoDBWithClient(db, []*dynamo.Table{other}, nil)
+	err = svc.DeleteItem(dynamo.CreateNewQueryObj("x", nil), "other")
+	if !errors.Is(err, dynamo.ErrResourceNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrResourceNotFound)
+	}
+}
+
+func TestUpdateWithCondition(t *testing.T) {
+	var tests = []struct {
+		pk, sk    string
+		field     string
+		wantCount int
+		wantErr   bool
+	}{
+		{pk: "B", sk: "004", field: "count-map.M", wantCount: 4},
+		{pk: "B", sk: "004", field: "count-map.M", wantCount: 2},
+		{pk: "B", sk: "004", field: "count-map.M", wantCount: 0},
+		{pk: "B", sk: "004", field: "count-map.M", wantCount: 0, wantErr: true}, // condition fail
+		{pk: "A", sk: "001", field: "count", wantCount: 1},
+	}
+
+	svc, _ := New(testTable)
+	seed(t, svc)
+	for _, test := range tests {
+		cond := dynamo.NewCondition()
+		cond.GreaterThanEqual(test.field, 2)
+		ud := dynamo.NewUpdateExpr()
+		ud.SetMinus(test.field, test.field, 2, true)
+		eb := dynamo.NewExprBuilder()
+		eb.SetCondition(cond)
+		eb.SetUpdate(ud)
+		expr, err := eb.BuildExpression()
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+
+		err = svc.UpdateItem(dynamo.CreateNewQueryObj(test.pk, test.sk), TableName, expr)
+		var ccf *dynamo.ConditionCheckFailedErr
+		if errors.As(err, &ccf) != test.wantErr {
+			t.Errorf("FAIL: %v; want condition check failure: %v", err, test.wantErr)
+		}
+
+		item, err := svc.GetItem(dynamo.CreateNewQueryObj(test.pk, test.sk), TableName, &record{}, dynamo.NewExpression())
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		r := item.(*record)
+		got := r.Count
+		if test.pk == "B" {
+			got = r.CountMap["M"]
+		}
+		if got != test.wantCount {
+			t.Errorf("FAIL - DATA: %d; want: %d", got, test.wantCount)
+		}
+	}
+}
+
+func TestDeleteItem(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	if err := svc.DeleteItem(dynamo.CreateNewQueryObj("A", "002"), TableName); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if n := len(db.Items(TableName)); n != 4 {
+		t.Errorf("FAIL: %d items; want: 4", n)
+	}
+	// key missing the sort key
+	if err := svc.DeleteItem(dynamo.CreateNewQueryObj("A", nil), TableName); err == nil {
+		t.Errorf("FAIL: invalid key accepted")
+	}
+}
+
+func TestBatchGetOrder(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+	// force the client to retry unprocessed keys
+	db.MaxBatchGetProcessed = 2
+
+	eb := dynamo.NewExprBuilder()
+	eb.SetProjection([]string{"count"})
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	queries := []*dynamo.Query{
+		dynamo.CreateNewQueryObj("C", "005"),
+		dynamo.CreateNewQueryObj("B", "004"),
+		dynamo.CreateNewQueryObj("A", "999"), // not found
+		dynamo.CreateNewQueryObj("A", "002"),
+		nil,
+		dynamo.CreateNewQueryObj("B", "004"),
+		dynamo.CreateNewQueryObj("A", "001"),
+	}
+	refs := []interface{}{}
+	for range queries {
+		refs = append(refs, &record{})
+	}
+	got, err := svc.BatchGet(TableName, &dynamo.FailConfig{Base: 1, Cap: 1000}, queries, refs, expr)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	want := []int{0, 10, -1, 5, -1, 10, 3} // -1: not found
+	if len(got) != len(want) {
+		t.Fatalf("FAIL - DATA: %d items; want: %d", len(got), len(want))
+	}
+	for i, count := range want {
+		if count < 0 {
+			if got[i] != nil {
+				t.Errorf("FAIL - DATA: %d: %+v; want: nil", i, got[i])
+			}
+			continue
+		}
+		r, ok := got[i].(*record)
+		if !ok || r.Count != count || r.Partition != "" {
+			t.Errorf("FAIL - DATA: %d: %+v; want count: %d", i, got[i], count)
+		}
+	}
+}
+
+func TestBatchLimits(t *testing.T) {
+	svc, db := New(testTable)
+
+	items, queries, refs := []interface{}{}, []*dynamo.Query{}, []interface{}{}
+	for i := 0; i < 26; i++ {
+		items = append(items, record{Partition: "A", UUID: fmt.Sprintf("%03d", i)})
+	}
+	if err := svc.BatchWriteCreate(TableName, &dynamo.FailConfig{}, items); !errors.Is(err, dynamo.ErrCollectionSizeExceeded) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrCollectionSizeExceeded)
+	}
+	if err := svc.BatchWriteCreate(TableName, &dynamo.FailConfig{}, items[:25]); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for i := 0; i < 25; i++ {
+		queries = append(queries, dynamo.CreateNewQueryObj("A", fmt.Sprintf("%03d", i)))
+		refs = append(refs, &record{})
+	}
+	got, err := svc.BatchGet(TableName, &dynamo.FailConfig{}, queries, refs, dynamo.NewExpression())
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(got) != 25 {
+		t.Errorf("FAIL: %d items; want: 25", len(got))
+	}
+	if err := svc.BatchWriteDelete(TableName, &dynamo.FailConfig{}, queries[:10]); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if n := len(db.Items(TableName)); n != 15 {
+		t.Errorf("FAIL: %d items; want: 15", n)
+	}
+
+	// the database enforces the limits independently of the client
+	wrs := []*dynamodb.WriteRequest{}
+	for i := 0; i < 26; i++ {
+		wrs = append(wrs, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: map[string]*dynamodb.AttributeValue{
+			"partition": {S: aws.String("Z")},
+			"uuid":      {S: aws.String(fmt.Sprint(i))},
+		}}})
+	}
+	if _, err := db.BatchWriteItem(&dynamodb.BatchWriteItemInput{RequestItems: map[string][]*dynamodb.WriteRequest{TableName: wrs}}); err == nil {
+		t.Errorf("FAIL: 26 write requests accepted")
+	}
+	keys := []map[string]*dynamodb.AttributeValue{}
+	for i := 0; i < 101; i++ {
+		keys = append(keys, map[string]*dynamodb.AttributeValue{
+			"partition": {S: aws.String("Z")},
+			"uuid":      {S: aws.String(fmt.Sprint(i))},
+		})
+	}
+	if _, err := db.BatchGetItem(&dynamodb.BatchGetItemInput{RequestItems: map[string]*dynamodb.KeysAndAttributes{TableName: {Keys: keys}}}); err == nil {
+		t.Errorf("FAIL: 101 keys accepted")
+	}
+}
+
+func TestScanItems(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	eb := dynamo.NewExprBuilder()
+	cond := dynamo.NewCondition()
+	cond.GreaterThan("count", 4)
+	eb.Filter = &cond.Condition
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	var startKey any
+	got := 0
+	for page := 0; ; page++ {
+		res, err := svc.ScanItems(TableName, &record{}, startKey, expr, aws.Int64(2))
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		got += len(res.Results)
+		if res.LastKey == nil {
+			break
+		}
+		startKey = lastKey(res.LastKey)
+		if page > 5 {
+			t.Fatalf("FAIL: scan did not terminate")
+		}
+	}
+	if got != 3 {
+		t.Errorf("FAIL: %d items; want: 3", got)
+	}
+}
+
+func TestQueryItems(t *testing.T) {
+	var tests = []struct {
+		pk     string
+		after  string
+		perPg  *int64
+		want   int
+		wantLK bool
+	}{
+		{pk: "A", want: 3},
+		{pk: "A", after: "001", want: 2},
+		{pk: "A", perPg: aws.Int64(2), want: 2, wantLK: true},
+		{pk: "Z", want: 0},
+	}
+
+	svc, _ := New(testTable)
+	seed(t, svc)
+	for _, test := range tests {
+		kc := dynamo.NewKeyCondition()
+		kc.Equal("partition", test.pk)
+		if test.after != "" {
+			kc.GreaterThan("uuid", test.after)
+		}
+		eb := dynamo.NewExprBuilder()
+		eb.SetKeyCondition(kc)
+		expr, err := eb.BuildExpression()
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		res, err := svc.QueryItems(TableName, &record{}, nil, expr, test.perPg)
+		if err != nil {
+			t.Errorf("FAIL: %v", err)
+			continue
+		}
+		if len(res.Results) != test.want || (res.LastKey != nil) != test.wantLK {
+			t.Errorf("FAIL - DATA: %d items, last key %v; want: %d, %v", len(res.Results), res.LastKey, test.want, test.wantLK)
+		}
+	}
+}
+
+func TestQueryItemsModel(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	kc := dynamo.NewKeyCondition()
+	kc.Equal("partition", "A")
+	eb := dynamo.NewExprBuilder()
+	eb.SetKeyCondition(kc)
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	// each item is unmarshalled into its own value of the model's type
+	model := &record{}
+	res, err := svc.QueryItems(TableName, model, nil, expr, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	want := []string{"001", "002", "003"}
+	if len(res.Results) != len(want) {
+		t.Fatalf("FAIL - DATA: %d items; want: %d", len(res.Results), len(want))
+	}
+	for i, r := range res.Results {
+		rec, ok := r.(*record)
+		if !ok || rec == model || rec.UUID != want[i] {
+			t.Errorf("FAIL - DATA: result %d: %#v; want: new *record %s", i, r, want[i])
+		}
+	}
+	if model.UUID != "" {
+		t.Errorf("FAIL - DATA: model modified: %+v", model)
+	}
+
+	scan, err := svc.ScanIndex(TableName, "", record{}, nil, dynamo.NewExpression(), nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range scan.Results {
+		rec, ok := r.(record)
+		if !ok || seen[rec.UUID] {
+			t.Errorf("FAIL - DATA: %#v; want: distinct record values", r)
+		}
+		seen[rec.UUID] = true
+	}
+}
+
+// lastKey converts a LastEvaluatedKey into a value accepted as a start key.
+func lastKey(av map[string]*dynamodb.AttributeValue) map[string]string {
+	m := make(map[string]string)
+	for k, v := range av {
+		m[k] = aws.StringValue(v.S)
+	}
+	return m
+}
@@ -0,0 +1,1106 @@
+package dynamotest
+
+// This file contains a parser and evaluator for DynamoDB condition,
+// key condition, filter, projection and update expressions.
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+/* lexer */
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokName   // #name placeholder
+	tokValue  // :value placeholder
+	tokNumber // list index
+	tokPunct
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func lex(s string) ([]token, error) {
+	toks := []token{}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || c == ':':
+			j := i + 1
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				return nil, fmt.Errorf("invalid placeholder at position %d", i)
+			}
+			kind := tokName
+			if c == ':' {
+				kind = tokValue
+			}
+			toks = append(toks, token{kind, s[i:j]})
+			i = j
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			toks = append(toks, token{tokNumber, s[i:j]})
+			i = j
+		case isIdentChar(c):
+			j := i
+			for j < len(s) && isIdentChar(s[j]) {
+				j++
+			}
+			toks = append(toks, token{tokIdent, s[i:j]})
+			i = j
+		case c == '<' || c == '>':
+			if i+1 < len(s) && (s[i+1] == '=' || (c == '<' && s[i+1] == '>')) {
+				toks = append(toks, token{tokPunct, s[i : i+2]})
+				i += 2
+				continue
+			}
+			toks = append(toks, token{tokPunct, s[i : i+1]})
+			i++
+		case strings.IndexByte("()[],.=+-", c) >= 0:
+			toks = append(toks, token{tokPunct, s[i : i+1]})
+			i++
+		default:
+			return nil, fmt.Errorf("invalid character %q at position %d", c, i)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+/* AST */
+
+// pathElem is a single element of a document path.
+type pathElem struct {
+	name  string
+	index int
+	isIdx bool
+}
+
+type docPath []pathElem
+
+func (p docPath) String() string {
+	var sb strings.Builder
+	for i, e := range p {
+		if e.isIdx {
+			fmt.Fprintf(&sb, "[%d]", e.index)
+			continue
+		}
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(e.name)
+	}
+	return sb.String()
+}
+
+// operand is a value producing node.
+type operand interface {
+	eval(it item) (*dynamodb.AttributeValue, error)
+}
+
+type pathOperand struct{ path docPath }
+
+func (o pathOperand) eval(it item) (*dynamodb.AttributeValue, error) {
+	return getPath(it, o.path), nil
+}
+
+type valueOperand struct{ av *dynamodb.AttributeValue }
+
+func (o valueOperand) eval(item) (*dynamodb.AttributeValue, error) { return o.av, nil }
+
+type sizeOperand struct{ path docPath }
+
+func (o sizeOperand) eval(it item) (*dynamodb.AttributeValue, error) {
+	n, ok := itemSize(getPath(it, o.path))
+	if !ok {
+		return nil, nil
+	}
+	return &dynamodb.AttributeValue{N: aws.String(strconv.Itoa(n))}, nil
+}
+
+type arithOperand struct {
+	op          string
+	left, right operand
+}
+
+func (o arithOperand) eval(it item) (*dynamodb.AttributeValue, error) {
+	l, err := o.left.eval(it)
+	if err != nil {
+		return nil, err
+	}
+	r, err := o.right.eval(it)
+	if err != nil {
+		return nil, err
+	}
+	if avType(l) != dynamodb.ScalarAttributeTypeN || avType(r) != dynamodb.ScalarAttributeTypeN {
+		return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+	ln, err := parseNumber(*l.N)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := parseNumber(*r.N)
+	if err != nil {
+		return nil, err
+	}
+	res := new(big.Rat)
+	if o.op == "+" {
+		res.Add(ln, rn)
+	} else {
+		res.Sub(ln, rn)
+	}
+	return &dynamodb.AttributeValue{N: aws.String(formatNumber(res))}, nil
+}
+
+type ifNotExistsOperand struct {
+	path docPath
+	def  operand
+}
+
+func (o ifNotExistsOperand) eval(it item) (*dynamodb.AttributeValue, error) {
+	if v := getPath(it, o.path); v != nil {
+		return v, nil
+	}
+	return o.def.eval(it)
+}
+
+type listAppendOperand struct{ left, right operand }
+
+func (o listAppendOperand) eval(it item) (*dynamodb.AttributeValue, error) {
+	l, err := o.left.eval(it)
+	if err != nil {
+		return nil, err
+	}
+	r, err := o.right.eval(it)
+	if err != nil {
+		return nil, err
+	}
+	if avType(l) != "L" || avType(r) != "L" {
+		return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+	out := make([]*dynamodb.AttributeValue, 0, len(l.L)+len(r.L))
+	out = append(out, l.L...)
+	out = append(out, r.L...)
+	return &dynamodb.AttributeValue{L: out}, nil
+}
+
+// condition is a boolean node.
+type condition interface {
+	eval(it item) (bool, error)
+}
+
+type andCond struct{ left, right condition }
+
+func (c andCond) eval(it item) (bool, error) {
+	l, err := c.left.eval(it)
+	if err != nil || !l {
+		return false, err
+	}
+	return c.right.eval(it)
+}
+
+type orCond struct{ left, right condition }
+
+func (c orCond) eval(it item) (bool, error) {
+	l, err := c.left.eval(it)
+	if err != nil || l {
+		return l, err
+	}
+	return c.right.eval(it)
+}
+
+type notCond struct{ cond condition }
+
+func (c notCond) eval(it item) (bool, error) {
+	v, err := c.cond.eval(it)
+	return !v, err
+}
+
+type compareCond struct {
+	op          string
+	left, right operand
+}
+
+func (c compareCond) eval(it item) (bool, error) {
+	l, err := c.left.eval(it)
+	if err != nil {
+		return false, err
+	}
+	r, err := c.right.eval(it)
+	if err != nil {
+		return false, err
+	}
+	switch c.op {
+	case "=":
+		return equalAV(l, r), nil
+	case "<>":
+		return !equalAV(l, r), nil
+	}
+	cmp, ok := compareAV(l, r)
+	if !ok {
+		return false, nil
+	}
+	switch c.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	default:
+		return cmp >= 0, nil
+	}
+}
+
+type betweenCond struct{ value, lower, upper operand }
+
+func (c betweenCond) eval(it item) (bool, error) {
+	ge, err := compareCond{">=", c.value, c.lower}.eval(it)
+	if err != nil || !ge {
+		return false, err
+	}
+	return compareCond{"<=", c.value, c.upper}.eval(it)
+}
+
+type inCond struct {
+	value   operand
+	options []operand
+}
+
+func (c inCond) eval(it item) (bool, error) {
+	v, err := c.value.eval(it)
+	if err != nil {
+		return false, err
+	}
+	for _, o := range c.options {
+		ov, err := o.eval(it)
+		if err != nil {
+			return false, err
+		}
+		if equalAV(v, ov) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type funcCond struct {
+	name string
+	path docPath
+	arg  operand
+}
+
+func (c funcCond) eval(it item) (bool, error) {
+	v := getPath(it, c.path)
+	switch c.name {
+	case "attribute_exists":
+		return v != nil, nil
+	case "attribute_not_exists":
+		return v == nil, nil
+	}
+	arg, err := c.arg.eval(it)
+	if err != nil {
+		return false, err
+	}
+	if v == nil || arg == nil {
+		return false, nil
+	}
+	switch c.name {
+	case "attribute_type":
+		if arg.S == nil {
+			return false, fmt.Errorf("invalid attribute type argument")
+		}
+		return avType(v) == *arg.S, nil
+	case "begins_with":
+		switch {
+		case v.S != nil && arg.S != nil:
+			return strings.HasPrefix(*v.S, *arg.S), nil
+		case v.B != nil && arg.B != nil:
+			return bytes.HasPrefix(v.B, arg.B), nil
+		}
+		return false, nil
+	case "contains":
+		switch avType(v) {
+		case dynamodb.ScalarAttributeTypeS:
+			return arg.S != nil && strings.Contains(*v.S, *arg.S), nil
+		case dynamodb.ScalarAttributeTypeB:
+			return arg.B != nil && bytes.Contains(v.B, arg.B), nil
+		case "SS", "NS", "BS":
+			if avType(v) != avType(arg)+"S" {
+				return false, nil
+			}
+			return setKeys(v)[encodeKeyPart(arg)[2:]], nil
+		case "L":
+			for _, e := range v.L {
+				if equalAV(e, arg) {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown function: %s", c.name)
+}
+
+// updateAction is a single action of an update expression.
+type updateAction struct {
+	kind  string // SET, REMOVE, ADD, DELETE
+	path  docPath
+	value operand
+}
+
+/* parser */
+
+// exprAttrs resolves placeholders and tracks which ones were used.
+type exprAttrs struct {
+	names      map[string]*string
+	values     map[string]*dynamodb.AttributeValue
+	usedNames  map[string]bool
+	usedValues map[string]bool
+}
+
+func newExprAttrs(names map[string]*string, values map[string]*dynamodb.AttributeValue) *exprAttrs {
+	return &exprAttrs{
+		names:      names,
+		values:     values,
+		usedNames:  make(map[string]bool),
+		usedValues: make(map[string]bool),
+	}
+}
+
+// checkUnused returns an error if a placeholder was provided but never used.
+func (a *exprAttrs) checkUnused() error {
+	for _, n := range sortedNames(a.names) {
+		if !a.usedNames[n] {
+			return fmt.Errorf("Value provided in ExpressionAttributeNames unused in expressions: keys: {%s}", n)
+		}
+	}
+	for _, n := range sortedNames(a.values) {
+		if !a.usedValues[n] {
+			return fmt.Errorf("Value provided in ExpressionAttributeValues unused in expressions: keys: {%s}", n)
+		}
+	}
+	return nil
+}
+
+type parser struct {
+	toks  []token
+	pos   int
+	attrs *exprAttrs
+}
+
+func newParser(expr string, attrs *exprAttrs) (*parser, error) {
+	toks, err := lex(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &parser{toks: toks, attrs: attrs}, nil
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(s string) bool {
+	t := p.peek()
+	return t.kind == tokPunct && t.text == s
+}
+
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && strings.EqualFold(t.text, kw)
+}
+
+func (p *parser) expect(s string) error {
+	t := p.next()
+	if t.kind != tokPunct || t.text != s {
+		return fmt.Errorf("syntax error; expected %q, got %q", s, t.text)
+	}
+	return nil
+}
+
+func (p *parser) expectEOF() error {
+	if t := p.peek(); t.kind != tokEOF {
+		return fmt.Errorf("syntax error; unexpected token %q", t.text)
+	}
+	return nil
+}
+
+// parseConditionExpr parses a condition, filter or key condition expression.
+func parseConditionExpr(expr string, attrs *exprAttrs) (condition, error) {
+	p, err := newParser(expr, attrs)
+	if err != nil {
+		return nil, err
+	}
+	c, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	return c, p.expectEOF()
+}
+
+func (p *parser) parseOr() (condition, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (condition, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andCond{left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (condition, error) {
+	if p.isKeyword("NOT") {
+		p.next()
+		c, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notCond{c}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (condition, error) {
+	if p.isPunct("(") {
+		p.next()
+		c, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return c, p.expect(")")
+	}
+
+	if t := p.peek(); t.kind == tokIdent && p.toks[p.pos+1].kind == tokPunct && p.toks[p.pos+1].text == "(" {
+		switch name := strings.ToLower(t.text); name {
+		case "attribute_exists", "attribute_not_exists":
+			p.next()
+			p.next()
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			return funcCond{name: name, path: path}, p.expect(")")
+		case "attribute_type", "begins_with", "contains":
+			p.next()
+			p.next()
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			arg, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return funcCond{name: name, path: path, arg: arg}, p.expect(")")
+		}
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isKeyword("BETWEEN"):
+		p.next()
+		lower, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("AND") {
+			return nil, fmt.Errorf("syntax error; expected AND in BETWEEN")
+		}
+		p.next()
+		upper, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenCond{left, lower, upper}, nil
+	case p.isKeyword("IN"):
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		c := inCond{value: left}
+		for {
+			o, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			c.options = append(c.options, o)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+		return c, p.expect(")")
+	}
+
+	t := p.next()
+	switch t.text {
+	case "=", "<>", "<", "<=", ">", ">=":
+		if t.kind != tokPunct {
+			break
+		}
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareCond{t.text, left, right}, nil
+	}
+	return nil, fmt.Errorf("syntax error; unexpected token %q", t.text)
+}
+
+// parseOperand parses a path, value placeholder or size function.
+func (p *parser) parseOperand() (operand, error) {
+	t := p.peek()
+	if t.kind == tokValue {
+		p.next()
+		av, ok := p.attrs.values[t.text]
+		if !ok {
+			return nil, fmt.Errorf("An expression attribute value used in expression is not defined; attribute value: %s", t.text)
+		}
+		p.attrs.usedValues[t.text] = true
+		return valueOperand{av}, nil
+	}
+	if t.kind == tokIdent && strings.EqualFold(t.text, "size") && p.toks[p.pos+1].text == "(" {
+		p.next()
+		p.next()
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return sizeOperand{path}, p.expect(")")
+	}
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return pathOperand{path}, nil
+}
+
+func (p *parser) parsePathElem() (pathElem, error) {
+	t := p.next()
+	switch t.kind {
+	case tokIdent:
+		return pathElem{name: t.text}, nil
+	case tokName:
+		n, ok := p.attrs.names[t.text]
+		if !ok {
+			return pathElem{}, fmt.Errorf("An expression attribute name used in the document path is not defined; attribute name: %s", t.text)
+		}
+		p.attrs.usedNames[t.text] = true
+		return pathElem{name: aws.StringValue(n)}, nil
+	}
+	return pathElem{}, fmt.Errorf("syntax error; expected attribute name, got %q", t.text)
+}
+
+// parsePath parses a document path such as #a.b[1].#c.
+func (p *parser) parsePath() (docPath, error) {
+	e, err := p.parsePathElem()
+	if err != nil {
+		return nil, err
+	}
+	path := docPath{e}
+	for {
+		switch {
+		case p.isPunct("."):
+			p.next()
+			e, err := p.parsePathElem()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, e)
+		case p.isPunct("["):
+			p.next()
+			t := p.next()
+			if t.kind != tokNumber {
+				return nil, fmt.Errorf("syntax error; expected list index, got %q", t.text)
+			}
+			idx, err := strconv.Atoi(t.text)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			path = append(path, pathElem{index: idx, isIdx: true})
+		default:
+			return path, nil
+		}
+	}
+}
+
+// parseProjectionExpr parses a comma separated list of document paths.
+func parseProjectionExpr(expr string, attrs *exprAttrs) ([]docPath, error) {
+	p, err := newParser(expr, attrs)
+	if err != nil {
+		return nil, err
+	}
+	paths := []docPath{}
+	for {
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		for _, prev := range paths {
+			if prev.overlaps(path) {
+				return nil, fmt.Errorf("Two document paths overlap with each other; must remove or rewrite one of these paths; path one: [%s], path two: [%s]", prev, path)
+			}
+		}
+		paths = append(paths, path)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return paths, p.expectEOF()
+}
+
+// overlaps reports whether one of p and q is a prefix of the other.
+func (p docPath) overlaps(q docPath) bool {
+	n := min(len(p), len(q))
+	for i := 0; i < n; i++ {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseUpdateExpr parses an update expression into a list of actions.
+func parseUpdateExpr(expr string, attrs *exprAttrs) ([]updateAction, error) {
+	p, err := newParser(expr, attrs)
+	if err != nil {
+		return nil, err
+	}
+	actions := []updateAction{}
+	seen := make(map[string]bool)
+	for p.peek().kind != tokEOF {
+		t := p.next()
+		kind := strings.ToUpper(t.text)
+		if t.kind != tokIdent || (kind != "SET" && kind != "REMOVE" && kind != "ADD" && kind != "DELETE") {
+			return nil, fmt.Errorf("syntax error; unexpected token %q", t.text)
+		}
+		if seen[kind] {
+			return nil, fmt.Errorf("The %s section can only be used once in an update expression", kind)
+		}
+		seen[kind] = true
+
+		for {
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			a := updateAction{kind: kind, path: path}
+			switch kind {
+			case "SET":
+				if err := p.expect("="); err != nil {
+					return nil, err
+				}
+				if a.value, err = p.parseSetValue(); err != nil {
+					return nil, err
+				}
+			case "ADD", "DELETE":
+				if p.peek().kind != tokValue {
+					return nil, fmt.Errorf("syntax error; %s requires a value placeholder", kind)
+				}
+				if a.value, err = p.parseOperand(); err != nil {
+					return nil, err
+				}
+			}
+			actions = append(actions, a)
+			if !p.isPunct(",") {
+				break
+			}
+			p.next()
+		}
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("update expression is empty")
+	}
+	return actions, nil
+}
+
+func (p *parser) parseSetValue() (operand, error) {
+	left, err := p.parseSetTerm()
+	if err != nil {
+		return nil, err
+	}
+	if p.isPunct("+") || p.isPunct("-") {
+		op := p.next().text
+		right, err := p.parseSetTerm()
+		if err != nil {
+			return nil, err
+		}
+		return arithOperand{op, left, right}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseSetTerm() (operand, error) {
+	t := p.peek()
+	if t.kind == tokIdent && p.toks[p.pos+1].text == "(" {
+		switch strings.ToLower(t.text) {
+		case "if_not_exists":
+			p.next()
+			p.next()
+			path, err := p.parsePath()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			def, err := p.parseSetTerm()
+			if err != nil {
+				return nil, err
+			}
+			return ifNotExistsOperand{path, def}, p.expect(")")
+		case "list_append":
+			p.next()
+			p.next()
+			left, err := p.parseSetTerm()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+			right, err := p.parseSetTerm()
+			if err != nil {
+				return nil, err
+			}
+			return listAppendOperand{left, right}, p.expect(")")
+		}
+	}
+	return p.parseOperand()
+}
+
+/* document paths */
+
+// getPath returns the value at path, or nil if it does not exist.
+func getPath(it item, path docPath) *dynamodb.AttributeValue {
+	if len(path) == 0 || path[0].isIdx {
+		return nil
+	}
+	v := it[path[0].name]
+	for _, e := range path[1:] {
+		if v == nil {
+			return nil
+		}
+		if e.isIdx {
+			if v.L == nil || e.index >= len(v.L) {
+				return nil
+			}
+			v = v.L[e.index]
+			continue
+		}
+		if v.M == nil {
+			return nil
+		}
+		v = v.M[e.name]
+	}
+	return v
+}
+
+// setPath sets the value at path. The parent of the path must exist.
+func setPath(it item, path docPath, val *dynamodb.AttributeValue) error {
+	if len(path) == 1 {
+		it[path[0].name] = val
+		return nil
+	}
+	parent := getPath(it, path[:len(path)-1])
+	last := path[len(path)-1]
+	if parent == nil {
+		return fmt.Errorf("The document path provided in the update expression is invalid for update")
+	}
+	if last.isIdx {
+		if parent.L == nil {
+			return fmt.Errorf("The document path provided in the update expression is invalid for update")
+		}
+		if last.index >= len(parent.L) {
+			parent.L = append(parent.L, val)
+			return nil
+		}
+		parent.L[last.index] = val
+		return nil
+	}
+	if parent.M == nil {
+		return fmt.Errorf("The document path provided in the update expression is invalid for update")
+	}
+	parent.M[last.name] = val
+	return nil
+}
+
+// removePath removes the value at path if it exists.
+func removePath(it item, path docPath) {
+	if len(path) == 1 {
+		delete(it, path[0].name)
+		return
+	}
+	parent := getPath(it, path[:len(path)-1])
+	if parent == nil {
+		return
+	}
+	last := path[len(path)-1]
+	if last.isIdx {
+		if parent.L != nil && last.index < len(parent.L) {
+			parent.L = append(parent.L[:last.index], parent.L[last.index+1:]...)
+		}
+		return
+	}
+	if parent.M != nil {
+		delete(parent.M, last.name)
+	}
+}
+
+// project returns a new item containing only the given paths.
+func project(it item, paths []docPath) item {
+	out := make(item)
+	for _, path := range paths {
+		v := getPath(it, path)
+		if v == nil {
+			continue
+		}
+		projectPath(out, path, cloneAV(v))
+	}
+	return out
+}
+
+func projectPath(out item, path docPath, v *dynamodb.AttributeValue) {
+	if len(path) == 1 {
+		out[path[0].name] = v
+		return
+	}
+	// build the nested containers along the path
+	cur, ok := out[path[0].name]
+	if !ok {
+		cur = newContainer(path[1])
+		out[path[0].name] = cur
+	}
+	for i, e := range path[1:] {
+		last := i == len(path)-2
+		if e.isIdx {
+			if last {
+				cur.L = append(cur.L, v)
+				return
+			}
+			next := newContainer(path[i+2])
+			cur.L = append(cur.L, next)
+			cur = next
+			continue
+		}
+		if last {
+			cur.M[e.name] = v
+			return
+		}
+		next, ok := cur.M[e.name]
+		if !ok {
+			next = newContainer(path[i+2])
+			cur.M[e.name] = next
+		}
+		cur = next
+	}
+}
+
+func newContainer(child pathElem) *dynamodb.AttributeValue {
+	if child.isIdx {
+		return &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{}}
+	}
+	return &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{}}
+}
+
+/* update evaluation */
+
+// applyUpdate applies the update actions to a copy of old and returns the result
+// along with the paths that were modified.
+func applyUpdate(old item, actions []updateAction) (item, []docPath, error) {
+	// all operands are evaluated against the item as it was before the update
+	vals := make([]*dynamodb.AttributeValue, len(actions))
+	for i, a := range actions {
+		if a.value == nil {
+			continue
+		}
+		v, err := a.value.eval(old)
+		if err != nil {
+			return nil, nil, err
+		}
+		if v == nil && a.kind == "SET" {
+			return nil, nil, fmt.Errorf("The provided expression refers to an attribute that does not exist in the item")
+		}
+		vals[i] = cloneAV(v)
+	}
+
+	it := item(cloneItem(old))
+	if it == nil {
+		it = make(item)
+	}
+	paths := make([]docPath, 0, len(actions))
+	for i, a := range actions {
+		paths = append(paths, a.path)
+		switch a.kind {
+		case "SET":
+			if err := setPath(it, a.path, vals[i]); err != nil {
+				return nil, nil, err
+			}
+		case "REMOVE":
+			removePath(it, a.path)
+		case "ADD":
+			cur := getPath(it, a.path)
+			v, err := addValue(cur, vals[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := setPath(it, a.path, v); err != nil {
+				return nil, nil, err
+			}
+		case "DELETE":
+			cur := getPath(it, a.path)
+			if cur == nil {
+				continue
+			}
+			v, err := deleteValue(cur, vals[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			if v == nil {
+				removePath(it, a.path)
+				continue
+			}
+			if err := setPath(it, a.path, v); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return it, paths, nil
+}
+
+// addValue implements the ADD action for numbers and sets.
+func addValue(cur, v *dynamodb.AttributeValue) (*dynamodb.AttributeValue, error) {
+	switch avType(v) {
+	case dynamodb.ScalarAttributeTypeN:
+		if cur == nil {
+			return v, nil
+		}
+		return arithOperand{"+", valueOperand{cur}, valueOperand{v}}.eval(nil)
+	case "SS", "NS", "BS":
+		if cur == nil {
+			return v, nil
+		}
+		if avType(cur) != avType(v) {
+			return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+		}
+		out := cloneAV(cur)
+		have := setKeys(cur)
+		for _, m := range setMembers(v) {
+			if have[memberKey(m)] {
+				continue
+			}
+			appendMember(out, m)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("Incorrect operand type for operator or function; operator: ADD, operand type: %s", avType(v))
+}
+
+// deleteValue implements the DELETE action for sets. It returns nil if the set becomes empty.
+func deleteValue(cur, v *dynamodb.AttributeValue) (*dynamodb.AttributeValue, error) {
+	if avType(cur) != avType(v) || (avType(v) != "SS" && avType(v) != "NS" && avType(v) != "BS") {
+		return nil, fmt.Errorf("An operand in the update expression has an incorrect data type")
+	}
+	drop := setKeys(v)
+	out := &dynamodb.AttributeValue{}
+	for _, m := range setMembers(cur) {
+		if drop[memberKey(m)] {
+			continue
+		}
+		appendMember(out, m)
+	}
+	if avType(out) == "" {
+		return nil, nil
+	}
+	return out, nil
+}
+
+// setMembers returns the members of a set as individual scalar values.
+func setMembers(av *dynamodb.AttributeValue) []*dynamodb.AttributeValue {
+	out := []*dynamodb.AttributeValue{}
+	for _, s := range av.SS {
+		out = append(out, &dynamodb.AttributeValue{SS: []*string{s}})
+	}
+	for _, n := range av.NS {
+		out = append(out, &dynamodb.AttributeValue{NS: []*string{n}})
+	}
+	for _, b := range av.BS {
+		out = append(out, &dynamodb.AttributeValue{BS: [][]byte{b}})
+	}
+	return out
+}
+
+func memberKey(m *dynamodb.AttributeValue) string {
+	for k := range setKeys(m) {
+		return k
+	}
+	return ""
+}
+
+func appendMember(set, m *dynamodb.AttributeValue) {
+	set.SS = append(set.SS, m.SS...)
+	set.NS = append(set.NS, m.NS...)
+	set.BS = append(set.BS, m.BS...)
+}
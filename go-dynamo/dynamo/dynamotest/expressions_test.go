@@ -0,0 +1,181 @@
+package dynamotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+)
+
+var exprKey = map[string]*dynamodb.AttributeValue{
+	"partition": {S: aws.String("A")},
+	"uuid":      {S: aws.String("001")},
+}
+
+func exprDB(t *testing.T) *DB {
+	t.Helper()
+	db := NewDB(testTable)
+	item, err := dynamodbattribute.MarshalMap(map[string]interface{}{
+		"partition": "A",
+		"uuid":      "001",
+		"count":     3,
+		"name":      "widget",
+		"tags":      []string{"a", "b"},
+		"nested":    map[string]interface{}{"list": []int{1, 2, 3}, "flag": true},
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	item["colors"] = &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"red", "blue"})}
+	if _, err := db.PutItem(&dynamodb.PutItemInput{TableName: aws.String(TableName), Item: item}); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return db
+}
+
+func TestConditionExpressions(t *testing.T) {
+	var tests = []struct {
+		name string
+		cond expression.ConditionBuilder
+		want bool
+	}{
+		{"equal", expression.Name("count").Equal(expression.Value(3)), true},
+		{"not equal", expression.Name("count").NotEqual(expression.Value(3)), false},
+		{"less than", expression.Name("count").LessThan(expression.Value(10)), true},
+		{"string compare", expression.Name("name").GreaterThan(expression.Value("a")), true},
+		{"type mismatch", expression.Name("name").GreaterThan(expression.Value(1)), false},
+		{"between", expression.Name("count").Between(expression.Value(1), expression.Value(3)), true},
+		{"in", expression.Name("count").In(expression.Value(1), expression.Value(2)), false},
+		{"begins with", expression.Name("name").BeginsWith("wid"), true},
+		{"contains string", expression.Name("name").Contains("dge"), true},
+		{"contains set", expression.Name("colors").Contains("red"), true},
+		{"contains list", expression.Name("nested.list").Contains("4"), false},
+		{"exists", expression.Name("nested.flag").AttributeExists(), true},
+		{"not exists", expression.Name("missing").AttributeNotExists(), true},
+		{"type", expression.Name("colors").AttributeType(expression.StringSet), true},
+		{"size", expression.Name("nested.list").Size().Equal(expression.Value(3)), true},
+		{"and", expression.Name("count").Equal(expression.Value(3)).And(expression.Name("name").Equal(expression.Value("x"))), false},
+		{"or", expression.Name("count").Equal(expression.Value(3)).Or(expression.Name("name").Equal(expression.Value("x"))), true},
+		{"not", expression.Name("count").Equal(expression.Value(3)).Not(), false},
+	}
+
+	db := exprDB(t)
+	for _, test := range tests {
+		expr, err := expression.NewBuilder().WithCondition(test.cond).Build()
+		if err != nil {
+			t.Fatalf("FAIL: %s: %v", test.name, err)
+		}
+		_, err = db.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName:                 aws.String(TableName),
+			Key:                       exprKey,
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		var ccf *dynamodb.ConditionalCheckFailedException
+		switch {
+		case test.want && err != nil:
+			t.Errorf("FAIL: %s: %v", test.name, err)
+		case !test.want && !errors.As(err, &ccf):
+			t.Errorf("FAIL: %s: %v; want: ConditionalCheckFailedException", test.name, err)
+		}
+		if test.want {
+			db = exprDB(t)
+		}
+	}
+}
+
+func TestUpdateExpressions(t *testing.T) {
+	update := expression.Set(expression.Name("count"), expression.Name("count").Plus(expression.Value(2))).
+		Set(expression.Name("nested.list"), expression.ListAppend(expression.Name("nested.list"), expression.Value([]int{4}))).
+		Set(expression.Name("created"), expression.IfNotExists(expression.Name("created"), expression.Value("now"))).
+		Remove(expression.Name("name")).
+		Add(expression.Name("colors"), expression.Value(&dynamodb.AttributeValue{SS: aws.StringSlice([]string{"green"})})).
+		Delete(expression.Name("tags"), expression.Value(&dynamodb.AttributeValue{SS: aws.StringSlice([]string{"a"})}))
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	db := exprDB(t)
+	// tags is a list, not a set, so DELETE is rejected and nothing changes
+	_, err = db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(TableName),
+		Key:                       exprKey,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != errCodeValidation {
+		t.Fatalf("FAIL: %v; want: %s", err, errCodeValidation)
+	}
+
+	update = expression.Set(expression.Name("count"), expression.Name("count").Plus(expression.Value(2))).
+		Set(expression.Name("nested.list"), expression.ListAppend(expression.Name("nested.list"), expression.Value([]int{4}))).
+		Set(expression.Name("created"), expression.IfNotExists(expression.Name("created"), expression.Value("now"))).
+		Remove(expression.Name("name")).
+		Add(expression.Name("colors"), expression.Value(&dynamodb.AttributeValue{SS: aws.StringSlice([]string{"green"})}))
+	if expr, err = expression.NewBuilder().WithUpdate(update).Build(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	out, err := db.UpdateItem(&dynamodb.UpdateItemInput{
+		TableName:                 aws.String(TableName),
+		Key:                       exprKey,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	got := struct {
+		Count   int      `json:"count"`
+		Name    string   `json:"name"`
+		Created string   `json:"created"`
+		Colors  []string `json:"colors"`
+		Nested  struct {
+			List []int `json:"list"`
+		} `json:"nested"`
+	}{}
+	if err := dynamodbattribute.UnmarshalMap(out.Attributes, &got); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if got.Count != 5 || got.Name != "" || got.Created != "now" || len(got.Colors) != 3 || len(got.Nested.List) != 4 {
+		t.Errorf("FAIL - DATA: %+v", got)
+	}
+}
+
+func TestExpressionValidation(t *testing.T) {
+	var tests = []struct {
+		name   string
+		cond   *string
+		names  map[string]*string
+		values map[string]*dynamodb.AttributeValue
+	}{
+		{"syntax", aws.String("#c = "), map[string]*string{"#c": aws.String("count")}, nil},
+		{"undefined value", aws.String("#c = :v"), map[string]*string{"#c": aws.String("count")}, nil},
+		{"undefined name", aws.String("#c = :v"), nil, map[string]*dynamodb.AttributeValue{":v": {N: aws.String("1")}}},
+		{"unused name", aws.String("attribute_exists(#c)"), map[string]*string{"#c": aws.String("count"), "#x": aws.String("x")}, nil},
+		{"names without expression", nil, map[string]*string{"#c": aws.String("count")}, nil},
+	}
+
+	db := exprDB(t)
+	for _, test := range tests {
+		_, err := db.DeleteItem(&dynamodb.DeleteItemInput{
+			TableName:                 aws.String(TableName),
+			Key:                       exprKey,
+			ConditionExpression:       test.cond,
+			ExpressionAttributeNames:  test.names,
+			ExpressionAttributeValues: test.values,
+		})
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != errCodeValidation {
+			t.Errorf("FAIL: %s: %v; want: %s", test.name, err, errCodeValidation)
+		}
+	}
+}
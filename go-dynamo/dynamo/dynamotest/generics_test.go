@@ -0,0 +1,147 @@
+package dynamotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func TestGenericGetItem(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	var tests = []struct {
+		pk, sk    string
+		wantFound bool
+		wantCount int
+	}{
+		{pk: "A", sk: "001", wantFound: true, wantCount: 3},
+		{pk: "B", sk: "004", wantFound: true, wantCount: 10},
+		{pk: "A", sk: "999"}, // not found
+	}
+	for _, test := range tests {
+		r, err := dynamo.GetItem[*record](svc, dynamo.CreateNewQueryObj(test.pk, test.sk), TableName, dynamo.NewExpression())
+		if err != nil {
+			t.Errorf("FAIL: %v", err)
+			continue
+		}
+		if (r != nil) != test.wantFound {
+			t.Errorf("FAIL - DATA: %+v; want found: %v", r, test.wantFound)
+			continue
+		}
+		if r != nil && (r.UUID != test.sk || r.Count != test.wantCount) {
+			t.Errorf("FAIL - DATA: %+v; want: %s, %d", r, test.sk, test.wantCount)
+		}
+	}
+
+	// values are returned as well as pointers
+	r, err := dynamo.GetItem[record](svc, dynamo.CreateNewQueryObj("A", "002"), TableName, dynamo.NewExpression())
+	if err != nil || r.Count != 5 {
+		t.Errorf("FAIL: %+v, %v", r, err)
+	}
+	var tnf *dynamo.TableNotFoundErr
+	if _, err := dynamo.GetItem[record](svc, dynamo.CreateNewQueryObj("A", "002"), "missing", dynamo.NewExpression()); !errors.As(err, &tnf) {
+		t.Errorf("FAIL: %v; want: TableNotFoundErr", err)
+	}
+}
+
+func TestGenericScanAndQueryItems(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	// every result is a distinct value
+	res, err := dynamo.ScanItems[*record](svc, TableName, nil, dynamo.NewExpression(), nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, r := range res.Results {
+		seen[r.UUID] = true
+	}
+	if len(res.Results) != 5 || len(seen) != 5 {
+		t.Errorf("FAIL - DATA: %d results, %d distinct; want: 5", len(res.Results), len(seen))
+	}
+
+	kc := dynamo.NewKeyCondition()
+	kc.Equal("partition", "A")
+	eb := dynamo.NewExprBuilder()
+	eb.SetKeyCondition(kc)
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	// page through the partition, feeding LastKey back as the start key
+	got := []string{}
+	var startKey any
+	for page := 0; ; page++ {
+		qr, err := dynamo.QueryItems[record](svc, TableName, startKey, expr, aws.Int64(2))
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		if qr.PerPage != 2 {
+			t.Errorf("FAIL - DATA: per page %d; want: 2", qr.PerPage)
+		}
+		for _, r := range qr.Results {
+			got = append(got, r.UUID)
+		}
+		if qr.LastKey == nil {
+			break
+		}
+		startKey = qr.LastKey
+		if page > 3 {
+			t.Fatalf("FAIL: query did not terminate")
+		}
+	}
+	if len(got) != 3 || got[0] != "001" || got[2] != "003" {
+		t.Errorf("FAIL - DATA: %v", got)
+	}
+}
+
+func TestGenericBatchGet(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+	// force the client to retry unprocessed keys
+	db.MaxBatchGetProcessed = 2
+
+	queries := []*dynamo.Query{
+		dynamo.CreateNewQueryObj("A", "001"),
+		dynamo.CreateNewQueryObj("A", "002"),
+		dynamo.CreateNewQueryObj("A", "999"), // not found
+		dynamo.CreateNewQueryObj("B", "004"),
+		dynamo.CreateNewQueryObj("C", "005"),
+	}
+	eb := dynamo.NewExprBuilder()
+	eb.SetProjection([]string{"uuid", "count"})
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	got, err := dynamo.BatchGet[record](svc, TableName, &dynamo.FailConfig{Base: 1, Cap: 1000}, queries, expr)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	// one result per query, with the miss in the middle not found
+	wantCounts := []int{3, 5, -1, 10, 0}
+	if len(got) != len(wantCounts) {
+		t.Fatalf("FAIL - DATA: %d results; want: %d", len(got), len(wantCounts))
+	}
+	for i, want := range wantCounts {
+		r := got[i]
+		switch {
+		case r.Index != i || r.Err != nil:
+			t.Errorf("FAIL - DATA: result %d: %+v", i, r)
+		case want < 0:
+			if r.Found {
+				t.Errorf("FAIL - DATA: result %d: %+v; want: not found", i, r)
+			}
+		case !r.Found || r.Item.Count != want || r.Item.UUID != queries[i].SortValue:
+			t.Errorf("FAIL - DATA: result %d: %+v; want count: %d", i, r, want)
+		case r.Item.Partition != "":
+			t.Errorf("FAIL - DATA: projection not applied: %+v", r)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+type user struct {
+	ID     string `json:"id"`
+	Email  string `json:"email"`
+	Logins int    `json:"logins"`
+}
+
+func TestHashOnlyTable(t *testing.T) {
+	svc, db := New()
+	users := dynamo.CreateNewTableObj("users", "id", "string", "", "")
+	users.GlobalIndexes = []*dynamo.Index{dynamo.CreateNewIndexObj("by-email", "email", "string", "", "")}
+	if err := svc.CreateTable(users); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if ks := describeTable(t, db, "users").KeySchema; len(ks) != 1 {
+		t.Errorf("FAIL - DATA: key schema %v", ks)
+	}
+
+	items := []interface{}{}
+	for _, u := range []user{{ID: "u1", Email: "a@example.com"}, {ID: "u2", Email: "b@example.com"}, {ID: "u3", Email: "c@example.com"}} {
+		items = append(items, u)
+	}
+	if err := svc.BatchWriteCreate("users", &dynamo.FailConfig{}, items); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	// the sort value of a query is ignored for hash-only tables
+	u, err := dynamo.GetItem[*user](svc, dynamo.CreateNewQueryObj("u2", "ignored"), "users", dynamo.NewExpression())
+	if err != nil || u == nil || u.Email != "b@example.com" {
+		t.Errorf("FAIL: %+v, %v", u, err)
+	}
+
+	ud := dynamo.NewUpdateExpr()
+	ud.Set("logins", 1)
+	eb := dynamo.NewExprBuilder()
+	eb.SetUpdate(ud)
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := svc.UpdateItem(dynamo.CreateNewQueryObj("u1", nil), "users", expr); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+	got, err := dynamo.BatchGet[user](svc, "users", &dynamo.FailConfig{}, []*dynamo.Query{
+		dynamo.CreateNewQueryObj("u1", nil),
+		dynamo.CreateNewQueryObj("u3", nil),
+	}, dynamo.NewExpression())
+	if err != nil || len(got) != 2 || !got[0].Found || got[0].Item.ID != "u1" || !got[1].Found || got[1].Item.ID != "u3" {
+		t.Errorf("FAIL: %+v, %v", got, err)
+	}
+
+	kc := dynamo.NewKeyCondition()
+	kc.Equal("email", "c@example.com")
+	eb = dynamo.NewExprBuilder()
+	eb.SetKeyCondition(kc)
+	if expr, err = eb.BuildExpression(); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for u, err := range dynamo.QueryAll[user](context.Background(), svc, "users", expr, dynamo.IterOptions{IndexName: "by-email"}) {
+		if err != nil || u.ID != "u3" {
+			t.Errorf("FAIL: %+v, %v", u, err)
+		}
+	}
+
+	if err := svc.DeleteItem(dynamo.CreateNewQueryObj("u1", nil), "users"); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+	if err := svc.BatchWriteDelete("users", &dynamo.FailConfig{}, []*dynamo.Query{dynamo.CreateNewQueryObj("u2", nil)}); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+	if n := len(db.Items("users")); n != 1 {
+		t.Errorf("FAIL: %d items; want: 1", n)
+	}
+}
+
+func TestTableValidation(t *testing.T) {
+	var tests = []struct {
+		name    string
+		table   *dynamo.Table
+		wantErr bool
+	}{
+		{name: "hash only", table: dynamo.CreateNewTableObj("tbl", "id", "string", "", "")},
+		{name: "composite", table: dynamo.CreateNewTableObj("tbl", "id", "string", "ts", "int")},
+		{name: "no table name", table: dynamo.CreateNewTableObj("", "id", "string", "", ""), wantErr: true},
+		{name: "no partition key", table: dynamo.CreateNewTableObj("tbl", "", "string", "", ""), wantErr: true},
+		{name: "unknown type", table: dynamo.CreateNewTableObj("tbl", "id", "int64", "", ""), wantErr: true},
+		{name: "non-key type", table: dynamo.CreateNewTableObj("tbl", "id", "bool", "", ""), wantErr: true},
+		{name: "sort type without name", table: &dynamo.Table{TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S", SortKeyType: "S"}, wantErr: true},
+		{name: "sort name without type", table: &dynamo.Table{TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S", SortKeyName: "ts"}, wantErr: true},
+		{name: "same keys", table: dynamo.CreateNewTableObj("tbl", "id", "string", "id", "string"), wantErr: true},
+		{name: "local index on hash-only table", table: &dynamo.Table{
+			TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S",
+			LocalIndexes: []*dynamo.Index{{IndexName: "lsi", SortKeyName: "ts", SortKeyType: "N"}},
+		}, wantErr: true},
+		{name: "conflicting index key type", table: &dynamo.Table{
+			TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S",
+			GlobalIndexes: []*dynamo.Index{dynamo.CreateNewIndexObj("gsi", "id", "int", "", "")},
+		}, wantErr: true},
+		{name: "duplicate index", table: &dynamo.Table{
+			TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S",
+			GlobalIndexes: []*dynamo.Index{dynamo.CreateNewIndexObj("gsi", "a", "int", "", ""), dynamo.CreateNewIndexObj("gsi", "b", "int", "", "")},
+		}, wantErr: true},
+		{name: "include without projection", table: &dynamo.Table{
+			TableName: "tbl", PrimaryKeyName: "id", PrimaryKeyType: "S",
+			GlobalIndexes: []*dynamo.Index{{IndexName: "gsi", PrimaryKeyName: "a", PrimaryKeyType: "S", NonKeyAttributes: []string{"b"}}},
+		}, wantErr: true},
+	}
+	for _, test := range tests {
+		err := test.table.Validate()
+		if test.wantErr != errors.Is(err, dynamo.ErrInvalidTableDefinition) {
+			t.Errorf("FAIL: %s: %v; want error: %v", test.name, err, test.wantErr)
+		}
+		// invalid tables are rejected before reaching the database
+		svc, _ := New()
+		if err := svc.CreateTable(test.table); (err != nil) != test.wantErr {
+			t.Errorf("FAIL: %s: %v; want error: %v", test.name, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func indexedTable() *dynamo.Table {
+	t := dynamo.CreateNewTableObj(TableName, "partition", "string", "uuid", "string")
+	t.GlobalIndexes = []*dynamo.Index{dynamo.CreateNewIndexObj("by-count", "count", "int", "", "")}
+	t.LocalIndexes = []*dynamo.Index{{
+		IndexName:        "by-price",
+		SortKeyName:      "price",
+		SortKeyType:      "N",
+		ProjectionType:   "INCLUDE",
+		NonKeyAttributes: []string{"tags"},
+	}}
+	return t
+}
+
+func TestCreateTableWithIndexes(t *testing.T) {
+	svc, db := New()
+	table := indexedTable()
+	if err := svc.CreateTable(table); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	seed(t, svc)
+
+	out, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(TableName)})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(out.Table.AttributeDefinitions) != 4 || len(out.Table.GlobalSecondaryIndexes) != 1 || len(out.Table.LocalSecondaryIndexes) != 1 {
+		t.Errorf("FAIL - DATA: %v", out.Table)
+	}
+
+	var tests = []struct {
+		index    string
+		pk       string
+		pkValue  any
+		want     []string
+		wantFull bool
+	}{
+		{index: "by-count", pk: "count", pkValue: 5, want: []string{"002"}, wantFull: true},
+		{index: "by-count", pk: "count", pkValue: 6, want: []string{}},
+		// the local index orders the partition by price and projects keys and tags only
+		{index: "by-price", pk: "partition", pkValue: "A", want: []string{"003", "002", "001"}},
+	}
+	for _, test := range tests {
+		kc := dynamo.NewKeyCondition()
+		kc.Equal(test.pk, test.pkValue)
+		eb := dynamo.NewExprBuilder()
+		eb.SetKeyCondition(kc)
+		expr, err := eb.BuildExpression()
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		res, err := dynamo.QueryIndex[record](svc, TableName, test.index, nil, expr, nil)
+		if err != nil {
+			t.Errorf("FAIL: %s: %v", test.index, err)
+			continue
+		}
+		if len(res.Results) != len(test.want) {
+			t.Errorf("FAIL - DATA: %s: %+v; want: %v", test.index, res.Results, test.want)
+			continue
+		}
+		for i, r := range res.Results {
+			if r.UUID != test.want[i] || (r.Count != 0) != test.wantFull {
+				t.Errorf("FAIL - DATA: %s: %+v; want: %s", test.index, r, test.want[i])
+			}
+		}
+	}
+
+	// scans and iterators read the index too
+	scan, err := svc.ScanIndex(TableName, "by-count", &record{}, nil, dynamo.NewExpression(), nil)
+	if err != nil || len(scan.Results) != 5 {
+		t.Errorf("FAIL: %v, %v", scan, err)
+	}
+	n := 0
+	for _, err := range dynamo.ScanAll[record](context.Background(), svc, TableName, dynamo.NewExpression(), dynamo.IterOptions{IndexName: "by-price"}) {
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		n++
+	}
+	if n != 5 {
+		t.Errorf("FAIL: %d items; want: 5", n)
+	}
+
+	// tables created by New include their indexes
+	svc, _ = New(indexedTable())
+	if _, err := svc.ScanIndex(TableName, "by-price", &record{}, nil, dynamo.NewExpression(), nil); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+
+	var inf *dynamo.IndexNotFoundErr
+	if _, err := svc.QueryIndex(TableName, "missing", &record{}, nil, dynamo.NewExpression(), nil); !errors.As(err, &inf) {
+		t.Errorf("FAIL: %v; want: IndexNotFoundErr", err)
+	}
+}
@@ -0,0 +1,618 @@
+package dynamotest
+
+// This file contains item level operations.
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// PutItem creates or replaces an item.
+func (db *DB) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("PutItem"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	it := item(input.Item)
+	if err := t.validateItem(it); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	switch rv := aws.StringValue(input.ReturnValues); rv {
+	case "", dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld:
+	default:
+		return nil, validationErr("ReturnValues can only be ALL_OLD or NONE")
+	}
+	attrs := newExprAttrs(input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	cond, err := parseOptionalCondition(input.ConditionExpression, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+
+	old := t.get(it)
+	if err := checkCondition(cond, old, input.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+	t.put(item(cloneItem(it)))
+
+	out := &dynamodb.PutItemOutput{}
+	if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld && old != nil {
+		out.Attributes = cloneItem(old)
+	}
+	return out, nil
+}
+
+// GetItem returns the item with the given key.
+func (db *DB) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("GetItem"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.keys.validateKey(input.Key); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	attrs := newExprAttrs(input.ExpressionAttributeNames, nil)
+	proj, err := parseOptionalProjection(input.ProjectionExpression, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+
+	out := &dynamodb.GetItemOutput{}
+	if it := t.get(input.Key); it != nil {
+		out.Item = applyProjection(it, proj)
+	}
+	return out, nil
+}
+
+// UpdateItem edits an existing item's attributes, or adds a new item if it does not exist.
+func (db *DB) UpdateItem(input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("UpdateItem"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.keys.validateKey(input.Key); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	attrs := newExprAttrs(input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	cond, err := parseOptionalCondition(input.ConditionExpression, attrs)
+	if err != nil {
+		return nil, err
+	}
+	var actions []updateAction
+	if input.UpdateExpression != nil {
+		if actions, err = parseUpdateExpr(*input.UpdateExpression, attrs); err != nil {
+			return nil, validationErr("Invalid UpdateExpression: %s", err.Error())
+		}
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+
+	old := t.get(input.Key)
+	if err := checkCondition(cond, old, input.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+	updated, paths, err := t.update(input.Key, old, actions)
+	if err != nil {
+		return nil, err
+	}
+	t.put(updated)
+
+	out := &dynamodb.UpdateItemOutput{}
+	switch aws.StringValue(input.ReturnValues) {
+	case "", dynamodb.ReturnValueNone:
+	case dynamodb.ReturnValueAllOld:
+		if old != nil {
+			out.Attributes = cloneItem(old)
+		}
+	case dynamodb.ReturnValueUpdatedOld:
+		if old != nil {
+			out.Attributes = nonEmpty(project(old, paths))
+		}
+	case dynamodb.ReturnValueAllNew:
+		out.Attributes = cloneItem(updated)
+	case dynamodb.ReturnValueUpdatedNew:
+		out.Attributes = nonEmpty(project(updated, paths))
+	default:
+		return nil, validationErr("Invalid ReturnValues: %s", aws.StringValue(input.ReturnValues))
+	}
+	return out, nil
+}
+
+// DeleteItem deletes a single item by primary key.
+func (db *DB) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("DeleteItem"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.keys.validateKey(input.Key); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	switch aws.StringValue(input.ReturnValues) {
+	case "", dynamodb.ReturnValueNone, dynamodb.ReturnValueAllOld:
+	default:
+		return nil, validationErr("ReturnValues can only be ALL_OLD or NONE")
+	}
+	attrs := newExprAttrs(input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	cond, err := parseOptionalCondition(input.ConditionExpression, attrs)
+	if err != nil {
+		return nil, err
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+
+	old := t.get(input.Key)
+	if err := checkCondition(cond, old, input.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+	t.remove(input.Key)
+
+	out := &dynamodb.DeleteItemOutput{}
+	if aws.StringValue(input.ReturnValues) == dynamodb.ReturnValueAllOld && old != nil {
+		out.Attributes = cloneItem(old)
+	}
+	return out, nil
+}
+
+// Query returns the items with the partition key given in the key condition expression.
+func (db *DB) Query(input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("Query"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := t.index(input.IndexName)
+	if err != nil {
+		return nil, err
+	}
+	if input.KeyConditionExpression == nil {
+		return nil, validationErr("Either the KeyConditions or KeyConditionExpression parameter must be specified in the request.")
+	}
+
+	attrs := newExprAttrs(input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	keyCond, err := parseConditionExpr(*input.KeyConditionExpression, attrs)
+	if err != nil {
+		return nil, validationErr("Invalid KeyConditionExpression: %s", err.Error())
+	}
+	ks := t.keys
+	if idx != nil {
+		ks = idx.keys
+	}
+	if err := validateKeyCondition(keyCond, ks); err != nil {
+		return nil, validationErr("Query key condition not supported: %s", err.Error())
+	}
+	r, err := newReader(t, idx, input.FilterExpression, input.ProjectionExpression, input.Select, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.read(input.ExclusiveStartKey, input.Limit, !aws.BoolValue(orTrue(input.ScanIndexForward)), func(it item) (bool, error) {
+		return keyCond.eval(it)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.QueryOutput{
+		Count:            res.count,
+		Items:            res.items,
+		LastEvaluatedKey: res.lastKey,
+		ScannedCount:     res.scanned,
+	}, nil
+}
+
+// Scan returns every item in the table or index.
+func (db *DB) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("Scan"); err != nil {
+		return nil, err
+	}
+
+	t, err := db.table(input.TableName)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := t.index(input.IndexName)
+	if err != nil {
+		return nil, err
+	}
+	segment, total := aws.Int64Value(input.Segment), aws.Int64Value(input.TotalSegments)
+	if (input.Segment == nil) != (input.TotalSegments == nil) || (total > 0 && segment >= total) {
+		return nil, validationErr("Segment and TotalSegments must both be specified, and Segment must be less than TotalSegments")
+	}
+
+	attrs := newExprAttrs(input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	r, err := newReader(t, idx, input.FilterExpression, input.ProjectionExpression, input.Select, attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.read(input.ExclusiveStartKey, input.Limit, false, func(it item) (bool, error) {
+		if total == 0 {
+			return true, nil
+		}
+		h := fnv.New32a()
+		h.Write([]byte(encodeKeyPart(it[t.keys.hash])))
+		return int64(h.Sum32())%total == segment, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.ScanOutput{
+		Count:            res.count,
+		Items:            res.items,
+		LastEvaluatedKey: res.lastKey,
+		ScannedCount:     res.scanned,
+	}, nil
+}
+
+// reader implements the shared paging, filtering and projection logic of Query and Scan.
+type reader struct {
+	t         *table
+	idx       *index
+	filter    condition
+	proj      []docPath
+	countOnly bool
+}
+
+type readResult struct {
+	items   []map[string]*dynamodb.AttributeValue
+	count   *int64
+	scanned *int64
+	lastKey map[string]*dynamodb.AttributeValue
+}
+
+func newReader(t *table, idx *index, filter, proj, sel *string, attrs *exprAttrs) (*reader, error) {
+	r := &reader{t: t, idx: idx}
+	var err error
+	if filter != nil {
+		if r.filter, err = parseConditionExpr(*filter, attrs); err != nil {
+			return nil, validationErr("Invalid FilterExpression: %s", err.Error())
+		}
+	}
+	if r.proj, err = parseOptionalProjection(proj, attrs); err != nil {
+		return nil, err
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	switch aws.StringValue(sel) {
+	case "", dynamodb.SelectAllAttributes, dynamodb.SelectAllProjectedAttributes, dynamodb.SelectSpecificAttributes:
+	case dynamodb.SelectCount:
+		r.countOnly = true
+	default:
+		return nil, validationErr("Invalid Select: %s", aws.StringValue(sel))
+	}
+	return r, nil
+}
+
+// read evaluates up to limit items matching scope, starting after startKey.
+func (r *reader) read(startKey map[string]*dynamodb.AttributeValue, limit *int64, reverse bool, scope func(item) (bool, error)) (*readResult, error) {
+	if limit != nil && *limit < 1 {
+		return nil, validationErr("Limit must be greater than or equal to 1")
+	}
+	all := r.t.sorted(r.idx)
+	if reverse {
+		for i, j := 0, len(all)-1; i < j; i, j = i+1, j-1 {
+			all[i], all[j] = all[j], all[i]
+		}
+	}
+
+	candidates := make([]item, 0, len(all))
+	for _, it := range all {
+		ok, err := scope(it)
+		if err != nil {
+			return nil, validationErr("%s", err.Error())
+		}
+		if ok {
+			candidates = append(candidates, it)
+		}
+	}
+
+	if startKey != nil {
+		if err := r.t.keys.validateItem(startKey); err != nil {
+			return nil, validationErr("The provided starting key is invalid: %s", err.Error())
+		}
+		start := r.t.keys.encode(startKey)
+		pos := len(candidates)
+		for i, it := range candidates {
+			if r.t.keys.encode(it) == start {
+				pos = i + 1
+				break
+			}
+		}
+		candidates = candidates[pos:]
+	}
+
+	res := &readResult{items: []map[string]*dynamodb.AttributeValue{}}
+	var count, scanned int64
+	for _, it := range candidates {
+		if limit != nil && scanned == *limit {
+			res.lastKey = r.t.indexKeyOf(r.idx, candidates[scanned-1])
+			break
+		}
+		scanned++
+		visible := it
+		if r.idx != nil && r.idx.global {
+			visible = r.t.projectIndex(r.idx, it)
+		}
+		if r.filter != nil {
+			ok, err := r.filter.eval(visible)
+			if err != nil {
+				return nil, validationErr("%s", err.Error())
+			}
+			if !ok {
+				continue
+			}
+		}
+		count++
+		if r.countOnly {
+			continue
+		}
+		if r.proj == nil && r.idx != nil {
+			res.items = append(res.items, r.t.projectIndex(r.idx, it))
+			continue
+		}
+		res.items = append(res.items, applyProjection(visible, r.proj))
+	}
+	if r.countOnly {
+		res.items = nil
+	}
+	res.count, res.scanned = aws.Int64(count), aws.Int64(scanned)
+	return res, nil
+}
+
+// index returns the named index, or nil if name is nil.
+func (t *table) index(name *string) (*index, error) {
+	if name == nil {
+		return nil, nil
+	}
+	idx := t.indexes[*name]
+	if idx == nil {
+		return nil, validationErr("The table does not have the specified index: %s", *name)
+	}
+	return idx, nil
+}
+
+// validateItem checks the table and index key attributes of an item.
+func (t *table) validateItem(it item) error {
+	if err := t.keys.validateItem(it); err != nil {
+		return err
+	}
+	for _, name := range sortedNames(t.indexes) {
+		idx := t.indexes[name]
+		for _, n := range idx.keys.names() {
+			if v, ok := it[n]; ok && avType(v) != idx.keys.types[n] {
+				return fmt.Errorf("One or more parameter values were invalid: Type mismatch for Index Key %s Expected: %s Actual: %s IndexName: %s", n, idx.keys.types[n], avType(v), name)
+			}
+		}
+	}
+	return nil
+}
+
+// update applies actions to old, or to a new item with the given key if old is nil.
+func (t *table) update(key, old item, actions []updateAction) (item, []docPath, error) {
+	for _, a := range actions {
+		for _, n := range t.keys.names() {
+			if a.path[0].name == n {
+				return nil, nil, validationErr("One or more parameter values were invalid: Cannot update attribute %s. This attribute is part of the key", n)
+			}
+		}
+	}
+	base := old
+	if base == nil {
+		base = item(cloneItem(key))
+	}
+	updated, paths, err := applyUpdate(base, actions)
+	if err != nil {
+		return nil, nil, validationErr("%s", err.Error())
+	}
+	if err := t.validateItem(updated); err != nil {
+		return nil, nil, validationErr("%s", err.Error())
+	}
+	return updated, paths, nil
+}
+
+// validateKeyCondition checks that a key condition selects a single partition
+// and optionally constrains the sort key.
+func validateKeyCondition(c condition, ks keySchema) error {
+	conds := []condition{c}
+	if and, ok := c.(andCond); ok {
+		conds = []condition{and.left, and.right}
+	}
+	hash := false
+	for _, c := range conds {
+		name, op := "", ""
+		switch cc := c.(type) {
+		case compareCond:
+			if p, ok := cc.left.(pathOperand); ok && len(p.path) == 1 {
+				name, op = p.path[0].name, cc.op
+			}
+			if _, ok := cc.right.(valueOperand); !ok {
+				name = ""
+			}
+		case betweenCond:
+			if p, ok := cc.value.(pathOperand); ok && len(p.path) == 1 {
+				name, op = p.path[0].name, "BETWEEN"
+			}
+		case funcCond:
+			if cc.name == "begins_with" && len(cc.path) == 1 {
+				name, op = cc.path[0].name, cc.name
+			}
+		}
+		switch {
+		case name == ks.hash && op == "=" && !hash:
+			hash = true
+		case name != "" && name == ks.rng && op != "<>":
+		default:
+			return fmt.Errorf("invalid key condition")
+		}
+	}
+	if !hash {
+		return fmt.Errorf("key condition must specify the partition key %s with the = operator", ks.hash)
+	}
+	return nil
+}
+
+func parseOptionalCondition(expr *string, attrs *exprAttrs) (condition, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	c, err := parseConditionExpr(*expr, attrs)
+	if err != nil {
+		return nil, validationErr("Invalid ConditionExpression: %s", err.Error())
+	}
+	return c, nil
+}
+
+func parseOptionalProjection(expr *string, attrs *exprAttrs) ([]docPath, error) {
+	if expr == nil {
+		return nil, nil
+	}
+	p, err := parseProjectionExpr(*expr, attrs)
+	if err != nil {
+		return nil, validationErr("Invalid ProjectionExpression: %s", err.Error())
+	}
+	return p, nil
+}
+
+// checkCondition evaluates cond against old and returns a ConditionalCheckFailedException
+// if it does not hold.
+func checkCondition(cond condition, old item, onFailure *string) error {
+	if cond == nil {
+		return nil
+	}
+	ok, err := evalCondition(cond, old)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	e := &dynamodb.ConditionalCheckFailedException{Message_: aws.String("The conditional request failed")}
+	if aws.StringValue(onFailure) == dynamodb.ReturnValuesOnConditionCheckFailureAllOld && old != nil {
+		e.Item = cloneItem(old)
+	}
+	return e
+}
+
+// evalCondition evaluates cond against it, which may be nil.
+func evalCondition(cond condition, it item) (bool, error) {
+	if it == nil {
+		it = item{}
+	}
+	ok, err := cond.eval(it)
+	if err != nil {
+		return false, validationErr("Invalid ConditionExpression: %s", err.Error())
+	}
+	return ok, nil
+}
+
+// applyProjection returns a copy of it limited to proj, if set.
+func applyProjection(it item, proj []docPath) map[string]*dynamodb.AttributeValue {
+	if proj == nil {
+		return cloneItem(it)
+	}
+	return project(it, proj)
+}
+
+func nonEmpty(it item) map[string]*dynamodb.AttributeValue {
+	if len(it) == 0 {
+		return nil
+	}
+	return it
+}
+
+func orTrue(b *bool) *bool {
+	if b == nil {
+		return aws.Bool(true)
+	}
+	return b
+}
+
+// PutItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, _ ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.PutItem(input)
+}
+
+// GetItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, _ ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.GetItem(input)
+}
+
+// UpdateItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, _ ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.UpdateItem(input)
+}
+
+// DeleteItemWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, _ ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.DeleteItem(input)
+}
+
+// QueryWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, _ ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.Query(input)
+}
+
+// ScanWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, _ ...request.Option) (*dynamodb.ScanOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.Scan(input)
+}
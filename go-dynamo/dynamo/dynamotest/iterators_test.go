@@ -0,0 +1,111 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func TestQueryAll(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	kc := dynamo.NewKeyCondition()
+	kc.Equal("partition", "A")
+	eb := dynamo.NewExprBuilder()
+	eb.SetKeyCondition(kc)
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	var tests = []struct {
+		opts     dynamo.IterOptions
+		throttle int
+		want     int
+		wantErr  error
+	}{
+		{opts: dynamo.IterOptions{}, want: 3},
+		{opts: dynamo.IterOptions{PerPage: aws.Int64(1)}, want: 3},
+		{opts: dynamo.IterOptions{PerPage: aws.Int64(1), MaxItems: 2}, want: 2},
+		{opts: dynamo.IterOptions{StartKey: map[string]string{"partition": "A", "uuid": "001"}}, want: 2},
+		{opts: dynamo.IterOptions{PerPage: aws.Int64(2)}, throttle: 1, wantErr: dynamo.ErrRateLimitExceeded},
+		{opts: dynamo.IterOptions{PerPage: aws.Int64(2), Retry: fastRetry}, throttle: 2, want: 3},
+	}
+	for i, test := range tests {
+		db.FailNext("Query", test.throttle, awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil))
+		got := 0
+		var gotErr error
+		for r, err := range dynamo.QueryAll[*record](context.Background(), svc, TableName, expr, test.opts) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+			if r.Partition != "A" {
+				t.Errorf("FAIL - DATA: %+v", r)
+			}
+			got++
+		}
+		if !errors.Is(gotErr, test.wantErr) {
+			t.Errorf("FAIL: %d: %v; want: %v", i, gotErr, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("FAIL - DATA: %d: %d items; want: %d", i, got, test.want)
+		}
+	}
+}
+
+func TestScanAll(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	got := []string{}
+	for r, err := range dynamo.ScanAll[record](context.Background(), svc, TableName, dynamo.NewExpression(), dynamo.IterOptions{PerPage: aws.Int64(2)}) {
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		got = append(got, r.UUID)
+	}
+	if len(got) != 5 {
+		t.Errorf("FAIL - DATA: %v", got)
+	}
+
+	// stopping early does not read further pages
+	n := 0
+	for range dynamo.ScanAll[record](context.Background(), svc, TableName, dynamo.NewExpression(), dynamo.IterOptions{PerPage: aws.Int64(2)}) {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("FAIL: %d items after break", n)
+	}
+
+	// a canceled context ends iteration with its error
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n = 0
+	var gotErr error
+	for _, err := range dynamo.ScanAll[record](ctx, svc, TableName, dynamo.NewExpression(), dynamo.IterOptions{PerPage: aws.Int64(2)}) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		n++
+		cancel()
+	}
+	if n != 2 || !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("FAIL: %d items, %v; want: 2, %v", n, gotErr, context.Canceled)
+	}
+
+	var tnf *dynamo.TableNotFoundErr
+	for _, err := range dynamo.ScanAll[record](context.Background(), svc, "missing", dynamo.NewExpression(), dynamo.IterOptions{}) {
+		if !errors.As(err, &tnf) {
+			t.Errorf("FAIL: %v; want: TableNotFoundErr", err)
+		}
+	}
+}
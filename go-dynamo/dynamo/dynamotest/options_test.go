@@ -0,0 +1,117 @@
+package dynamotest
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func describeTable(t *testing.T, db *DB, name string) *dynamodb.TableDescription {
+	t.Helper()
+	out, err := db.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(name)})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return out.Table
+}
+
+func TestCreateTableWithOptions(t *testing.T) {
+	var tests = []struct {
+		opts    *dynamo.TableOptions
+		wantErr bool
+	}{
+		{opts: nil},
+		{opts: &dynamo.TableOptions{BillingMode: "PROVISIONED", ReadCapacity: 5, WriteCapacity: 2}},
+		{opts: &dynamo.TableOptions{BillingMode: "PROVISIONED"}, wantErr: true}, // no capacity
+		{opts: &dynamo.TableOptions{
+			StreamViewType:     "NEW_AND_OLD_IMAGES",
+			KMSKeyID:           "alias/app",
+			TableClass:         "STANDARD_INFREQUENT_ACCESS",
+			DeletionProtection: true,
+			Tags:               map[string]string{"env": "dev", "team": "it-eng"},
+		}},
+	}
+	for i, test := range tests {
+		svc, db := New()
+		table := indexedTable()
+		table.Options = test.opts
+		err := svc.CreateTable(table)
+		if (err != nil) != test.wantErr {
+			t.Errorf("FAIL: %d: %v; want error: %v", i, err, test.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+
+		desc := describeTable(t, db, TableName)
+		opts := test.opts
+		if opts == nil {
+			opts = &dynamo.TableOptions{BillingMode: "PAY_PER_REQUEST"}
+		}
+		if mode := aws.StringValue(desc.BillingModeSummary.BillingMode); opts.BillingMode != "" && mode != opts.BillingMode {
+			t.Errorf("FAIL - DATA: %d: billing mode %s; want: %s", i, mode, opts.BillingMode)
+		}
+		if opts.ReadCapacity > 0 && (aws.Int64Value(desc.ProvisionedThroughput.ReadCapacityUnits) != 5 ||
+			aws.Int64Value(desc.GlobalSecondaryIndexes[0].ProvisionedThroughput.WriteCapacityUnits) != 2) {
+			t.Errorf("FAIL - DATA: %d: %v", i, desc)
+		}
+		if opts.StreamViewType != "" && (desc.LatestStreamArn == nil || aws.StringValue(desc.StreamSpecification.StreamViewType) != opts.StreamViewType) {
+			t.Errorf("FAIL - DATA: %d: stream %v", i, desc.StreamSpecification)
+		}
+		if opts.KMSKeyID != "" && (aws.StringValue(desc.SSEDescription.KMSMasterKeyArn) != opts.KMSKeyID || aws.StringValue(desc.SSEDescription.SSEType) != "KMS") {
+			t.Errorf("FAIL - DATA: %d: sse %v", i, desc.SSEDescription)
+		}
+		if opts.TableClass != "" && aws.StringValue(desc.TableClassSummary.TableClass) != opts.TableClass {
+			t.Errorf("FAIL - DATA: %d: class %v", i, desc.TableClassSummary)
+		}
+		if aws.BoolValue(desc.DeletionProtectionEnabled) != opts.DeletionProtection {
+			t.Errorf("FAIL - DATA: %d: deletion protection %v", i, desc.DeletionProtectionEnabled)
+		}
+		if opts.DeletionProtection {
+			if err := svc.DeleteTable(TableName); err == nil {
+				t.Errorf("FAIL: %d: protected table deleted", i)
+			}
+		}
+		tags, err := db.ListTagsOfResource(&dynamodb.ListTagsOfResourceInput{ResourceArn: desc.TableArn})
+		if err != nil || len(tags.Tags) != len(opts.Tags) {
+			t.Errorf("FAIL - DATA: %d: tags %v, %v", i, tags, err)
+		}
+	}
+}
+
+func TestUpdateTable(t *testing.T) {
+	svc, db := New(indexedTable())
+
+	var tests = []struct {
+		billingMode string
+		read, write int64
+		wantErr     bool
+	}{
+		{billingMode: "PROVISIONED", read: 10, write: 5},
+		{billingMode: "PROVISIONED", read: 20, write: 5},
+		{billingMode: "PROVISIONED", wantErr: true},
+		{billingMode: "PAY_PER_REQUEST"},
+	}
+	for _, test := range tests {
+		err := svc.UpdateTable(TableName, test.billingMode, test.read, test.write)
+		if (err != nil) != test.wantErr {
+			t.Errorf("FAIL: %v; want error: %v", err, test.wantErr)
+		}
+		if err != nil {
+			continue
+		}
+		desc := describeTable(t, db, TableName)
+		if aws.StringValue(desc.BillingModeSummary.BillingMode) != test.billingMode {
+			t.Errorf("FAIL - DATA: billing mode %s; want: %s", aws.StringValue(desc.BillingModeSummary.BillingMode), test.billingMode)
+		}
+		pt := desc.GlobalSecondaryIndexes[0].ProvisionedThroughput
+		if test.read > 0 && (pt == nil || aws.Int64Value(pt.ReadCapacityUnits) != test.read) {
+			t.Errorf("FAIL - DATA: index throughput %v; want: %d", pt, test.read)
+		}
+		if test.read == 0 && (desc.ProvisionedThroughput != nil || pt != nil) {
+			t.Errorf("FAIL - DATA: on-demand table has throughput %v, %v", desc.ProvisionedThroughput, pt)
+		}
+	}
+}
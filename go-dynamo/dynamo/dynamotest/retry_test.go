@@ -0,0 +1,158 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+// fastRetry retries with waits of at most a few milliseconds.
+var fastRetry = &dynamo.Backoff{Base: time.Millisecond, Cap: 10 * time.Millisecond, MaxElapsed: time.Second}
+
+// fakeClock advances when waited on and records each wait.
+type fakeClock struct {
+	now   time.Time
+	waits []time.Duration
+	block bool // waits never end
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	if c.block {
+		return nil
+	}
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func TestBackoff(t *testing.T) {
+	base, limit := 100*time.Millisecond, time.Second
+	var tests = []struct {
+		jitter dynamo.Jitter
+		bounds func(retry int, prev time.Duration) (lo, hi time.Duration)
+	}{
+		{jitter: dynamo.FullJitter, bounds: func(retry int, _ time.Duration) (time.Duration, time.Duration) {
+			return 0, min(limit, base<<(retry-1))
+		}},
+		{jitter: dynamo.EqualJitter, bounds: func(retry int, _ time.Duration) (time.Duration, time.Duration) {
+			exp := min(limit, base<<(retry-1))
+			return exp / 2, exp
+		}},
+		{jitter: dynamo.DecorrelatedJitter, bounds: func(_ int, prev time.Duration) (time.Duration, time.Duration) {
+			return base, min(limit, 3*prev)
+		}},
+	}
+	for _, test := range tests {
+		for run := 0; run < 20; run++ {
+			clock := &fakeClock{}
+			r := (&dynamo.Backoff{Jitter: test.jitter, Base: base, Cap: limit, MaxAttempts: 8, Clock: clock}).Start()
+			var err error
+			for err == nil {
+				err = r.Wait(context.Background())
+			}
+			if !errors.Is(err, dynamo.ErrRetriesExhausted) || len(clock.waits) != 7 {
+				t.Fatalf("FAIL: jitter %d: %v after %d waits; want: %v after 7", test.jitter, err, len(clock.waits), dynamo.ErrRetriesExhausted)
+			}
+			prev := base
+			for i, w := range clock.waits {
+				if lo, hi := test.bounds(i+1, prev); w < lo || w > hi {
+					t.Errorf("FAIL - DATA: jitter %d: wait %d: %v; want: [%v, %v]", test.jitter, i+1, w, lo, hi)
+				}
+				prev = w
+			}
+		}
+	}
+}
+
+func TestBackoffLimits(t *testing.T) {
+	// waits stop before exceeding MaxElapsed
+	clock := &fakeClock{}
+	r := (&dynamo.Backoff{Jitter: dynamo.EqualJitter, Base: time.Second, MaxElapsed: 5 * time.Second, Clock: clock}).Start()
+	var err error
+	for err == nil {
+		err = r.Wait(context.Background())
+	}
+	if !errors.Is(err, dynamo.ErrRetriesExhausted) || len(clock.waits) < 2 || clock.now.Sub(time.Time{}) > 5*time.Second {
+		t.Errorf("FAIL: %v after %v; want: %v within 5s", err, clock.waits, dynamo.ErrRetriesExhausted)
+	}
+
+	// waits end when the context is done
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := (&dynamo.Backoff{Base: time.Second, Clock: &fakeClock{}}).Start().Wait(cancelled); !errors.Is(err, context.Canceled) {
+		t.Errorf("FAIL: %v; want: %v", err, context.Canceled)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := (&dynamo.Backoff{Base: time.Hour, Clock: &fakeClock{block: true}}).Start().Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FAIL: %v; want: %v", err, context.DeadlineExceeded)
+	}
+
+	// a FailConfig without a Cap does not retry
+	if err := (&dynamo.FailConfig{}).Start().Wait(context.Background()); !errors.Is(err, dynamo.ErrRetriesExhausted) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrRetriesExhausted)
+	}
+	if err := (&dynamo.FailConfig{Base: 1, Cap: 1000}).Start().Wait(context.Background()); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+}
+
+func TestRetryThrottledRequests(t *testing.T) {
+	throttled := awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil)
+	clock := &fakeClock{}
+	db := NewDB(testTable)
+	svc := dynamo.NewDynamoDBWithClient(db, []*dynamo.Table{testTable}, &dynamo.Backoff{Base: time.Millisecond, MaxAttempts: 3, Clock: clock})
+
+	var tests = []struct {
+		op        string
+		throttles int
+		call      func() error
+		wantErr   error
+	}{
+		{op: "PutItem", throttles: 2, call: func() error {
+			return svc.CreateItem(record{Partition: "A", UUID: "001", Count: 1}, TableName)
+		}},
+		{op: "GetItem", throttles: 3, call: func() error {
+			_, err := svc.GetItem(dynamo.CreateNewQueryObj("A", "001"), TableName, &record{}, dynamo.NewExpression())
+			return err
+		}, wantErr: dynamo.ErrRateLimitExceeded},
+		{op: "UpdateItem", throttles: 1, call: func() error {
+			ud := dynamo.NewUpdateExpr()
+			ud.Set("count", 2)
+			eb := dynamo.NewExprBuilder()
+			eb.SetUpdate(ud)
+			return svc.UpdateItem(dynamo.CreateNewQueryObj("A", "001"), TableName, buildExpr(t, eb))
+		}},
+		{op: "TransactWriteItems", throttles: 2, call: func() error {
+			_, err := svc.TxWrite([]dynamo.TransactionItem{
+				dynamo.NewCreateTxItem("put", record{Partition: "A", UUID: "002"}, testTable, nil, dynamo.NewExpression()),
+			}, "")
+			return err
+		}},
+		{op: "DeleteItem", throttles: 2, call: func() error {
+			return svc.DeleteItem(dynamo.CreateNewQueryObj("A", "001"), TableName)
+		}},
+	}
+	for _, test := range tests {
+		clock.waits = nil
+		db.FailNext(test.op, test.throttles, throttled)
+		if err := test.call(); !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %s: %v; want: %v", test.op, err, test.wantErr)
+		}
+		if n := min(test.throttles, 2); len(clock.waits) != n {
+			t.Errorf("FAIL: %s: %d retries; want: %d", test.op, len(clock.waits), n)
+		}
+	}
+	if n := len(db.Items(TableName)); n != 1 {
+		t.Errorf("FAIL: %d items; want: 1", n)
+	}
+}
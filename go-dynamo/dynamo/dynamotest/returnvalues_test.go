@@ -0,0 +1,209 @@
+package dynamotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func buildExpr(t *testing.T, eb dynamo.ExprBuilder) dynamo.Expression {
+	t.Helper()
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return expr
+}
+
+func TestConditionalCreateAndDelete(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	absent := dynamo.NewCondition()
+	absent.AttributeNotExists("partition")
+	eb := dynamo.NewExprBuilder()
+	eb.SetCondition(absent)
+	ifAbsent := buildExpr(t, eb)
+
+	countIs := func(n int) dynamo.Expression {
+		cond := dynamo.NewCondition()
+		cond.Equal("count", n)
+		eb := dynamo.NewExprBuilder()
+		eb.SetCondition(cond)
+		return buildExpr(t, eb)
+	}
+
+	var tests = []struct {
+		name         string
+		write        func(out *record) error
+		wantErr      bool
+		wantOut      int // count of the returned item
+		wantCurrent  int // count of the current item on failure
+		wantNotExist bool
+	}{
+		{name: "put if absent", write: func(out *record) error {
+			return svc.CreateItemWithExpr(record{Partition: "A", UUID: "001", Count: 99}, TableName, ifAbsent, dynamo.ReturnValuesAllOld, out)
+		}, wantErr: true, wantCurrent: 3},
+		{name: "put new item if absent", write: func(out *record) error {
+			return svc.CreateItemWithExpr(record{Partition: "D", UUID: "006", Count: 1}, TableName, ifAbsent, dynamo.ReturnValuesAllOld, out)
+		}},
+		{name: "replace", write: func(out *record) error {
+			return svc.CreateItemWithExpr(record{Partition: "A", UUID: "002", Count: 50}, TableName, countIs(5), dynamo.ReturnValuesAllOld, out)
+		}, wantOut: 5},
+		{name: "delete if count", write: func(out *record) error {
+			return svc.DeleteItemWithExpr(dynamo.CreateNewQueryObj("A", "003"), TableName, countIs(7), dynamo.ReturnValuesAllOld, out)
+		}, wantOut: 7},
+		{name: "delete if count fails", write: func(out *record) error {
+			return svc.DeleteItemWithExpr(dynamo.CreateNewQueryObj("B", "004"), TableName, countIs(1), dynamo.ReturnValuesAllOld, out)
+		}, wantErr: true, wantCurrent: 10},
+		{name: "delete missing item if count", write: func(out *record) error {
+			return svc.DeleteItemWithExpr(dynamo.CreateNewQueryObj("B", "999"), TableName, countIs(1), dynamo.ReturnValuesNone, out)
+		}, wantErr: true, wantNotExist: true},
+		{name: "invalid return values", write: func(out *record) error {
+			return svc.CreateItemWithExpr(record{Partition: "E", UUID: "007"}, TableName, dynamo.NewExpression(), dynamo.ReturnValuesAllNew, out)
+		}, wantErr: true},
+	}
+	for _, test := range tests {
+		out := &record{}
+		err := test.write(out)
+		if (err != nil) != test.wantErr {
+			t.Errorf("FAIL: %s: %v; want error: %v", test.name, err, test.wantErr)
+			continue
+		}
+		if out.Count != test.wantOut {
+			t.Errorf("FAIL - DATA: %s: returned %+v; want count: %d", test.name, out, test.wantOut)
+		}
+
+		var ccf *dynamo.ConditionCheckFailedErr
+		if !errors.As(err, &ccf) {
+			continue
+		}
+		current := &record{}
+		ok, err := ccf.Item(current)
+		if err != nil || ok == test.wantNotExist || current.Count != test.wantCurrent {
+			t.Errorf("FAIL - DATA: %s: current %+v, %v, %v; want count: %d", test.name, current, ok, err, test.wantCurrent)
+		}
+	}
+
+	if n := len(db.Items(TableName)); n != 5 {
+		t.Errorf("FAIL: %d items; want: 5", n)
+	}
+}
+
+func TestUpdateItemWithReturn(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	var tests = []struct {
+		returnValues string
+		want         record
+	}{
+		{returnValues: dynamo.ReturnValuesAllNew, want: record{Partition: "A", UUID: "001", Count: 4, Price: 19.95}},
+		{returnValues: dynamo.ReturnValuesUpdatedOld, want: record{Count: 4}},
+		{returnValues: dynamo.ReturnValuesAllOld, want: record{Partition: "A", UUID: "001", Count: 5, Price: 19.95}},
+		{returnValues: dynamo.ReturnValuesNone},
+	}
+	for _, test := range tests {
+		ud := dynamo.NewUpdateExpr()
+		ud.SetPlus("count", "count", 1, true)
+		eb := dynamo.NewExprBuilder()
+		eb.SetUpdate(ud)
+		var got record
+		if err := svc.UpdateItemWithReturn(dynamo.CreateNewQueryObj("A", "001"), TableName, buildExpr(t, eb), test.returnValues, &got); err != nil {
+			t.Errorf("FAIL: %s: %v", test.returnValues, err)
+			continue
+		}
+		if got.Partition != test.want.Partition || got.Count != test.want.Count || got.Price != test.want.Price {
+			t.Errorf("FAIL - DATA: %s: %+v; want: %+v", test.returnValues, got, test.want)
+		}
+	}
+
+	// failed conditions include the current item
+	cond := dynamo.NewCondition()
+	cond.LessThan("count", 5)
+	ud := dynamo.NewUpdateExpr()
+	ud.Set("count", 0)
+	eb := dynamo.NewExprBuilder()
+	eb.SetCondition(cond)
+	eb.SetUpdate(ud)
+	err := svc.UpdateItemWithReturn(dynamo.CreateNewQueryObj("A", "001"), TableName, buildExpr(t, eb), dynamo.ReturnValuesAllNew, nil)
+	var ccf *dynamo.ConditionCheckFailedErr
+	if !errors.As(err, &ccf) {
+		t.Fatalf("FAIL: %v; want: ConditionCheckFailedErr", err)
+	}
+	current := &record{}
+	if ok, err := ccf.Item(current); !ok || err != nil || current.Count != 7 {
+		t.Errorf("FAIL - DATA: %+v, %v, %v", current, ok, err)
+	}
+}
+
+func TestUpdateItemExpressions(t *testing.T) {
+	svc, _ := New(testTable)
+	seed(t, svc)
+
+	increment := func(eb *dynamo.ExprBuilder) {
+		ud := dynamo.NewUpdateExpr()
+		ud.SetPlus("count", "count", 1, true)
+		eb.SetUpdate(ud)
+	}
+	var tests = []struct {
+		name    string
+		build   func(eb *dynamo.ExprBuilder)
+		wantErr error
+		want    int
+	}{
+		{name: "update", build: increment, want: 4},
+		{name: "condition", build: func(eb *dynamo.ExprBuilder) {
+			increment(eb)
+			cond := dynamo.NewCondition()
+			cond.Equal("count", 4)
+			eb.SetCondition(cond)
+		}, want: 5},
+		{name: "filter", build: func(eb *dynamo.ExprBuilder) {
+			increment(eb)
+			eb.SetFilter("count", 5)
+		}, wantErr: dynamo.ErrInvalidExpression},
+		{name: "projection", build: func(eb *dynamo.ExprBuilder) {
+			increment(eb)
+			eb.SetProjection([]string{"count"})
+		}, wantErr: dynamo.ErrInvalidExpression},
+		{name: "key condition", build: func(eb *dynamo.ExprBuilder) {
+			increment(eb)
+			kc := dynamo.NewKeyCondition()
+			kc.Equal("partition", "A")
+			eb.SetKeyCondition(kc)
+		}, wantErr: dynamo.ErrInvalidExpression},
+	}
+	for _, test := range tests {
+		eb := dynamo.NewExprBuilder()
+		test.build(&eb)
+		got, err := dynamo.UpdateItem[*record](svc, dynamo.CreateNewQueryObj("A", "001"), TableName, buildExpr(t, eb))
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %s: %v; want: %v", test.name, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// only updated attributes are returned
+		if got == nil || got.Count != test.want || got.UUID != "" {
+			t.Errorf("FAIL - DATA: %s: %+v; want count: %d", test.name, got, test.want)
+		}
+	}
+
+	r, err := dynamo.UpdateItemWithReturn[record](svc, dynamo.CreateNewQueryObj("A", "001"), TableName, dynamo.NewExpression(), dynamo.ReturnValuesAllNew)
+	if err != nil || r.UUID != "001" || r.Count != 5 {
+		t.Errorf("FAIL: %+v, %v", r, err)
+	}
+
+	// updates apply to updates only
+	eb := dynamo.NewExprBuilder()
+	increment(&eb)
+	if err := svc.CreateItemWithExpr(record{Partition: "A", UUID: "001"}, TableName, buildExpr(t, eb), dynamo.ReturnValuesNone, nil); !errors.Is(err, dynamo.ErrInvalidExpression) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidExpression)
+	}
+	if err := svc.DeleteItemWithExpr(dynamo.CreateNewQueryObj("A", "001"), TableName, buildExpr(t, eb), dynamo.ReturnValuesNone, nil); !errors.Is(err, dynamo.ErrInvalidExpression) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidExpression)
+	}
+}
@@ -0,0 +1,115 @@
+package dynamotest
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+type Base struct {
+	Customer string `json:"customer" dynamo:"pk"`
+}
+
+type order struct {
+	Base
+	ID      string  `json:"id" dynamo:"sk"`
+	Status  string  `json:"status" dynamo:"gsi1pk"`
+	Total   int64   `json:"total" dynamo:"gsi1sk,lsi1sk"`
+	Price   float64 `dynamodbav:"price"`
+	Comment string  `json:"-"`
+}
+
+func TestNewTableFromStruct(t *testing.T) {
+	var tests = []struct {
+		name    string
+		model   any
+		want    *dynamo.Table
+		wantErr bool
+	}{
+		{name: "order", model: &order{}, want: &dynamo.Table{
+			TableName: "orders", PrimaryKeyName: "customer", PrimaryKeyType: "S", SortKeyName: "id", SortKeyType: "S",
+			GlobalIndexes: []*dynamo.Index{{IndexName: "gsi1", PrimaryKeyName: "status", PrimaryKeyType: "S", SortKeyName: "total", SortKeyType: "N", ProjectionType: "ALL"}},
+			LocalIndexes:  []*dynamo.Index{{IndexName: "lsi1", SortKeyName: "total", SortKeyType: "N", ProjectionType: "ALL"}},
+		}},
+		{name: "hash only", model: struct {
+			ID []byte `dynamo:"pk"`
+		}{}, want: &dynamo.Table{TableName: "orders", PrimaryKeyName: "ID", PrimaryKeyType: "B"}},
+		{name: "not a struct", model: "order", wantErr: true},
+		{name: "no partition key", model: struct {
+			ID string `dynamo:"sk"`
+		}{}, wantErr: true},
+		{name: "duplicate key", model: struct {
+			A string `dynamo:"pk"`
+			B string `dynamo:"pk"`
+		}{}, wantErr: true},
+		{name: "invalid tag", model: struct {
+			A string `dynamo:"partition"`
+		}{}, wantErr: true},
+		{name: "invalid key type", model: struct {
+			A bool `dynamo:"pk"`
+		}{}, wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := dynamo.NewTableFromStruct("orders", test.model)
+		if test.wantErr != errors.Is(err, dynamo.ErrInvalidTableDefinition) {
+			t.Errorf("FAIL: %s: %v; want error: %v", test.name, err, test.wantErr)
+			continue
+		}
+		if !test.wantErr && !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FAIL - DATA: %s: %+v; want: %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestItemKeys(t *testing.T) {
+	table, err := dynamo.NewTableFromStruct("orders", order{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	svc, db := New(table)
+
+	o := order{Base: Base{Customer: "c1"}, ID: "o1", Status: "open", Total: 42, Price: 4.2}
+	if err := svc.CreateItem(o, "orders"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := svc.CreateItem(order{Base: Base{Customer: "c1"}}, "orders"); !errors.Is(err, dynamo.ErrMissingKey) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrMissingKey)
+	}
+
+	var tests = []struct {
+		key     order
+		want    order
+		wantErr error
+	}{
+		{key: order{Base: Base{Customer: "c1"}, ID: "o1"}, want: o},
+		{key: order{Base: Base{Customer: "c1"}, ID: "o2"}},
+		{key: order{ID: "o1"}, wantErr: dynamo.ErrMissingKey},
+	}
+	for _, test := range tests {
+		got, err := dynamo.GetItemByKey(svc, test.key, "orders", dynamo.NewExpression())
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %v; want: %v", err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("FAIL - DATA: %+v; want: %+v", got, test.want)
+		}
+	}
+
+	// keys may also be built from any value with the key attributes
+	key, err := table.ItemKey(map[string]string{"customer": "c1", "id": "o1"})
+	if err != nil || len(key) != 2 {
+		t.Errorf("FAIL: %v, %v", key, err)
+	}
+
+	if err := svc.DeleteItemByKey(&o, "orders"); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+	if n := len(db.Items("orders")); n != 0 {
+		t.Errorf("FAIL: %d items; want: 0", n)
+	}
+	if err := svc.DeleteItemByKey(o, "missing"); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+}
@@ -0,0 +1,285 @@
+package dynamotest
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// keySchema holds the names of a table or index's key attributes.
+type keySchema struct {
+	hash  string
+	rng   string
+	types map[string]string
+}
+
+func newKeySchema(elems []*dynamodb.KeySchemaElement, types map[string]string) (keySchema, error) {
+	ks := keySchema{types: types}
+	for _, e := range elems {
+		name := aws.StringValue(e.AttributeName)
+		if _, ok := types[name]; !ok {
+			return ks, fmt.Errorf("One or more parameter values were invalid: Some index key attributes are not defined in AttributeDefinitions. Keys: [%s]", name)
+		}
+		switch aws.StringValue(e.KeyType) {
+		case dynamodb.KeyTypeHash:
+			if ks.hash != "" {
+				return ks, fmt.Errorf("Invalid KeySchema: Too many hash keys")
+			}
+			ks.hash = name
+		case dynamodb.KeyTypeRange:
+			if ks.rng != "" {
+				return ks, fmt.Errorf("Invalid KeySchema: Too many range keys")
+			}
+			ks.rng = name
+		default:
+			return ks, fmt.Errorf("Invalid KeyType: %s", aws.StringValue(e.KeyType))
+		}
+	}
+	if ks.hash == "" {
+		return ks, fmt.Errorf("Invalid KeySchema: No hash key")
+	}
+	return ks, nil
+}
+
+// names returns the key attribute names.
+func (ks keySchema) names() []string {
+	if ks.rng == "" {
+		return []string{ks.hash}
+	}
+	return []string{ks.hash, ks.rng}
+}
+
+// hasKeys reports whether it contains every key attribute with the declared type.
+func (ks keySchema) hasKeys(it item) bool {
+	for _, n := range ks.names() {
+		if avType(it[n]) != ks.types[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// validateKey checks that key contains exactly the key attributes of the schema.
+func (ks keySchema) validateKey(key item) error {
+	if len(key) != len(ks.names()) {
+		return fmt.Errorf("The provided key element does not match the schema")
+	}
+	return ks.validateItem(key)
+}
+
+// validateItem checks that it contains valid key attributes.
+func (ks keySchema) validateItem(it item) error {
+	for _, n := range ks.names() {
+		v, ok := it[n]
+		if !ok {
+			return fmt.Errorf("One or more parameter values were invalid: Missing the key %s in the item", n)
+		}
+		if avType(v) != ks.types[n] {
+			return fmt.Errorf("One or more parameter values were invalid: Type mismatch for key %s expected: %s actual: %s", n, ks.types[n], avType(v))
+		}
+		if (v.S != nil && *v.S == "") || (v.B != nil && len(v.B) == 0) {
+			return fmt.Errorf("One or more parameter values are not valid. The AttributeValue for a key attribute cannot contain an empty string value. Key: %s", n)
+		}
+	}
+	return nil
+}
+
+// encode returns the storage key of an item.
+func (ks keySchema) encode(it item) string {
+	k := encodeKeyPart(it[ks.hash])
+	if ks.rng != "" {
+		k += "\x00" + encodeKeyPart(it[ks.rng])
+	}
+	return k
+}
+
+// less orders two items by hash key, then range key.
+func (ks keySchema) less(a, b item) bool {
+	ha, hb := encodeKeyPart(a[ks.hash]), encodeKeyPart(b[ks.hash])
+	if ha != hb {
+		return ha < hb
+	}
+	if ks.rng == "" {
+		return false
+	}
+	c, _ := compareAV(a[ks.rng], b[ks.rng])
+	return c < 0
+}
+
+// index is a global or local secondary index.
+type index struct {
+	name       string
+	keys       keySchema
+	projection *dynamodb.Projection
+	global     bool
+}
+
+// table is an in-memory table.
+type table struct {
+	input *dynamodb.CreateTableInput
+	desc  *dynamodb.TableDescription
+	keys  keySchema
+	// indexes are keyed by index name
+	indexes map[string]*index
+	// items are keyed by encoded primary key
+	items map[string]item
+	tags  []*dynamodb.Tag
+}
+
+func newTable(input *dynamodb.CreateTableInput) (*table, error) {
+	types := make(map[string]string)
+	for _, ad := range input.AttributeDefinitions {
+		at := aws.StringValue(ad.AttributeType)
+		switch at {
+		case dynamodb.ScalarAttributeTypeS, dynamodb.ScalarAttributeTypeN, dynamodb.ScalarAttributeTypeB:
+		default:
+			return nil, fmt.Errorf("1 validation error detected: Value '%s' at 'attributeDefinitions.%s.member.attributeType' failed to satisfy constraint: Member must satisfy enum value set: [B, N, S]", at, aws.StringValue(ad.AttributeName))
+		}
+		types[aws.StringValue(ad.AttributeName)] = at
+	}
+
+	keys, err := newKeySchema(input.KeySchema, types)
+	if err != nil {
+		return nil, err
+	}
+	t := &table{
+		input:   input,
+		keys:    keys,
+		indexes: make(map[string]*index),
+		items:   make(map[string]item),
+	}
+	used := map[string]bool{keys.hash: true, keys.rng: true}
+
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		ks, err := newKeySchema(gsi.KeySchema, types)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.addIndex(&index{name: aws.StringValue(gsi.IndexName), keys: ks, projection: gsi.Projection, global: true}); err != nil {
+			return nil, err
+		}
+		used[ks.hash], used[ks.rng] = true, true
+	}
+	for _, lsi := range input.LocalSecondaryIndexes {
+		ks, err := newKeySchema(lsi.KeySchema, types)
+		if err != nil {
+			return nil, err
+		}
+		if ks.hash != keys.hash || ks.rng == "" {
+			return nil, fmt.Errorf("One or more parameter values were invalid: Index KeySchema does not have the same leading hash key as table KeySchema for index: %s", aws.StringValue(lsi.IndexName))
+		}
+		if err := t.addIndex(&index{name: aws.StringValue(lsi.IndexName), keys: ks, projection: lsi.Projection}); err != nil {
+			return nil, err
+		}
+		used[ks.rng] = true
+	}
+
+	for name := range types {
+		if !used[name] {
+			return nil, fmt.Errorf("One or more parameter values were invalid: Number of attributes in KeySchema does not exactly match number of attributes defined in AttributeDefinitions")
+		}
+	}
+	return t, nil
+}
+
+func (t *table) addIndex(idx *index) error {
+	if idx.name == "" {
+		return fmt.Errorf("One or more parameter values were invalid: index name must be specified")
+	}
+	if _, ok := t.indexes[idx.name]; ok {
+		return fmt.Errorf("One or more parameter values were invalid: Duplicate index name: %s", idx.name)
+	}
+	t.indexes[idx.name] = idx
+	return nil
+}
+
+func (t *table) name() string {
+	return aws.StringValue(t.input.TableName)
+}
+
+// get returns the stored item for key, or nil.
+func (t *table) get(key item) item {
+	return t.items[t.keys.encode(key)]
+}
+
+// put stores it, replacing any existing item with the same key.
+func (t *table) put(it item) {
+	t.items[t.keys.encode(it)] = it
+}
+
+// remove deletes the item with the given key.
+func (t *table) remove(key item) {
+	delete(t.items, t.keys.encode(key))
+}
+
+// keyOf returns the primary key attributes of it.
+func (t *table) keyOf(it item) item {
+	key := make(item)
+	for _, n := range t.keys.names() {
+		key[n] = cloneAV(it[n])
+	}
+	return key
+}
+
+// sorted returns the items in index (or table, if idx is nil) order.
+// Items missing an index key attribute are excluded from the index.
+func (t *table) sorted(idx *index) []item {
+	ks := t.keys
+	if idx != nil {
+		ks = idx.keys
+	}
+	out := make([]item, 0, len(t.items))
+	for _, it := range t.items {
+		if idx != nil && !idx.keys.hasKeys(it) {
+			continue
+		}
+		out = append(out, it)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if ks.less(out[i], out[j]) {
+			return true
+		}
+		if ks.less(out[j], out[i]) {
+			return false
+		}
+		// ties in an index are broken by the table key
+		return t.keys.less(out[i], out[j])
+	})
+	return out
+}
+
+// indexKeyOf returns the key attributes that identify it within idx.
+func (t *table) indexKeyOf(idx *index, it item) item {
+	key := t.keyOf(it)
+	if idx != nil {
+		for _, n := range idx.keys.names() {
+			key[n] = cloneAV(it[n])
+		}
+	}
+	return key
+}
+
+// projectIndex returns the attributes of it that are projected into idx.
+func (t *table) projectIndex(idx *index, it item) item {
+	if idx == nil || idx.projection == nil || aws.StringValue(idx.projection.ProjectionType) == dynamodb.ProjectionTypeAll {
+		return item(cloneItem(it))
+	}
+	out := t.indexKeyOf(idx, it)
+	if aws.StringValue(idx.projection.ProjectionType) == dynamodb.ProjectionTypeInclude {
+		for _, n := range idx.projection.NonKeyAttributes {
+			if v, ok := it[aws.StringValue(n)]; ok {
+				out[aws.StringValue(n)] = cloneAV(v)
+			}
+		}
+	}
+	return out
+}
+
+// describe returns the table's description.
+func (t *table) describe() *dynamodb.TableDescription {
+	d := *t.desc
+	d.ItemCount = aws.Int64(int64(len(t.items)))
+	return &d
+}
@@ -0,0 +1,140 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+func TestDescribeAndLoadTables(t *testing.T) {
+	_, db := New()
+	users := dynamo.CreateNewTableObj("users", "id", "string", "", "")
+	users.Options = &dynamo.TableOptions{BillingMode: "PROVISIONED", ReadCapacity: 2, WriteCapacity: 1, StreamViewType: "KEYS_ONLY"}
+	indexed := indexedTable()
+	for _, table := range []*dynamo.Table{users, indexed} {
+		if err := dynamo.NewDynamoDBWithClient(db, nil, nil).CreateTable(table); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+
+	// a client created without table definitions discovers them
+	svc := dynamo.NewDynamoDBWithClient(db, nil, nil)
+	if err := svc.CreateItem(record{Partition: "A", UUID: "001"}, TableName); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+	tables, err := svc.LoadTables()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("FAIL - DATA: %d tables; want: 2", len(tables))
+	}
+	if err := svc.CreateItem(record{Partition: "A", UUID: "001"}, TableName); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+
+	var tests = []struct {
+		want *dynamo.Table
+		got  *dynamo.Table
+	}{
+		{want: indexed, got: tables[0]},
+		{want: users, got: tables[1]},
+	}
+	for _, test := range tests {
+		want := *test.want
+		want.Options = &dynamo.TableOptions{BillingMode: "PAY_PER_REQUEST"}
+		if test.want.Options != nil {
+			want.Options = test.want.Options
+		}
+		if !reflect.DeepEqual(test.got, &want) {
+			t.Errorf("FAIL - DATA: %+v; want: %+v", test.got, &want)
+		}
+	}
+
+	desc, err := svc.DescribeTable(TableName)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if desc.Status != "ACTIVE" || desc.ItemCount != 1 || desc.TableArn == "" {
+		t.Errorf("FAIL - DATA: %+v", desc)
+	}
+	if _, err := svc.DescribeTable("missing"); !errors.Is(err, dynamo.ErrResourceNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrResourceNotFound)
+	}
+	if _, err := svc.LoadTables("missing"); !errors.Is(err, dynamo.ErrResourceNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrResourceNotFound)
+	}
+}
+
+func TestLoadTablesKeepsVersioning(t *testing.T) {
+	table, err := dynamo.NewTableFromStruct("docs", doc{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	table.Options = &dynamo.TableOptions{StreamViewType: "NEW_IMAGE"}
+	svc, _ := New(table)
+
+	tables, err := svc.LoadTables("docs")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	// the version attribute is kept and the options are discovered
+	if len(tables) != 1 || tables[0].VersionAttribute != "version" || tables[0].Options == table.Options ||
+		tables[0].Options.StreamViewType != "NEW_IMAGE" || tables[0].Options.BillingMode != "PAY_PER_REQUEST" {
+		t.Fatalf("FAIL - DATA: %+v; want: version attribute of %+v", tables, table)
+	}
+
+	// writes to the reloaded table are still version checked
+	if err := svc.CreateItem(doc{ID: "d1", Body: "v1"}, "docs"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	var vce *dynamo.VersionConflictErr
+	if err := svc.CreateItem(doc{ID: "d1", Body: "stale"}, "docs"); !errors.As(err, &vce) || vce.Expected() != 0 || vce.Current() != 1 {
+		t.Errorf("FAIL: %v; want: VersionConflictErr", err)
+	}
+}
+
+func TestWaiters(t *testing.T) {
+	svc, db := New()
+	db.StatusDelay = 30 * time.Millisecond
+	ctx := context.Background()
+	const poll = 5 * time.Millisecond
+
+	if err := svc.CreateTable(indexedTable()); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	desc, err := svc.DescribeTable(TableName)
+	if err != nil || desc.Status != "CREATING" {
+		t.Fatalf("FAIL: %+v, %v", desc, err)
+	}
+
+	// a short timeout expires before the table is active
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := svc.WaitUntilActive(short, TableName, poll); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FAIL: %v; want: %v", err, context.DeadlineExceeded)
+	}
+	if err := svc.WaitUntilActive(ctx, TableName, poll); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if desc, _ := svc.DescribeTable(TableName); desc.Status != "ACTIVE" {
+		t.Errorf("FAIL - DATA: %+v", desc)
+	}
+
+	if err := svc.DeleteTable(TableName); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if desc, err := svc.DescribeTable(TableName); err != nil || desc.Status != "DELETING" {
+		t.Errorf("FAIL: %+v, %v", desc, err)
+	}
+	if err := svc.WaitUntilDeleted(ctx, TableName, poll); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if _, err := svc.DescribeTable(TableName); !errors.Is(err, dynamo.ErrResourceNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrResourceNotFound)
+	}
+}
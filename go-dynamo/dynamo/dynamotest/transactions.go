@@ -0,0 +1,276 @@
+package dynamotest
+
+// This file contains transaction operations.
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+const (
+	cancelCodeNone              = "None"
+	cancelCodeConditionalFailed = "ConditionalCheckFailed"
+	cancelCodeValidation        = "ValidationError"
+)
+
+// txToken records a ClientRequestToken seen by TransactWriteItems.
+type txToken struct {
+	items []*dynamodb.TransactWriteItem
+	at    time.Time
+}
+
+// txWrite is a validated TransactWriteItem.
+type txWrite struct {
+	t         *table
+	key       item
+	cond      condition
+	put       item
+	actions   []updateAction
+	delete    bool
+	onFailure *string
+}
+
+// TransactWriteItems applies up to 100 write actions atomically.
+// If any condition fails, no action is applied and a TransactionCanceledException
+// is returned with one cancellation reason per action.
+func (db *DB) TransactWriteItems(input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("TransactWriteItems"); err != nil {
+		return nil, err
+	}
+
+	if len(input.TransactItems) == 0 || len(input.TransactItems) > maxTransactItems {
+		return nil, validationErr("Member must have length less than or equal to %d and greater than or equal to 1", maxTransactItems)
+	}
+
+	// a successful request is not applied again if retried with the same token
+	token, now := aws.StringValue(input.ClientRequestToken), db.Now()
+	if token != "" {
+		if prev, ok := db.tokens[token]; ok && now.Sub(prev.at) < idempotencyWindow {
+			if !reflect.DeepEqual(prev.items, input.TransactItems) {
+				return nil, &dynamodb.IdempotentParameterMismatchException{
+					Message_: aws.String("The request uses the same client token as a previous, but non-identical request."),
+				}
+			}
+			return &dynamodb.TransactWriteItemsOutput{}, nil
+		}
+	}
+
+	writes := make([]*txWrite, 0, len(input.TransactItems))
+	seen := make(map[string]bool)
+	for _, ti := range input.TransactItems {
+		w, err := db.newTxWrite(ti)
+		if err != nil {
+			return nil, err
+		}
+		k := w.t.name() + "\x00" + w.t.keys.encode(w.key)
+		if seen[k] {
+			return nil, validationErr("Transaction request cannot include multiple operations on one item")
+		}
+		seen[k] = true
+		writes = append(writes, w)
+	}
+
+	// evaluate every condition and update before applying any write
+	reasons := make([]*dynamodb.CancellationReason, len(writes))
+	results := make([]item, len(writes))
+	failed := false
+	for i, w := range writes {
+		reasons[i] = &dynamodb.CancellationReason{Code: aws.String(cancelCodeNone)}
+		old := w.t.get(w.key)
+		if w.cond != nil {
+			ok, err := evalCondition(w.cond, old)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				failed = true
+				reasons[i] = &dynamodb.CancellationReason{
+					Code:    aws.String(cancelCodeConditionalFailed),
+					Message: aws.String("The conditional request failed"),
+				}
+				if aws.StringValue(w.onFailure) == dynamodb.ReturnValuesOnConditionCheckFailureAllOld && old != nil {
+					reasons[i].Item = cloneItem(old)
+				}
+				continue
+			}
+		}
+		switch {
+		case w.put != nil:
+			results[i] = item(cloneItem(w.put))
+		case w.actions != nil:
+			updated, _, err := w.t.update(w.key, old, w.actions)
+			if err != nil {
+				failed = true
+				reasons[i] = &dynamodb.CancellationReason{
+					Code:    aws.String(cancelCodeValidation),
+					Message: aws.String(err.Error()),
+				}
+				continue
+			}
+			results[i] = updated
+		}
+	}
+
+	if failed {
+		codes := make([]string, len(reasons))
+		for i, r := range reasons {
+			codes[i] = aws.StringValue(r.Code)
+		}
+		return nil, &dynamodb.TransactionCanceledException{
+			Message_:            aws.String(fmt.Sprintf("Transaction cancelled, please refer cancellation reasons for specific reasons [%s]", strings.Join(codes, ", "))),
+			CancellationReasons: reasons,
+		}
+	}
+
+	for i, w := range writes {
+		switch {
+		case results[i] != nil:
+			w.t.put(results[i])
+		case w.delete:
+			w.t.remove(w.key)
+		}
+	}
+	if token != "" {
+		db.tokens[token] = txToken{items: input.TransactItems, at: now}
+	}
+	return &dynamodb.TransactWriteItemsOutput{}, nil
+}
+
+// newTxWrite validates a TransactWriteItem. db.mu must be held.
+func (db *DB) newTxWrite(ti *dynamodb.TransactWriteItem) (*txWrite, error) {
+	var (
+		tableName, cond, update *string
+		names                   map[string]*string
+		values                  map[string]*dynamodb.AttributeValue
+		w                       = &txWrite{}
+		n                       int
+	)
+	if c := ti.ConditionCheck; c != nil {
+		n++
+		tableName, w.key, cond, names, values, w.onFailure = c.TableName, c.Key, c.ConditionExpression, c.ExpressionAttributeNames, c.ExpressionAttributeValues, c.ReturnValuesOnConditionCheckFailure
+		if cond == nil {
+			return nil, validationErr("The ConditionExpression of a ConditionCheck must be specified")
+		}
+	}
+	if p := ti.Put; p != nil {
+		n++
+		tableName, w.key, w.put, cond, names, values, w.onFailure = p.TableName, p.Item, p.Item, p.ConditionExpression, p.ExpressionAttributeNames, p.ExpressionAttributeValues, p.ReturnValuesOnConditionCheckFailure
+	}
+	if d := ti.Delete; d != nil {
+		n++
+		tableName, w.key, cond, names, values, w.onFailure = d.TableName, d.Key, d.ConditionExpression, d.ExpressionAttributeNames, d.ExpressionAttributeValues, d.ReturnValuesOnConditionCheckFailure
+		w.delete = true
+	}
+	if u := ti.Update; u != nil {
+		n++
+		tableName, w.key, cond, update, names, values, w.onFailure = u.TableName, u.Key, u.ConditionExpression, u.UpdateExpression, u.ExpressionAttributeNames, u.ExpressionAttributeValues, u.ReturnValuesOnConditionCheckFailure
+		if update == nil {
+			return nil, validationErr("The UpdateExpression of an Update must be specified")
+		}
+	}
+	if n != 1 {
+		return nil, validationErr("TransactItems can only contain one of Check, Put, Update or Delete")
+	}
+
+	t, err := db.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	w.t = t
+	if w.put != nil {
+		if err := t.validateItem(w.put); err != nil {
+			return nil, validationErr("%s", err.Error())
+		}
+		w.key = t.keyOf(w.put)
+	} else if err := t.keys.validateKey(w.key); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+
+	attrs := newExprAttrs(names, values)
+	if w.cond, err = parseOptionalCondition(cond, attrs); err != nil {
+		return nil, err
+	}
+	if update != nil {
+		if w.actions, err = parseUpdateExpr(*update, attrs); err != nil {
+			return nil, validationErr("Invalid UpdateExpression: %s", err.Error())
+		}
+	}
+	if err := attrs.checkUnused(); err != nil {
+		return nil, validationErr("%s", err.Error())
+	}
+	return w, nil
+}
+
+// TransactGetItems reads up to 100 items atomically.
+func (db *DB) TransactGetItems(input *dynamodb.TransactGetItemsInput) (*dynamodb.TransactGetItemsOutput, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if err := db.fault("TransactGetItems"); err != nil {
+		return nil, err
+	}
+
+	if len(input.TransactItems) == 0 || len(input.TransactItems) > maxTransactItems {
+		return nil, validationErr("Member must have length less than or equal to %d and greater than or equal to 1", maxTransactItems)
+	}
+
+	out := &dynamodb.TransactGetItemsOutput{Responses: make([]*dynamodb.ItemResponse, 0, len(input.TransactItems))}
+	seen := make(map[string]bool)
+	for _, ti := range input.TransactItems {
+		g := ti.Get
+		if g == nil {
+			return nil, validationErr("TransactItems can only contain Get")
+		}
+		t, err := db.table(g.TableName)
+		if err != nil {
+			return nil, err
+		}
+		if err := t.keys.validateKey(g.Key); err != nil {
+			return nil, validationErr("%s", err.Error())
+		}
+		k := t.name() + "\x00" + t.keys.encode(g.Key)
+		if seen[k] {
+			return nil, validationErr("Transaction request cannot include multiple operations on one item")
+		}
+		seen[k] = true
+
+		attrs := newExprAttrs(g.ExpressionAttributeNames, nil)
+		proj, err := parseOptionalProjection(g.ProjectionExpression, attrs)
+		if err != nil {
+			return nil, err
+		}
+		if err := attrs.checkUnused(); err != nil {
+			return nil, validationErr("%s", err.Error())
+		}
+
+		resp := &dynamodb.ItemResponse{}
+		if it := t.get(g.Key); it != nil {
+			resp.Item = applyProjection(it, proj)
+		}
+		out.Responses = append(out.Responses, resp)
+	}
+	return out, nil
+}
+
+// TransactWriteItemsWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, _ ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.TransactWriteItems(input)
+}
+
+// TransactGetItemsWithContext implements dynamodbiface.DynamoDBAPI.
+func (db *DB) TransactGetItemsWithContext(ctx aws.Context, input *dynamodb.TransactGetItemsInput, _ ...request.Option) (*dynamodb.TransactGetItemsOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, canceledErr(err)
+	}
+	return db.TransactGetItems(input)
+}
@@ -0,0 +1,332 @@
+package dynamotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+// decrementTx returns an update TransactionItem that decrements count by n if count >= n.
+func decrementTx(t *testing.T, name, pk, sk string, n int) dynamo.TransactionItem {
+	t.Helper()
+	cond := dynamo.NewCondition()
+	cond.GreaterThanEqual("count", n)
+	ud := dynamo.NewUpdateExpr()
+	ud.SetMinus("count", "count", n, true)
+	eb := dynamo.NewExprBuilder()
+	eb.SetCondition(cond)
+	eb.SetUpdate(ud)
+	expr, err := eb.BuildExpression()
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return dynamo.NewUpdateTxItem(name, testTable, dynamo.CreateNewQueryObj(pk, sk), expr)
+}
+
+func counts(db *DB) map[string]string {
+	m := make(map[string]string)
+	for _, it := range db.Items(TableName) {
+		m[aws.StringValue(it["uuid"].S)] = aws.StringValue(it["count"].N)
+	}
+	return m
+}
+
+func TestTxWriteAllOrNothing(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	// 001 has count 3 and fails its condition; nothing is applied
+	items := []dynamo.TransactionItem{
+		decrementTx(t, "t00", "A", "002", 4),
+		decrementTx(t, "t01", "A", "001", 4),
+		dynamo.NewCreateTxItem("t02", record{Partition: "D", UUID: "006"}, testTable, nil, dynamo.NewExpression()),
+	}
+	before := counts(db)
+	failed, err := svc.TxWrite(items, "tk-001")
+	if !errors.Is(err, dynamo.ErrTxConditionCheckFailed) {
+		t.Fatalf("FAIL: %v; want: %v", err, dynamo.ErrTxConditionCheckFailed)
+	}
+	if len(failed) != 1 || failed[0].Name != "t01" {
+		t.Errorf("FAIL: failed items: %v", failed)
+	}
+	after := counts(db)
+	if len(after) != len(before) || after["002"] != before["002"] {
+		t.Errorf("FAIL: partial transaction applied: %v", after)
+	}
+
+	// all conditions pass
+	items[1] = decrementTx(t, "t01", "A", "001", 3)
+	if failed, err = svc.TxWrite(items, "tk-002"); err != nil {
+		t.Fatalf("FAIL: %v\n failed: %v", err, failed)
+	}
+	after = counts(db)
+	if after["001"] != "0" || after["002"] != "1" || len(after) != 6 {
+		t.Errorf("FAIL - DATA: %v", after)
+	}
+
+	// retrying with the same token is not applied twice
+	if _, err = svc.TxWrite(items, "tk-002"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if got := counts(db); got["002"] != "1" {
+		t.Errorf("FAIL: idempotent retry applied: %v", got)
+	}
+}
+
+func TestTxWriteValidation(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	// two actions on one item
+	items := []dynamo.TransactionItem{
+		decrementTx(t, "t00", "A", "002", 1),
+		decrementTx(t, "t01", "A", "002", 1),
+	}
+	if _, err := svc.TxWrite(items, ""); err == nil {
+		t.Errorf("FAIL: duplicate item accepted")
+	}
+
+	// unknown table
+	missing := &dynamo.Table{TableName: "missing", PrimaryKeyName: "partition", PrimaryKeyType: "S", SortKeyName: "uuid", SortKeyType: "S"}
+	del := dynamo.NewDeleteTxItem("t00", missing, dynamo.CreateNewQueryObj("A", "001"), dynamo.NewExpression())
+	if _, err := svc.TxWrite([]dynamo.TransactionItem{del}, ""); err == nil {
+		t.Errorf("FAIL: unknown table accepted")
+	}
+
+	// cancellation reasons are reported per item
+	_, err := db.TransactWriteItems(&dynamodb.TransactWriteItemsInput{
+		TransactItems: []*dynamodb.TransactWriteItem{
+			{ConditionCheck: &dynamodb.ConditionCheck{
+				TableName:           aws.String(TableName),
+				Key:                 map[string]*dynamodb.AttributeValue{"partition": {S: aws.String("A")}, "uuid": {S: aws.String("001")}},
+				ConditionExpression: aws.String("attribute_not_exists(#p)"),
+				ExpressionAttributeNames: map[string]*string{
+					"#p": aws.String("partition"),
+				},
+				ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+			}},
+			{Delete: &dynamodb.Delete{
+				TableName: aws.String(TableName),
+				Key:       map[string]*dynamodb.AttributeValue{"partition": {S: aws.String("C")}, "uuid": {S: aws.String("005")}},
+			}},
+		},
+	})
+	var tce *dynamodb.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		t.Fatalf("FAIL: %v; want: TransactionCanceledException", err)
+	}
+	if len(tce.CancellationReasons) != 2 ||
+		aws.StringValue(tce.CancellationReasons[0].Code) != "ConditionalCheckFailed" ||
+		aws.StringValue(tce.CancellationReasons[1].Code) != "None" ||
+		tce.CancellationReasons[0].Item == nil {
+		t.Errorf("FAIL: reasons: %v", tce.CancellationReasons)
+	}
+	if n := len(db.Items(TableName)); n != 5 {
+		t.Errorf("FAIL: %d items; want: 5", n)
+	}
+}
+
+// tokenRecorder records the ClientRequestToken of each transaction.
+type tokenRecorder struct {
+	*DB
+	tokens []string
+}
+
+func (r *tokenRecorder) TransactWriteItemsWithContext(ctx aws.Context, input *dynamodb.TransactWriteItemsInput, opts ...request.Option) (*dynamodb.TransactWriteItemsOutput, error) {
+	r.tokens = append(r.tokens, aws.StringValue(input.ClientRequestToken))
+	return r.DB.TransactWriteItemsWithContext(ctx, input, opts...)
+}
+
+func TestTxWriteWithRetry(t *testing.T) {
+	rec := &tokenRecorder{DB: NewDB(testTable)}
+	svc := dynamo.NewDynamoDBWithClient(rec, []*dynamo.Table{testTable}, nil)
+	seed(t, svc)
+	retry := &dynamo.Backoff{Base: time.Millisecond, MaxAttempts: 3}
+
+	cancelled := func(codes ...string) error {
+		reasons := []*dynamodb.CancellationReason{}
+		for _, code := range codes {
+			reasons = append(reasons, &dynamodb.CancellationReason{Code: aws.String(code), Message: aws.String(code)})
+		}
+		return &dynamodb.TransactionCanceledException{Message_: aws.String("cancelled"), CancellationReasons: reasons}
+	}
+	var tests = []struct {
+		name         string
+		token        string
+		fail         error
+		failures     int
+		items        []dynamo.TransactionItem
+		wantErr      error
+		wantAttempts int
+		wantCancels  []string // names of cancelled items of the last attempt
+		want         map[string]string
+	}{
+		{name: "conflict", token: "tk-001", fail: cancelled("TransactionConflict", "None"), failures: 2, items: []dynamo.TransactionItem{
+			decrementTx(t, "t00", "A", "001", 1),
+			decrementTx(t, "t01", "A", "002", 1),
+		}, wantAttempts: 3, want: map[string]string{"001": "2", "002": "4"}},
+		{name: "throttled", fail: cancelled("None", "ThrottlingError"), failures: 3, items: []dynamo.TransactionItem{
+			decrementTx(t, "t00", "A", "001", 1),
+			decrementTx(t, "t01", "A", "002", 1),
+		}, wantErr: dynamo.ErrTxThrottled, wantAttempts: 3, wantCancels: []string{"t01"}, want: map[string]string{"001": "2", "002": "4"}},
+		{name: "request throttled", fail: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "throttled", nil), failures: 1, items: []dynamo.TransactionItem{
+			decrementTx(t, "t00", "A", "003", 1),
+		}, wantAttempts: 2, want: map[string]string{"003": "6"}},
+		{name: "condition", items: []dynamo.TransactionItem{
+			decrementTx(t, "t00", "A", "001", 1),
+			decrementTx(t, "t01", "A", "002", 9),
+		}, wantErr: dynamo.ErrTxConditionCheckFailed, wantAttempts: 1, wantCancels: []string{"t01"}, want: map[string]string{"001": "2", "002": "4"}},
+	}
+	for _, test := range tests {
+		rec.tokens = nil
+		if test.fail != nil {
+			rec.FailNext("TransactWriteItems", test.failures, test.fail)
+		}
+		report, err := svc.TxWriteWithRetry(context.Background(), test.items, test.token, retry)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %s: %v; want: %v", test.name, err, test.wantErr)
+			continue
+		}
+		if report.Attempts != test.wantAttempts || len(report.Cancellations) != len(test.wantCancels) {
+			t.Errorf("FAIL - DATA: %s: %+v", test.name, report)
+		}
+		for i, c := range report.Cancellations {
+			if c.Name != test.wantCancels[i] || c.Code == "" || c.Message == "" {
+				t.Errorf("FAIL - DATA: %s: cancellation %+v", test.name, c)
+			}
+		}
+		// every attempt reuses the token
+		if report.RequestToken == "" || (test.token != "" && report.RequestToken != test.token) {
+			t.Errorf("FAIL - DATA: %s: token %q", test.name, report.RequestToken)
+		}
+		for _, token := range rec.tokens {
+			if token != report.RequestToken {
+				t.Errorf("FAIL - DATA: %s: tokens %v", test.name, rec.tokens)
+				break
+			}
+		}
+		got := counts(rec.DB)
+		for uuid, count := range test.want {
+			if got[uuid] != count {
+				t.Errorf("FAIL - DATA: %s: %v; want: %v", test.name, got, test.want)
+				break
+			}
+		}
+	}
+
+	// failed conditions include the current item
+	report, _ := svc.TxWriteWithRetry(context.Background(), []dynamo.TransactionItem{decrementTx(t, "t00", "A", "002", 9)}, "", retry)
+	current := &record{}
+	if ok, err := report.Cancellations[0].Item(current); !ok || err != nil || current.Count != 4 {
+		t.Errorf("FAIL - DATA: %+v, %v, %v", current, ok, err)
+	}
+
+	// no attempt is made once the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := svc.TxWriteWithRetry(ctx, []dynamo.TransactionItem{decrementTx(t, "t00", "A", "001", 1)}, "", retry); !errors.Is(err, context.Canceled) {
+		t.Errorf("FAIL: %v; want: %v", err, context.Canceled)
+	}
+}
+
+func TestTxRead(t *testing.T) {
+	docs, err := dynamo.NewTableFromStruct("docs", doc{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	svc, _ := New(testTable, docs)
+	seed(t, svc)
+	if err := svc.CreateItem(doc{ID: "d1", Body: "one"}, "docs"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	eb := dynamo.NewExprBuilder()
+	eb.SetProjection([]string{"count"})
+	items := []dynamo.TransactionItem{
+		dynamo.NewReadTxItem("r00", testTable, dynamo.CreateNewQueryObj("B", "004"), buildExpr(t, eb)),
+		dynamo.NewReadTxItem("r01", docs, dynamo.CreateNewQueryObj("d1", nil), dynamo.NewExpression()),
+		dynamo.NewReadTxItem("r02", testTable, dynamo.CreateNewQueryObj("A", "999"), dynamo.NewExpression()),
+	}
+	r00, r01, r02 := &record{}, &doc{}, &record{}
+	notFound, err := svc.TxRead(items, map[string]interface{}{"r00": r00, "r01": r01, "r02": r02})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(notFound) != 1 || notFound[0].Name != "r02" {
+		t.Errorf("FAIL - DATA: not found: %v", notFound)
+	}
+	if r00.Count != 10 || r00.UUID != "" || r00.CountMap != nil {
+		t.Errorf("FAIL - DATA: projection not applied: %+v", r00)
+	}
+	if r01.Body != "one" || r01.Version != 1 || r02.UUID != "" {
+		t.Errorf("FAIL - DATA: %+v, %+v", r01, r02)
+	}
+
+	cond := dynamo.NewCondition()
+	cond.Equal("count", 10)
+	withCond := dynamo.NewExprBuilder()
+	withCond.SetCondition(cond)
+	var tests = []struct {
+		name    string
+		items   []dynamo.TransactionItem
+		dest    map[string]interface{}
+		wantErr error
+	}{
+		{name: "write item", items: []dynamo.TransactionItem{
+			dynamo.NewDeleteTxItem("d00", testTable, dynamo.CreateNewQueryObj("B", "004"), dynamo.NewExpression()),
+		}, dest: map[string]interface{}{"d00": &record{}}, wantErr: dynamo.ErrInvalidRequestType},
+		{name: "missing destination", items: items[:1], dest: map[string]interface{}{"r01": &doc{}}, wantErr: dynamo.ErrMissingDestination},
+		{name: "duplicate name", items: []dynamo.TransactionItem{
+			items[0],
+			dynamo.NewReadTxItem("r00", testTable, dynamo.CreateNewQueryObj("A", "001"), dynamo.NewExpression()),
+		}, dest: map[string]interface{}{"r00": &record{}}, wantErr: dynamo.ErrDuplicateName},
+		{name: "condition", items: []dynamo.TransactionItem{
+			dynamo.NewReadTxItem("r00", testTable, dynamo.CreateNewQueryObj("B", "004"), buildExpr(t, withCond)),
+		}, dest: map[string]interface{}{"r00": &record{}}, wantErr: dynamo.ErrInvalidExpression},
+	}
+	for _, test := range tests {
+		if _, err := svc.TxRead(test.items, test.dest); !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %s: %v; want: %v", test.name, err, test.wantErr)
+		}
+	}
+
+	// read items are not written
+	if _, err := svc.TxWrite(items[:1], ""); !errors.Is(err, dynamo.ErrInvalidRequestType) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidRequestType)
+	}
+}
+
+func TestTransactGetItems(t *testing.T) {
+	svc, db := New(testTable)
+	seed(t, svc)
+
+	out, err := db.TransactGetItems(&dynamodb.TransactGetItemsInput{
+		TransactItems: []*dynamodb.TransactGetItem{
+			{Get: &dynamodb.Get{
+				TableName:            aws.String(TableName),
+				Key:                  map[string]*dynamodb.AttributeValue{"partition": {S: aws.String("A")}, "uuid": {S: aws.String("001")}},
+				ProjectionExpression: aws.String("#c"),
+				ExpressionAttributeNames: map[string]*string{
+					"#c": aws.String("count"),
+				},
+			}},
+			{Get: &dynamodb.Get{
+				TableName: aws.String(TableName),
+				Key:       map[string]*dynamodb.AttributeValue{"partition": {S: aws.String("Z")}, "uuid": {S: aws.String("999")}},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(out.Responses) != 2 || len(out.Responses[0].Item) != 1 || out.Responses[1].Item != nil {
+		t.Errorf("FAIL - DATA: %v", out.Responses)
+	}
+}
@@ -0,0 +1,280 @@
+package dynamotest
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// item is a stored DynamoDB item.
+type item map[string]*dynamodb.AttributeValue
+
+// avType returns the DynamoDB data type descriptor of an AttributeValue.
+func avType(av *dynamodb.AttributeValue) string {
+	switch {
+	case av == nil:
+		return ""
+	case av.S != nil:
+		return dynamodb.ScalarAttributeTypeS
+	case av.N != nil:
+		return dynamodb.ScalarAttributeTypeN
+	case av.B != nil:
+		return dynamodb.ScalarAttributeTypeB
+	case av.BOOL != nil:
+		return "BOOL"
+	case av.NULL != nil:
+		return "NULL"
+	case av.SS != nil:
+		return "SS"
+	case av.NS != nil:
+		return "NS"
+	case av.BS != nil:
+		return "BS"
+	case av.L != nil:
+		return "L"
+	case av.M != nil:
+		return "M"
+	default:
+		return ""
+	}
+}
+
+// parseNumber parses a DynamoDB number string.
+func parseNumber(n string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(n))
+	if !ok {
+		return nil, fmt.Errorf("invalid number: %q", n)
+	}
+	return r, nil
+}
+
+// formatNumber formats a number in its shortest decimal representation.
+func formatNumber(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	f, _ := new(big.Float).SetPrec(256).SetRat(r).Float64()
+	if new(big.Rat).SetFloat64(f).Cmp(r) == 0 {
+		return fmt.Sprintf("%v", f)
+	}
+	return strings.TrimRight(strings.TrimRight(r.FloatString(38), "0"), ".")
+}
+
+// compareAV orders two scalar values of the same type (S, N or B).
+// ok is false if the values are not comparable.
+func compareAV(a, b *dynamodb.AttributeValue) (cmp int, ok bool) {
+	ta, tb := avType(a), avType(b)
+	if ta != tb {
+		return 0, false
+	}
+	switch ta {
+	case dynamodb.ScalarAttributeTypeS:
+		return strings.Compare(*a.S, *b.S), true
+	case dynamodb.ScalarAttributeTypeB:
+		return bytes.Compare(a.B, b.B), true
+	case dynamodb.ScalarAttributeTypeN:
+		ra, err := parseNumber(*a.N)
+		if err != nil {
+			return 0, false
+		}
+		rb, err := parseNumber(*b.N)
+		if err != nil {
+			return 0, false
+		}
+		return ra.Cmp(rb), true
+	default:
+		return 0, false
+	}
+}
+
+// equalAV reports whether two AttributeValues are deeply equal.
+// Sets are compared without regard to order.
+func equalAV(a, b *dynamodb.AttributeValue) bool {
+	ta, tb := avType(a), avType(b)
+	if ta != tb || ta == "" {
+		return false
+	}
+	switch ta {
+	case dynamodb.ScalarAttributeTypeS, dynamodb.ScalarAttributeTypeN, dynamodb.ScalarAttributeTypeB:
+		c, ok := compareAV(a, b)
+		return ok && c == 0
+	case "BOOL":
+		return *a.BOOL == *b.BOOL
+	case "NULL":
+		return true
+	case "SS", "NS", "BS":
+		ka, kb := setKeys(a), setKeys(b)
+		if len(ka) != len(kb) {
+			return false
+		}
+		for k := range ka {
+			if !kb[k] {
+				return false
+			}
+		}
+		return true
+	case "L":
+		if len(a.L) != len(b.L) {
+			return false
+		}
+		for i := range a.L {
+			if !equalAV(a.L[i], b.L[i]) {
+				return false
+			}
+		}
+		return true
+	case "M":
+		if len(a.M) != len(b.M) {
+			return false
+		}
+		for k, v := range a.M {
+			if !equalAV(v, b.M[k]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// setKeys returns the canonical members of a set value.
+func setKeys(av *dynamodb.AttributeValue) map[string]bool {
+	keys := make(map[string]bool)
+	switch {
+	case av.SS != nil:
+		for _, s := range av.SS {
+			keys[aws.StringValue(s)] = true
+		}
+	case av.NS != nil:
+		for _, n := range av.NS {
+			keys[canonicalNumber(aws.StringValue(n))] = true
+		}
+	case av.BS != nil:
+		for _, b := range av.BS {
+			keys[string(b)] = true
+		}
+	}
+	return keys
+}
+
+func canonicalNumber(n string) string {
+	r, err := parseNumber(n)
+	if err != nil {
+		return n
+	}
+	return r.RatString()
+}
+
+// cloneAV returns a deep copy of an AttributeValue.
+func cloneAV(av *dynamodb.AttributeValue) *dynamodb.AttributeValue {
+	if av == nil {
+		return nil
+	}
+	c := &dynamodb.AttributeValue{}
+	if av.S != nil {
+		c.S = aws.String(*av.S)
+	}
+	if av.N != nil {
+		c.N = aws.String(*av.N)
+	}
+	if av.B != nil {
+		c.B = append([]byte{}, av.B...)
+	}
+	if av.BOOL != nil {
+		c.BOOL = aws.Bool(*av.BOOL)
+	}
+	if av.NULL != nil {
+		c.NULL = aws.Bool(*av.NULL)
+	}
+	if av.SS != nil {
+		c.SS = make([]*string, len(av.SS))
+		for i, s := range av.SS {
+			c.SS[i] = aws.String(aws.StringValue(s))
+		}
+	}
+	if av.NS != nil {
+		c.NS = make([]*string, len(av.NS))
+		for i, n := range av.NS {
+			c.NS[i] = aws.String(aws.StringValue(n))
+		}
+	}
+	if av.BS != nil {
+		c.BS = make([][]byte, len(av.BS))
+		for i, b := range av.BS {
+			c.BS[i] = append([]byte{}, b...)
+		}
+	}
+	if av.L != nil {
+		c.L = make([]*dynamodb.AttributeValue, len(av.L))
+		for i, v := range av.L {
+			c.L[i] = cloneAV(v)
+		}
+	}
+	if av.M != nil {
+		c.M = cloneItem(av.M)
+	}
+	return c
+}
+
+// cloneItem returns a deep copy of an item.
+func cloneItem(m map[string]*dynamodb.AttributeValue) map[string]*dynamodb.AttributeValue {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]*dynamodb.AttributeValue, len(m))
+	for k, v := range m {
+		c[k] = cloneAV(v)
+	}
+	return c
+}
+
+// encodeKeyPart encodes a key attribute value as a string usable in a map key.
+func encodeKeyPart(av *dynamodb.AttributeValue) string {
+	switch avType(av) {
+	case dynamodb.ScalarAttributeTypeS:
+		return "S:" + *av.S
+	case dynamodb.ScalarAttributeTypeN:
+		return "N:" + canonicalNumber(*av.N)
+	case dynamodb.ScalarAttributeTypeB:
+		return "B:" + string(av.B)
+	default:
+		return ""
+	}
+}
+
+// sortedNames returns the keys of a map in sorted order.
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// itemSize approximates the size of an AttributeValue as counted by the size() function.
+func itemSize(av *dynamodb.AttributeValue) (int, bool) {
+	switch avType(av) {
+	case dynamodb.ScalarAttributeTypeS:
+		return len(*av.S), true
+	case dynamodb.ScalarAttributeTypeB:
+		return len(av.B), true
+	case "SS":
+		return len(av.SS), true
+	case "NS":
+		return len(av.NS), true
+	case "BS":
+		return len(av.BS), true
+	case "L":
+		return len(av.L), true
+	case "M":
+		return len(av.M), true
+	default:
+		return 0, false
+	}
+}
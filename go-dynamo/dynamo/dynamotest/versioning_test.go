@@ -0,0 +1,133 @@
+package dynamotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ggarcia209/go-aws/go-dynamo/dynamo"
+)
+
+type doc struct {
+	ID      string   `json:"id" dynamo:"pk"`
+	Body    string   `json:"body"`
+	Tags    []string `json:"tags,omitempty"`
+	Version int64    `json:"version" dynamo:"version"`
+}
+
+func TestVersioning(t *testing.T) {
+	table, err := dynamo.NewTableFromStruct("docs", doc{})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if table.VersionAttribute != "version" {
+		t.Fatalf("FAIL - DATA: version attribute %q", table.VersionAttribute)
+	}
+	svc, _ := New(table)
+
+	if err := svc.CreateItem(doc{ID: "d1", Body: "v1", Tags: []string{"a"}, Version: 7}, "docs"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	var vce *dynamo.VersionConflictErr
+	if err := svc.CreateItem(doc{ID: "d1"}, "docs"); !errors.As(err, &vce) || vce.Expected() != 0 || vce.Current() != 1 {
+		t.Errorf("FAIL: %v; want: VersionConflictErr", err)
+	}
+
+	setBody := func(body string) dynamo.Expression {
+		ud := dynamo.NewUpdateExpr()
+		ud.Set("body", body)
+		eb := dynamo.NewExprBuilder()
+		eb.SetUpdate(ud)
+		expr, err := eb.BuildExpression()
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		return expr
+	}
+	removeTags := func() dynamo.Expression {
+		ud := dynamo.NewUpdateExpr()
+		ud.Remove("tags")
+		cond := dynamo.NewCondition()
+		cond.AttributeExists("tags")
+		eb := dynamo.NewExprBuilder()
+		eb.SetUpdate(ud)
+		eb.SetCondition(cond)
+		expr, err := eb.BuildExpression()
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		return expr
+	}
+
+	var tests = []struct {
+		name        string
+		id          string
+		version     int64
+		expr        dynamo.Expression
+		wantErr     error
+		wantCurrent int64 // current version of a conflict
+		wantVersion int64 // version after the update
+	}{
+		{name: "set", id: "d1", version: 1, expr: setBody("v2"), wantVersion: 2},
+		{name: "stale", id: "d1", version: 1, expr: setBody("stale"), wantErr: &dynamo.VersionConflictErr{}, wantCurrent: 2, wantVersion: 2},
+		{name: "missing version", id: "d1", expr: setBody("none"), wantErr: dynamo.ErrMissingVersion, wantVersion: 2},
+		{name: "no set clause", id: "d1", version: 2, expr: removeTags(), wantVersion: 3},
+		{name: "caller condition", id: "d1", version: 3, expr: removeTags(), wantErr: &dynamo.ConditionCheckFailedErr{}, wantVersion: 3},
+		{name: "missing item", id: "d2", version: 1, expr: setBody("new"), wantErr: &dynamo.VersionConflictErr{}},
+	}
+	for _, test := range tests {
+		q := dynamo.CreateNewQueryObj(test.id, nil)
+		q.Version = test.version
+		err := svc.UpdateItem(q, "docs", test.expr)
+		switch want := test.wantErr.(type) {
+		case nil:
+			if err != nil {
+				t.Errorf("FAIL: %s: %v", test.name, err)
+			}
+		case *dynamo.VersionConflictErr:
+			if !errors.As(err, &vce) || vce.Expected() != test.version || vce.Current() != test.wantCurrent {
+				t.Errorf("FAIL: %s: %v; want: VersionConflictErr", test.name, err)
+			}
+		case *dynamo.ConditionCheckFailedErr:
+			if !errors.As(err, &want) || errors.As(err, &vce) {
+				t.Errorf("FAIL: %s: %v; want: ConditionCheckFailedErr", test.name, err)
+			}
+		default:
+			if !errors.Is(err, want) {
+				t.Errorf("FAIL: %s: %v; want: %v", test.name, err, want)
+			}
+		}
+
+		got, err := dynamo.GetItemByKey(svc, doc{ID: test.id}, "docs", dynamo.NewExpression())
+		if err != nil || got.Version != test.wantVersion {
+			t.Errorf("FAIL - DATA: %s: %+v, %v; want version: %d", test.name, got, err, test.wantVersion)
+		}
+	}
+
+	stale := dynamo.CreateNewQueryObj("d1", nil)
+	stale.Version = 2
+	if err := svc.DeleteItem(stale, "docs"); !errors.As(err, &vce) || vce.Current() != 3 {
+		t.Errorf("FAIL: %v; want: VersionConflictErr", err)
+	}
+	if err := svc.DeleteItemByKey(doc{ID: "d1"}, "docs"); !errors.Is(err, dynamo.ErrMissingVersion) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrMissingVersion)
+	}
+	current, _ := dynamo.GetItemByKey(svc, doc{ID: "d1"}, "docs", dynamo.NewExpression())
+	if err := svc.DeleteItemByKey(current, "docs"); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+}
+
+func TestVersionAttributeValidation(t *testing.T) {
+	table := dynamo.CreateNewTableObj("docs", "id", "string", "", "")
+	table.VersionAttribute = "id"
+	if err := table.Validate(); !errors.Is(err, dynamo.ErrInvalidTableDefinition) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidTableDefinition)
+	}
+	_, err := dynamo.NewTableFromStruct("docs", struct {
+		ID      string `dynamo:"pk"`
+		Version string `dynamo:"version"`
+	}{})
+	if !errors.Is(err, dynamo.ErrInvalidTableDefinition) {
+		t.Errorf("FAIL: %v; want: %v", err, dynamo.ErrInvalidTableDefinition)
+	}
+}
@@ -6,13 +6,29 @@
 package dynamo
 
 import (
+	"fmt"
+	"math"
+	"reflect"
 	"strconv"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 const ErrConditionalCheck = "ERR_CONDITIONAL_CHECK"
 
+// ReturnValues settings for single item writes. Puts and deletes support
+// ReturnValuesNone and ReturnValuesAllOld only.
+const (
+	ReturnValuesNone       = dynamodb.ReturnValueNone
+	ReturnValuesAllOld     = dynamodb.ReturnValueAllOld
+	ReturnValuesUpdatedOld = dynamodb.ReturnValueUpdatedOld
+	ReturnValuesAllNew     = dynamodb.ReturnValueAllNew
+	ReturnValuesUpdatedNew = dynamodb.ReturnValueUpdatedNew
+)
+
 // Table represents a table and holds basic information about it.
 // This object is used to access the Dynamo Table requested for each CRUD op.
 type Table struct {
@@ -21,6 +37,164 @@ type Table struct {
 	PrimaryKeyType string
 	SortKeyName    string
 	SortKeyType    string
+	// GlobalIndexes and LocalIndexes are the table's secondary indexes.
+	GlobalIndexes []*Index
+	LocalIndexes  []*Index
+	// Options are the settings used by CreateTable. Defaults are used if nil.
+	Options *TableOptions
+	// VersionAttribute enables optimistic locking when set. It names the numeric
+	// attribute holding each item's version; see versioning.go.
+	VersionAttribute string
+}
+
+// TableOptions holds optional table settings applied by CreateTable.
+type TableOptions struct {
+	// BillingMode is "PAY_PER_REQUEST" or "PROVISIONED". Defaults to "PAY_PER_REQUEST".
+	BillingMode string
+	// ReadCapacity and WriteCapacity are the provisioned capacity units of the table
+	// and each of its global indexes. Required in "PROVISIONED" billing mode.
+	ReadCapacity  int64
+	WriteCapacity int64
+	// StreamViewType enables a stream with the given view type, such as "NEW_AND_OLD_IMAGES".
+	// Streams are disabled if empty.
+	StreamViewType string
+	// SSEEnabled enables server-side encryption with an AWS managed KMS key,
+	// or with KMSKeyID if set.
+	SSEEnabled bool
+	KMSKeyID   string
+	// TableClass is "STANDARD" or "STANDARD_INFREQUENT_ACCESS". Defaults to "STANDARD".
+	TableClass         string
+	DeletionProtection bool
+	Tags               map[string]string
+}
+
+// billingMode returns the billing mode, or the default if not set.
+func (o *TableOptions) billingMode() string {
+	if o == nil || o.BillingMode == "" {
+		return dynamodb.BillingModePayPerRequest
+	}
+	return o.BillingMode
+}
+
+// Index represents a global or local secondary index of a Table.
+// Local indexes share the table's Partition Key; if PrimaryKeyName is empty
+// the table's Partition Key is used.
+type Index struct {
+	IndexName      string
+	PrimaryKeyName string
+	PrimaryKeyType string
+	SortKeyName    string
+	SortKeyType    string
+	// ProjectionType is one of "ALL", "KEYS_ONLY" or "INCLUDE". Defaults to "ALL".
+	ProjectionType string
+	// NonKeyAttributes lists the attributes projected into an "INCLUDE" index.
+	NonKeyAttributes []string
+}
+
+// Validate checks that the Table's key and index definitions are consistent.
+// Tables without a Sort Key must leave both SortKeyName and SortKeyType empty.
+// Returns an error wrapping ErrInvalidTableDefinition if the definition is invalid.
+func (t *Table) Validate() error {
+	if t.TableName == "" {
+		return fmt.Errorf("%w: table name is required", ErrInvalidTableDefinition)
+	}
+	types := make(map[string]string)
+	define := func(name, typ string) error {
+		if !isKeyType(typ) {
+			return fmt.Errorf("%w: table %s: key %s has type %q; want S, N or B", ErrInvalidTableDefinition, t.TableName, name, typ)
+		}
+		if prev, ok := types[name]; ok && prev != typ {
+			return fmt.Errorf("%w: table %s: key %s is defined as both %s and %s", ErrInvalidTableDefinition, t.TableName, name, prev, typ)
+		}
+		types[name] = typ
+		return nil
+	}
+
+	if err := validateKeys(t.TableName, t.PrimaryKeyName, t.SortKeyName, t.SortKeyType, define); err != nil {
+		return err
+	}
+	if err := define(t.PrimaryKeyName, t.PrimaryKeyType); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for _, idx := range append(append([]*Index{}, t.GlobalIndexes...), t.LocalIndexes...) {
+		if idx.IndexName == "" || names[idx.IndexName] {
+			return fmt.Errorf("%w: table %s: index names must be unique and not empty", ErrInvalidTableDefinition, t.TableName)
+		}
+		names[idx.IndexName] = true
+		switch idx.ProjectionType {
+		case "", dynamodb.ProjectionTypeAll, dynamodb.ProjectionTypeKeysOnly:
+			if len(idx.NonKeyAttributes) > 0 {
+				return fmt.Errorf("%w: index %s: non-key attributes require projection type INCLUDE", ErrInvalidTableDefinition, idx.IndexName)
+			}
+		case dynamodb.ProjectionTypeInclude:
+		default:
+			return fmt.Errorf("%w: index %s: invalid projection type %s", ErrInvalidTableDefinition, idx.IndexName, idx.ProjectionType)
+		}
+	}
+	for _, idx := range t.GlobalIndexes {
+		if err := validateKeys(idx.IndexName, idx.PrimaryKeyName, idx.SortKeyName, idx.SortKeyType, define); err != nil {
+			return err
+		}
+		if err := define(idx.PrimaryKeyName, idx.PrimaryKeyType); err != nil {
+			return err
+		}
+	}
+	for _, idx := range t.LocalIndexes {
+		if t.SortKeyName == "" {
+			return fmt.Errorf("%w: index %s: local indexes require a table with a sort key", ErrInvalidTableDefinition, idx.IndexName)
+		}
+		if idx.PrimaryKeyName != "" && idx.PrimaryKeyName != t.PrimaryKeyName {
+			return fmt.Errorf("%w: index %s: local indexes must use the table's partition key", ErrInvalidTableDefinition, idx.IndexName)
+		}
+		if idx.SortKeyName == "" {
+			return fmt.Errorf("%w: index %s: local indexes require a sort key", ErrInvalidTableDefinition, idx.IndexName)
+		}
+		if err := validateKeys(idx.IndexName, t.PrimaryKeyName, idx.SortKeyName, idx.SortKeyType, define); err != nil {
+			return err
+		}
+	}
+	if t.VersionAttribute != "" && (t.VersionAttribute == t.PrimaryKeyName || t.VersionAttribute == t.SortKeyName) {
+		return fmt.Errorf("%w: table %s: version attribute %s cannot be a key", ErrInvalidTableDefinition, t.TableName, t.VersionAttribute)
+	}
+	return nil
+}
+
+// validateKeys checks the key names of a table or index and defines its sort key.
+func validateKeys(name, pKeyName, sKeyName, sType string, define func(name, typ string) error) error {
+	if pKeyName == "" {
+		return fmt.Errorf("%w: %s: partition key name is required", ErrInvalidTableDefinition, name)
+	}
+	if sKeyName == "" {
+		if sType != "" {
+			return fmt.Errorf("%w: %s: sort key type %s has no sort key name", ErrInvalidTableDefinition, name, sType)
+		}
+		return nil
+	}
+	if sKeyName == pKeyName {
+		return fmt.Errorf("%w: %s: partition and sort keys must be different attributes", ErrInvalidTableDefinition, name)
+	}
+	return define(sKeyName, sType)
+}
+
+// isKeyType reports whether typ is a valid key attribute type.
+func isKeyType(typ string) bool {
+	switch typ {
+	case dynamodb.ScalarAttributeTypeS, dynamodb.ScalarAttributeTypeN, dynamodb.ScalarAttributeTypeB:
+		return true
+	}
+	return false
+}
+
+// index returns the named global or local index, or nil if it is not defined.
+func (t *Table) index(name string) *Index {
+	for _, idx := range append(append([]*Index{}, t.GlobalIndexes...), t.LocalIndexes...) {
+		if idx.IndexName == name {
+			return idx
+		}
+	}
+	return nil
 }
 
 // Query holds the search values for both the Partition and Sort Keys.
@@ -31,6 +205,9 @@ type Query struct {
 	UpdateFieldName string
 	UpdateExprKey   string
 	UpdateValue     interface{}
+	// Version is the expected version of the item when updating or deleting
+	// items in a table with a VersionAttribute.
+	Version int64
 }
 
 // New creates a new query by setting the Partition Key and Sort Key values.
@@ -49,29 +226,50 @@ func (q *Query) UpdateNew(pv, sv, fieldName string, value interface{}) {
 // Reset clears all fields.
 func (q *Query) Reset() {
 	q.PrimaryValue, q.SortValue, q.UpdateValue, q.UpdateExprKey, q.UpdateFieldName = nil, nil, nil, "", ""
+	q.Version = 0
 }
 
 // CreateNewTableObj creates a new Table struct.
 // The Table's key's Go types must be declared as strings.
+// Tables without a Sort Key are created by passing empty sKeyName and sType.
 // ex: t := CreateNewTableObj("my_table", "Year", "int", "MovieName", "string")
+// ex: t := CreateNewTableObj("my_table", "ID", "string", "", "")
 func CreateNewTableObj(tableName, pKeyName, pType, sKeyName, sType string) *Table {
-	typeMap := map[string]string{
-		"[]byte":   "B",
-		"[][]byte": "BS",
-		"bool":     "BOOL",
-		"list":     "L",
-		"map":      "M",
-		"int":      "N",
-		"[]int":    "NS",
-		"null":     "NULL",
-		"string":   "S",
-		"[]string": "SS",
+	return &Table{
+		TableName:      tableName,
+		PrimaryKeyName: pKeyName,
+		PrimaryKeyType: typeMap[pType],
+		SortKeyName:    sKeyName,
+		SortKeyType:    typeMap[sType],
 	}
+}
 
-	pt := typeMap[pType]
-	st := typeMap[sType]
+// CreateNewIndexObj creates a new Index struct projecting all attributes.
+// The Index's key's Go types must be declared as strings, as in CreateNewTableObj.
+// ex: idx := CreateNewIndexObj("gsi1", "Genre", "string", "Year", "int")
+func CreateNewIndexObj(indexName, pKeyName, pType, sKeyName, sType string) *Index {
+	return &Index{
+		IndexName:      indexName,
+		PrimaryKeyName: pKeyName,
+		PrimaryKeyType: typeMap[pType],
+		SortKeyName:    sKeyName,
+		SortKeyType:    typeMap[sType],
+		ProjectionType: dynamodb.ProjectionTypeAll,
+	}
+}
 
-	return &Table{tableName, pKeyName, pt, sKeyName, st}
+// typeMap maps Go type names to DynamoDB attribute types.
+var typeMap = map[string]string{
+	"[]byte":   "B",
+	"[][]byte": "BS",
+	"bool":     "BOOL",
+	"list":     "L",
+	"map":      "M",
+	"int":      "N",
+	"[]int":    "NS",
+	"null":     "NULL",
+	"string":   "S",
+	"[]string": "SS",
 }
 
 // CreateNewQueryObj creates a new Query struct.
@@ -80,78 +278,156 @@ func CreateNewQueryObj(pval, sval interface{}) *Query {
 	return &Query{PrimaryValue: pval, SortValue: sval}
 }
 
-func createAV(val interface{}) *dynamodb.AttributeValue {
-	if val == nil { // setNull
-		av := &dynamodb.AttributeValue{}
-		av.SetNULL(true)
-		return av
+// createAV converts a Go value into a DynamoDB Attribute Value.
+//
+// Numbers of any kind are converted to N, []byte to B, strings to S and bools
+// to BOOL. Slices of numbers, strings and []byte are converted to the NS, SS and
+// BS set types. time.Time values are converted to RFC3339 strings; use
+// dynamodbattribute.UnixTime for epoch seconds. Values implementing
+// dynamodbattribute.Marshaler marshal themselves, and other slices, arrays, maps
+// and structs are marshalled with dynamodbattribute. nil and nil pointers are
+// converted to NULL.
+// Returns ErrUnsupportedType for values that cannot be converted, such as
+// channels and funcs, for NaN and infinite floats, and for empty sets.
+func createAV(val interface{}) (*dynamodb.AttributeValue, error) {
+	rv := reflect.ValueOf(val)
+	if val == nil || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return (&dynamodb.AttributeValue{}).SetNULL(true), nil
 	}
-	if _, ok := val.([]byte); ok {
+
+	switch v := val.(type) {
+	case *dynamodb.AttributeValue:
+		return v, nil
+	case dynamodbattribute.Marshaler:
 		av := &dynamodb.AttributeValue{}
-		av.SetB(val.([]byte))
-		return av
+		if err := v.MarshalDynamoDBAttributeValue(av); err != nil {
+			return nil, fmt.Errorf("MarshalDynamoDBAttributeValue: %w", err)
+		}
+		return av, nil
+	case time.Time:
+		return (&dynamodb.AttributeValue{}).SetS(v.Format(time.RFC3339Nano)), nil
+	case []*dynamodb.AttributeValue:
+		return (&dynamodb.AttributeValue{}).SetL(v), nil
+	case map[string]*dynamodb.AttributeValue:
+		return (&dynamodb.AttributeValue{}).SetM(v), nil
 	}
-	if _, ok := val.(bool); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetBOOL(val.(bool))
-		return av
+
+	switch rv.Kind() {
+	case reflect.Ptr:
+		return createAV(rv.Elem().Interface())
+	case reflect.String:
+		return (&dynamodb.AttributeValue{}).SetS(rv.String()), nil
+	case reflect.Bool:
+		return (&dynamodb.AttributeValue{}).SetBOOL(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return (&dynamodb.AttributeValue{}).SetN(formatNumber(rv)), nil
+	case reflect.Float32, reflect.Float64:
+		if err := checkFinite(rv); err != nil {
+			return nil, err
+		}
+		return (&dynamodb.AttributeValue{}).SetN(formatNumber(rv)), nil
+	case reflect.Slice:
+		return createSliceAV(rv)
+	case reflect.Array, reflect.Map, reflect.Struct:
+		av, err := dynamodbattribute.Marshal(val)
+		if err != nil {
+			return nil, fmt.Errorf("dynamodbattribute.Marshal: %w", err)
+		}
+		return av, nil
 	}
-	if _, ok := val.([][]byte); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetBS(val.([][]byte))
-		return av
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedType, val)
+}
+
+// createSliceAV converts a slice into a binary value, a set or a list.
+func createSliceAV(rv reflect.Value) (*dynamodb.AttributeValue, error) {
+	elem := rv.Type().Elem()
+	if elem.Kind() == reflect.Uint8 {
+		return (&dynamodb.AttributeValue{}).SetB(rv.Bytes()), nil
 	}
-	if _, ok := val.([]*dynamodb.AttributeValue); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetL(val.([]*dynamodb.AttributeValue))
-		return av
+
+	isSet := true
+	switch elem.Kind() {
+	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+	case reflect.Slice:
+		isSet = elem.Elem().Kind() == reflect.Uint8
+	default:
+		isSet = false
 	}
-	if _, ok := val.(map[string]*dynamodb.AttributeValue); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetM(val.(map[string]*dynamodb.AttributeValue))
-		return av
+	if !isSet || elem.Implements(marshalerType) {
+		av, err := dynamodbattribute.Marshal(rv.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("dynamodbattribute.Marshal: %w", err)
+		}
+		return av, nil
 	}
-
-	if _, ok := val.(int); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetN(strconv.Itoa(val.(int)))
-		return av
+	if rv.Len() == 0 {
+		return nil, fmt.Errorf("%w: empty set %s", ErrUnsupportedType, rv.Type())
 	}
-	if _, ok := val.([]int); ok {
-		av := &dynamodb.AttributeValue{}
 
-		ns := func(is []int) []*string {
-			list := []*string{}
-			for _, n := range is {
-				str := strconv.Itoa(n)
-				list = append(list, &str)
+	av := &dynamodb.AttributeValue{}
+	for i := 0; i < rv.Len(); i++ {
+		e := rv.Index(i)
+		switch elem.Kind() {
+		case reflect.String:
+			av.SS = append(av.SS, aws.String(e.String()))
+		case reflect.Slice:
+			av.BS = append(av.BS, e.Bytes())
+		case reflect.Float32, reflect.Float64:
+			if err := checkFinite(e); err != nil {
+				return nil, err
 			}
-			return list
-		}(val.([]int))
-
-		av.SetNS(ns)
-		return av
-	}
-	if _, ok := val.(string); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetS(val.(string))
-		return av
+			av.NS = append(av.NS, aws.String(formatNumber(e)))
+		default:
+			av.NS = append(av.NS, aws.String(formatNumber(e)))
+		}
 	}
-	if _, ok := val.(string); ok {
-		av := &dynamodb.AttributeValue{}
-		av.SetS(val.(string))
-		return av
+	return av, nil
+}
+
+// marshalerType is the type of the dynamodbattribute.Marshaler interface.
+var marshalerType = reflect.TypeOf((*dynamodbattribute.Marshaler)(nil)).Elem()
+
+// checkFinite returns ErrUnsupportedType if the float rv is NaN or infinite,
+// which are not valid DynamoDB numbers.
+func checkFinite(rv reflect.Value) error {
+	if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, f)
 	}
 	return nil
 }
 
+// formatNumber formats a numeric value as a DynamoDB number string.
+func formatNumber(rv reflect.Value) string {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32)
+	}
+	return strconv.FormatFloat(rv.Float(), 'f', -1, 64)
+}
+
 // keyMaker creates a map of Partition and Sort Keys.
-func keyMaker(q *Query, t *Table) map[string]*dynamodb.AttributeValue {
+// Returns ErrMissingKey if q has no value for a key attribute.
+func keyMaker(q *Query, t *Table) (map[string]*dynamodb.AttributeValue, error) {
 	keys := make(map[string]*dynamodb.AttributeValue)
-	keys[t.PrimaryKeyName] = createAV(q.PrimaryValue)
+	pk, err := createAV(q.PrimaryValue)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", t.PrimaryKeyName, err)
+	}
+	keys[t.PrimaryKeyName] = pk
 	if t.SortKeyName == "" {
-		return keys
+		return t.keysOf(keys)
+	}
+	sk, err := createAV(q.SortValue)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", t.SortKeyName, err)
 	}
-	keys[t.SortKeyName] = createAV(q.SortValue)
-	return keys
+	keys[t.SortKeyName] = sk
+	return t.keysOf(keys)
 }
@@ -0,0 +1,107 @@
+package dynamo
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+type status string
+
+type version int
+
+// MarshalDynamoDBAttributeValue stores versions as "v<n>" strings.
+func (v version) MarshalDynamoDBAttributeValue(av *dynamodb.AttributeValue) error {
+	av.SetS("v" + formatNumber(reflect.ValueOf(int(v))))
+	return nil
+}
+
+func TestCreateAV(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	str := "abc"
+	var nilPtr *int
+	var tests = []struct {
+		val     interface{}
+		want    *dynamodb.AttributeValue
+		wantErr error
+	}{
+		{val: nil, want: &dynamodb.AttributeValue{NULL: aws.Bool(true)}},
+		{val: nilPtr, want: &dynamodb.AttributeValue{NULL: aws.Bool(true)}},
+		{val: "abc", want: &dynamodb.AttributeValue{S: aws.String("abc")}},
+		{val: &str, want: &dynamodb.AttributeValue{S: aws.String("abc")}},
+		{val: status("open"), want: &dynamodb.AttributeValue{S: aws.String("open")}},
+		{val: true, want: &dynamodb.AttributeValue{BOOL: aws.Bool(true)}},
+		{val: 7, want: &dynamodb.AttributeValue{N: aws.String("7")}},
+		{val: int8(-8), want: &dynamodb.AttributeValue{N: aws.String("-8")}},
+		{val: int64(1 << 40), want: &dynamodb.AttributeValue{N: aws.String("1099511627776")}},
+		{val: uint(9), want: &dynamodb.AttributeValue{N: aws.String("9")}},
+		{val: uint64(1 << 63), want: &dynamodb.AttributeValue{N: aws.String("9223372036854775808")}},
+		{val: float32(1.5), want: &dynamodb.AttributeValue{N: aws.String("1.5")}},
+		{val: 0.1, want: &dynamodb.AttributeValue{N: aws.String("0.1")}},
+		{val: []byte("ab"), want: &dynamodb.AttributeValue{B: []byte("ab")}},
+		{val: [][]byte{[]byte("a")}, want: &dynamodb.AttributeValue{BS: [][]byte{[]byte("a")}}},
+		{val: []int{1, 2}, want: &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"1", "2"})}},
+		{val: []float64{0.5}, want: &dynamodb.AttributeValue{NS: aws.StringSlice([]string{"0.5"})}},
+		{val: []string{"a", "b"}, want: &dynamodb.AttributeValue{SS: aws.StringSlice([]string{"a", "b"})}},
+		{val: []string{}, wantErr: ErrUnsupportedType},
+		{val: []bool{true}, want: &dynamodb.AttributeValue{L: []*dynamodb.AttributeValue{{BOOL: aws.Bool(true)}}}},
+		{val: ts, want: &dynamodb.AttributeValue{S: aws.String("2024-03-01T12:30:00Z")}},
+		{val: dynamodbattribute.UnixTime(ts), want: &dynamodb.AttributeValue{N: aws.String("1709296200")}},
+		{val: version(3), want: &dynamodb.AttributeValue{S: aws.String("v3")}},
+		{val: map[string]int{"a": 1}, want: &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{"a": {N: aws.String("1")}}}},
+		{val: struct {
+			Name string `json:"name"`
+		}{Name: "x"}, want: &dynamodb.AttributeValue{M: map[string]*dynamodb.AttributeValue{"name": {S: aws.String("x")}}}},
+		{val: &dynamodb.AttributeValue{S: aws.String("raw")}, want: &dynamodb.AttributeValue{S: aws.String("raw")}},
+		{val: make(chan int), wantErr: ErrUnsupportedType},
+		{val: func() {}, wantErr: ErrUnsupportedType},
+		{val: complex(1, 2), wantErr: ErrUnsupportedType},
+		{val: math.NaN(), wantErr: ErrUnsupportedType},
+		{val: float32(math.Inf(1)), wantErr: ErrUnsupportedType},
+		{val: []float64{1, math.Inf(-1)}, wantErr: ErrUnsupportedType},
+	}
+	for _, test := range tests {
+		got, err := createAV(test.val)
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("FAIL: %T: %v; want: %v", test.val, err, test.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("FAIL - DATA: %T: %v; want: %v", test.val, got, test.want)
+		}
+	}
+}
+
+func TestKeyMaker(t *testing.T) {
+	table := CreateNewTableObj(TABLE, "partition", "string", "ts", "int")
+	keys, err := keyMaker(CreateNewQueryObj("A", int64(1700000000)), table)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if aws.StringValue(keys["ts"].N) != "1700000000" {
+		t.Errorf("FAIL - DATA: %v", keys)
+	}
+	if _, err := keyMaker(CreateNewQueryObj("A", make(chan int)), table); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("FAIL: %v; want: %v", err, ErrUnsupportedType)
+	}
+	var missing *string
+	for _, q := range []*Query{CreateNewQueryObj(nil, int64(1)), CreateNewQueryObj("A", missing)} {
+		if _, err := keyMaker(q, table); !errors.Is(err, ErrMissingKey) {
+			t.Errorf("FAIL: %+v: %v; want: %v", q, err, ErrMissingKey)
+		}
+	}
+}
+
+func TestQueryReset(t *testing.T) {
+	q := &Query{PrimaryValue: "A", SortValue: "001", UpdateFieldName: "count", UpdateExprKey: ":c", UpdateValue: 1, Version: 3}
+	q.Reset()
+	if !reflect.DeepEqual(q, &Query{}) {
+		t.Errorf("FAIL - DATA: %+v", q)
+	}
+}
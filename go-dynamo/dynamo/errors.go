@@ -3,6 +3,9 @@ package dynamo
 import (
 	"errors"
 	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 var (
@@ -26,6 +29,31 @@ var (
 	ErrCollectionSizeExceeded = errors.New("collection size exceeded")
 	ErrReferenceObjectsCount  = errors.New("number of reference objects does not match number of queries")
 	ErrResourceInUse          = errors.New("resource in use")
+	// ErrInvalidTableDefinition is returned when a Table's keys or indexes are inconsistent.
+	ErrInvalidTableDefinition = errors.New("invalid table definition")
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrMissingKey is returned when an item has no value for a key attribute.
+	ErrMissingKey = errors.New("missing key attribute")
+	// ErrUnsupportedType is returned when a Go value cannot be converted to an Attribute Value.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrMissingVersion is returned when updating or deleting an item in a versioned
+	// table without its expected version.
+	ErrMissingVersion = errors.New("missing expected version")
+	// ErrInvalidExpression is returned when an Expression has expression kinds
+	// that do not apply to the operation.
+	ErrInvalidExpression = errors.New("invalid expression")
+	// ErrDuplicateKey is returned for items of a bulk write that repeat the key of an earlier item.
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrRetriesExhausted is returned by a Retrier when no more attempts may be made.
+	ErrRetriesExhausted = errors.New("retries exhausted")
+	// ErrMissingDestination is returned by TxRead when a TransactionItem has no destination.
+	ErrMissingDestination = errors.New("missing destination")
+	// ErrDuplicateName is returned by TxRead when TransactionItems share a Name.
+	ErrDuplicateName = errors.New("duplicate transaction item name")
+	// ErrVersionedTable is returned by batch writes to a table with a VersionAttribute,
+	// as batch writes cannot check or increment item versions.
+	ErrVersionedTable = errors.New("batch writes to versioned tables are not supported")
 )
 
 type TableNotFoundErr struct {
@@ -40,14 +68,63 @@ func NewTableNotFoundErr(tableName string) *TableNotFoundErr {
 	return &TableNotFoundErr{tableName: tableName}
 }
 
+type IndexNotFoundErr struct {
+	tableName string
+	indexName string
+}
+
+func (e *IndexNotFoundErr) Error() string {
+	return fmt.Sprintf("index %s not found on table %s", e.indexName, e.tableName)
+}
+
+func NewIndexNotFoundErr(tableName, indexName string) *IndexNotFoundErr {
+	return &IndexNotFoundErr{tableName: tableName, indexName: indexName}
+}
+
 type ConditionCheckFailedErr struct {
-	msg string
+	msg  string
+	item map[string]*dynamodb.AttributeValue
 }
 
 func (e *ConditionCheckFailedErr) Error() string {
 	return fmt.Sprintf("condition check failed: %s", e.msg)
 }
 
+// Item unmarshals the item's attributes at the time of the failed check into out.
+// Returns false if the item did not exist or its attributes were not returned.
+func (e *ConditionCheckFailedErr) Item(out interface{}) (bool, error) {
+	if len(e.item) == 0 {
+		return false, nil
+	}
+	if err := dynamodbattribute.UnmarshalMap(e.item, out); err != nil {
+		return false, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+	}
+	return true, nil
+}
+
 func NewConditionCheckFailedErr(msg string) *ConditionCheckFailedErr {
 	return &ConditionCheckFailedErr{msg: msg}
 }
+
+// VersionConflictErr is returned when a write to a table with a VersionAttribute fails
+// because the item's current version is not the expected version. The expected version
+// of a new item is 0, and the current version of an item that does not exist is 0.
+type VersionConflictErr struct {
+	tableName string
+	expected  int64
+	current   int64
+}
+
+func (e *VersionConflictErr) Error() string {
+	return fmt.Sprintf("version conflict on table %s: expected version %d, found %d", e.tableName, e.expected, e.current)
+}
+
+// Expected returns the version the write expected.
+func (e *VersionConflictErr) Expected() int64 { return e.expected }
+
+// Current returns the item's version at the time of the write.
+func (e *VersionConflictErr) Current() int64 { return e.current }
+
+func NewVersionConflictErr(tableName string, expected, current int64) *VersionConflictErr {
+	return &VersionConflictErr{tableName: tableName, expected: expected, current: current}
+}
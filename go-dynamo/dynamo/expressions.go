@@ -1,8 +1,11 @@
 package dynamo
 
 import (
+	"fmt"
 	"log"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 
 	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
@@ -428,3 +431,88 @@ func (c *Conditions) Or(left, right Conditions, other ...Conditions) {
 	}
 	c.Condition = condition
 }
+
+/* writeExpr internal type & methods */
+
+// checkWriteExpr returns ErrInvalidExpression if expr has expression kinds that
+// do not apply to single item writes. Update expressions apply to updates only.
+func checkWriteExpr(expr Expression, update bool) error {
+	if expr.Filter() != nil || expr.KeyCondition() != nil || expr.Projection() != nil {
+		return fmt.Errorf("%w: writes accept condition and update expressions only", ErrInvalidExpression)
+	}
+	if !update && expr.Update() != nil {
+		return fmt.Errorf("%w: update expressions apply to updates only", ErrInvalidExpression)
+	}
+	return nil
+}
+
+// writeExpr holds the expressions of a single item write, so that
+// checks such as version checks can be added to the caller's expression.
+type writeExpr struct {
+	condition *string
+	update    *string
+	names     map[string]*string
+	values    map[string]*dynamodb.AttributeValue
+}
+
+// newWriteExpr copies the condition and update expressions of expr.
+func newWriteExpr(expr Expression) *writeExpr {
+	w := &writeExpr{condition: expr.Condition(), update: expr.Update()}
+	for k, v := range expr.Names() {
+		w.name(k, aws.StringValue(v))
+	}
+	for k, v := range expr.Values() {
+		w.value(k, v)
+	}
+	return w
+}
+
+func (w *writeExpr) name(placeholder, name string) {
+	if w.names == nil {
+		w.names = make(map[string]*string)
+	}
+	w.names[placeholder] = aws.String(name)
+}
+
+func (w *writeExpr) value(placeholder string, av *dynamodb.AttributeValue) {
+	if w.values == nil {
+		w.values = make(map[string]*dynamodb.AttributeValue)
+	}
+	w.values[placeholder] = av
+}
+
+// and adds cond to the condition expression.
+func (w *writeExpr) and(cond string) {
+	if w.condition == nil {
+		w.condition = aws.String(cond)
+		return
+	}
+	w.condition = aws.String(fmt.Sprintf("(%s) AND (%s)", *w.condition, cond))
+}
+
+// set adds action to the SET clause of the update expression.
+func (w *writeExpr) set(action string) {
+	if w.update == nil {
+		w.update = aws.String("SET " + action)
+		return
+	}
+	// clauses are separated by newlines and may appear only once each
+	clauses := strings.Split(*w.update, "\n")
+	for i, c := range clauses {
+		if strings.HasPrefix(c, "SET ") {
+			clauses[i] = "SET " + action + ", " + strings.TrimPrefix(c, "SET ")
+			w.update = aws.String(strings.Join(clauses, "\n"))
+			return
+		}
+	}
+	w.update = aws.String("SET " + action + "\n" + *w.update)
+}
+
+// onConditionCheckFailure returns the ReturnValuesOnConditionCheckFailure setting
+// of the write: the current item is returned if the write has a condition.
+func (w *writeExpr) onConditionCheckFailure() *string {
+	if w.condition == nil {
+		return nil
+	}
+	return aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+}
@@ -0,0 +1,195 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains typed variants of the read operations. Each item is
+// unmarshalled into a new value of type T, so results never share memory.
+package dynamo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// TypedScanResults contains one page of items returned by ScanItems.
+type TypedScanResults[T any] struct {
+	Results []T                                 `json:"results"`
+	PerPage int64                               `json:"per_page,omitempty"`
+	LastKey map[string]*dynamodb.AttributeValue `json:"last_key,omitempty"`
+}
+
+// TypedQueryResults contains one page of items returned by QueryItems.
+type TypedQueryResults[T any] struct {
+	Results []T                                 `json:"results"`
+	PerPage int64                               `json:"per_page,omitempty"`
+	LastKey map[string]*dynamodb.AttributeValue `json:"last_key,omitempty"`
+}
+
+// GetItem reads the item identified by q from the table and returns it as a T.
+// Returns the zero value of T if the item is not found.
+// ex: r, err := GetItem[*Record](d, q, "my_table", NewExpression())
+func GetItem[T any](d *DynamoDB, q *Query, tableName string, expr Expression) (T, error) {
+	var item T
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return item, NewTableNotFoundErr(tableName)
+	}
+
+	keys, err := keyMaker(q, t)
+	if err != nil {
+		return item, err
+	}
+	result, err := d.getItem(keys, t, expr)
+	if err != nil {
+		return item, err
+	}
+	if len(result) == 0 {
+		return item, nil
+	}
+
+	return unmarshalItem[T](result)
+}
+
+// UpdateItem updates the item identified by q as DynamoDB.UpdateItem does and
+// returns the updated attributes as a T.
+// ex: r, err := UpdateItem[Record](d, q, "my_table", expr)
+func UpdateItem[T any](d *DynamoDB, q *Query, tableName string, expr Expression) (T, error) {
+	return UpdateItemWithReturn[T](d, q, tableName, expr, ReturnValuesUpdatedNew)
+}
+
+// UpdateItemWithReturn updates the item identified by q as DynamoDB.UpdateItem does
+// and returns the attributes selected by returnValues as a T.
+// Returns the zero value of T if no attributes are returned.
+func UpdateItemWithReturn[T any](d *DynamoDB, q *Query, tableName string, expr Expression, returnValues string) (T, error) {
+	var item T
+	if err := d.UpdateItemWithReturn(q, tableName, expr, returnValues, &item); err != nil {
+		var zero T
+		return zero, err
+	}
+	return item, nil
+}
+
+// BatchGet retrieves a list of up to 100 items from the database as values of
+// type T. One result is returned per query, in query order: the result at
+// index i holds the item of queries[i], with Found set to false if the item
+// does not exist.
+func BatchGet[T any](d *DynamoDB, tableName string, retry RetryPolicy, queries []*Query, expr Expression) ([]BulkGetResult[T], error) {
+	if len(queries) > 100 {
+		return nil, ErrCollectionSizeExceeded
+	}
+
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	results, err := d.batchGetItems(t, retry, queries, expr)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]BulkGetResult[T], len(results))
+	for i, r := range results {
+		items[i] = BulkGetResult[T]{Index: i, Found: r.Found}
+		if !r.Found {
+			continue
+		}
+		if items[i].Item, err = unmarshalItem[T](r.Item); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// ScanItems scans the given Table for items matching the given expression
+// parameters and returns one page of results as values of type T.
+func ScanItems[T any](d *DynamoDB, tableName string, startKey any, expr Expression, perPage *int64) (*TypedScanResults[T], error) {
+	return ScanIndex[T](d, tableName, "", startKey, expr, perPage)
+}
+
+// ScanIndex scans the named secondary index of the given Table and returns one page
+// of results as values of type T. The table itself is scanned if indexName is empty.
+func ScanIndex[T any](d *DynamoDB, tableName, indexName string, startKey any, expr Expression, perPage *int64) (*TypedScanResults[T], error) {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	result, err := d.scan(context.Background(), t, indexName, startKey, expr, perPage)
+	if err != nil {
+		return nil, err
+	}
+	items, err := unmarshalItems[T](result.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	scanResult := &TypedScanResults[T]{
+		Results: items,
+		LastKey: result.LastEvaluatedKey,
+	}
+	if perPage != nil {
+		scanResult.PerPage = *perPage
+	}
+	return scanResult, nil
+}
+
+// QueryItems queries the given Table for items matching the given expression
+// parameters and returns one page of results as values of type T.
+func QueryItems[T any](d *DynamoDB, tableName string, startKey any, expr Expression, perPage *int64) (*TypedQueryResults[T], error) {
+	return QueryIndex[T](d, tableName, "", startKey, expr, perPage)
+}
+
+// QueryIndex queries the named secondary index of the given Table and returns one page
+// of results as values of type T. The table itself is queried if indexName is empty.
+func QueryIndex[T any](d *DynamoDB, tableName, indexName string, startKey any, expr Expression, perPage *int64) (*TypedQueryResults[T], error) {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	result, err := d.query(context.Background(), t, indexName, startKey, expr, perPage)
+	if err != nil {
+		return nil, err
+	}
+	items, err := unmarshalItems[T](result.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	queryResult := &TypedQueryResults[T]{
+		Results: items,
+		LastKey: result.LastEvaluatedKey,
+	}
+	if perPage != nil {
+		queryResult.PerPage = *perPage
+	}
+	return queryResult, nil
+}
+
+// unmarshalItem unmarshals an AttributeValue map into a new T.
+func unmarshalItem[T any](av map[string]*dynamodb.AttributeValue) (T, error) {
+	var item T
+	if err := dynamodbattribute.UnmarshalMap(av, &item); err != nil {
+		return item, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+	}
+	return item, nil
+}
+
+// unmarshalItems unmarshals each AttributeValue map into a new T.
+func unmarshalItems[T any](avs []map[string]*dynamodb.AttributeValue) ([]T, error) {
+	items := make([]T, 0, len(avs))
+	for _, av := range avs {
+		item, err := unmarshalItem[T](av)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
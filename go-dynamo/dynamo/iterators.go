@@ -0,0 +1,115 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains iterators that page through Query and Scan results.
+package dynamo
+
+import (
+	"context"
+	"iter"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// IterOptions configures the QueryAll and ScanAll iterators.
+type IterOptions struct {
+	// IndexName is the secondary index to read. The table is read if empty.
+	IndexName string
+	// StartKey is the key to resume reading after, such as the LastKey of a previous page.
+	StartKey any
+	// PerPage is the number of items evaluated per request. nil uses the DynamoDB default.
+	PerPage *int64
+	// MaxItems is the maximum number of items yielded. 0 means no limit.
+	MaxItems int
+	// Retry retries throttled requests when set.
+	// Throttled requests are returned as errors when nil.
+	Retry RetryPolicy
+}
+
+// page is one page of items and the key to read the next page from.
+type page struct {
+	items   []map[string]*dynamodb.AttributeValue
+	lastKey map[string]*dynamodb.AttributeValue
+}
+
+// QueryAll returns an iterator over every item matching the expression's key condition,
+// following LastEvaluatedKey until the results are exhausted, opts.MaxItems is reached,
+// or ctx is done. Iteration stops after the first error is yielded.
+// ex: for r, err := range QueryAll[Record](ctx, d, "my_table", expr, IterOptions{}) {...}
+func QueryAll[T any](ctx context.Context, d *DynamoDB, tableName string, expr Expression, opts IterOptions) iter.Seq2[T, error] {
+	return paginate[T](ctx, d, tableName, opts, func(t *Table, startKey any) (*page, error) {
+		result, err := d.query(ctx, t, opts.IndexName, startKey, expr, opts.PerPage)
+		if err != nil {
+			return nil, err
+		}
+		return &page{items: result.Items, lastKey: result.LastEvaluatedKey}, nil
+	})
+}
+
+// ScanAll returns an iterator over every item in the table matching the expression's filter,
+// following LastEvaluatedKey until the results are exhausted, opts.MaxItems is reached,
+// or ctx is done. Iteration stops after the first error is yielded.
+func ScanAll[T any](ctx context.Context, d *DynamoDB, tableName string, expr Expression, opts IterOptions) iter.Seq2[T, error] {
+	return paginate[T](ctx, d, tableName, opts, func(t *Table, startKey any) (*page, error) {
+		result, err := d.scan(ctx, t, opts.IndexName, startKey, expr, opts.PerPage)
+		if err != nil {
+			return nil, err
+		}
+		return &page{items: result.Items, lastKey: result.LastEvaluatedKey}, nil
+	})
+}
+
+// paginate yields the items of each page returned by next.
+func paginate[T any](ctx context.Context, d *DynamoDB, tableName string, opts IterOptions, next func(t *Table, startKey any) (*page, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		// get table
+		t := d.tables[tableName]
+		if t == nil {
+			yield(zero, NewTableNotFoundErr(tableName))
+			return
+		}
+
+		startKey, n := opts.StartKey, 0
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			p, err := nextPage(ctx, t, startKey, opts.Retry, next)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, av := range p.items {
+				item, err := unmarshalItem[T](av)
+				if !yield(item, err) || err != nil {
+					return
+				}
+				n++
+				if opts.MaxItems > 0 && n == opts.MaxItems {
+					return
+				}
+			}
+
+			if len(p.lastKey) == 0 {
+				return
+			}
+			startKey = p.lastKey
+		}
+	}
+}
+
+// nextPage reads a page, retrying throttled requests with policy if policy is not nil.
+func nextPage(ctx context.Context, t *Table, startKey any, policy RetryPolicy, next func(t *Table, startKey any) (*page, error)) (*page, error) {
+	if policy == nil {
+		return next(t, startKey)
+	}
+	var p *page
+	err := retryThrottled(ctx, policy, func() (err error) {
+		p, err = next(t, startKey)
+		return err
+	})
+	return p, err
+}
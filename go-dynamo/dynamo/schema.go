@@ -0,0 +1,318 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains operations for deriving table definitions from struct tags
+// and for building keys directly from item values.
+package dynamo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
+)
+
+// TagName is the struct tag used to declare key attributes.
+//
+// The tag value is a comma separated list of key roles. "pk" and "sk" mark the
+// table's Partition and Sort Keys; "<index>pk" and "<index>sk" mark the keys of
+// the named secondary index. An index with a Partition Key is a global index and
+// an index with only a Sort Key is a local index. "version" marks the numeric
+// attribute used for optimistic locking; see Table.VersionAttribute.
+//
+// ex:
+//
+//	type Order struct {
+//		Customer string `json:"customer" dynamo:"pk"`
+//		ID       string `json:"id" dynamo:"sk"`
+//		Status   string `json:"status" dynamo:"gsi1pk"`
+//		Total    int    `json:"total" dynamo:"gsi1sk,lsi1sk"`
+//		Version  int64  `json:"version" dynamo:"version"`
+//	}
+//
+// Attribute names are read from the dynamodbav or json tag, or default to the
+// field name, as in dynamodbattribute.
+const TagName = "dynamo"
+
+// keyAttr is a key attribute declared by a struct tag.
+type keyAttr struct {
+	name, attrType string
+}
+
+// indexKeys holds the keys of a table or secondary index declared by struct tags.
+type indexKeys struct {
+	pk, sk *keyAttr
+}
+
+// structTags holds the attributes declared by the struct tags of a type.
+type structTags struct {
+	keys    indexKeys
+	version *keyAttr
+	indexes map[string]*indexKeys
+	order   []string // index names in order of appearance
+}
+
+// NewTableFromStruct creates a new Table struct from the dynamo struct tags of
+// model, which must be a struct or a pointer to a struct. Indexes are created in
+// the order their tags first appear and project all attributes.
+// ex: t, err := NewTableFromStruct("orders", Order{})
+func NewTableFromStruct(tableName string, model any) (*Table, error) {
+	rt := reflect.TypeOf(model)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%w: %T is not a struct", ErrInvalidTableDefinition, model)
+	}
+
+	tags := &structTags{indexes: make(map[string]*indexKeys)}
+	if err := tags.parse(rt); err != nil {
+		return nil, err
+	}
+	keys := tags.keys
+	if keys.pk == nil {
+		return nil, fmt.Errorf("%w: %s has no %q field", ErrInvalidTableDefinition, rt, "pk")
+	}
+
+	t := &Table{
+		TableName:      tableName,
+		PrimaryKeyName: keys.pk.name,
+		PrimaryKeyType: keys.pk.attrType,
+	}
+	if keys.sk != nil {
+		t.SortKeyName, t.SortKeyType = keys.sk.name, keys.sk.attrType
+	}
+	if tags.version != nil {
+		if tags.version.attrType != "N" {
+			return nil, fmt.Errorf("%w: version attribute %s must be a number", ErrInvalidTableDefinition, tags.version.name)
+		}
+		t.VersionAttribute = tags.version.name
+	}
+	for _, name := range tags.order {
+		ik := tags.indexes[name]
+		idx := &Index{IndexName: name, ProjectionType: dynamodb.ProjectionTypeAll}
+		if ik.sk != nil {
+			idx.SortKeyName, idx.SortKeyType = ik.sk.name, ik.sk.attrType
+		}
+		if ik.pk == nil {
+			t.LocalIndexes = append(t.LocalIndexes, idx)
+			continue
+		}
+		idx.PrimaryKeyName, idx.PrimaryKeyType = ik.pk.name, ik.pk.attrType
+		t.GlobalIndexes = append(t.GlobalIndexes, idx)
+	}
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parse adds the attributes declared by the fields of rt, including the fields
+// of embedded structs.
+func (st *structTags) parse(rt reflect.Type) error {
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		name, ok := attributeName(f)
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && ft.Kind() == reflect.Struct && name == f.Name {
+			// embedded struct fields are flattened into the item
+			if err := st.parse(ft); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag := f.Tag.Get(TagName)
+		if tag == "" {
+			continue
+		}
+		attr := &keyAttr{name: name, attrType: keyAttrType(ft)}
+		if attr.attrType == "" {
+			return fmt.Errorf("%w: field %s of type %s cannot be a key", ErrInvalidTableDefinition, f.Name, f.Type)
+		}
+		for _, role := range strings.Split(tag, ",") {
+			role = strings.TrimSpace(role)
+			var target **keyAttr
+			switch {
+			case role == "pk":
+				target = &st.keys.pk
+			case role == "sk":
+				target = &st.keys.sk
+			case role == "version":
+				target = &st.version
+			case len(role) > 2 && (strings.HasSuffix(role, "pk") || strings.HasSuffix(role, "sk")):
+				idxName := role[:len(role)-2]
+				ik := st.indexes[idxName]
+				if ik == nil {
+					ik = &indexKeys{}
+					st.indexes[idxName] = ik
+					st.order = append(st.order, idxName)
+				}
+				target = &ik.sk
+				if strings.HasSuffix(role, "pk") {
+					target = &ik.pk
+				}
+			default:
+				return fmt.Errorf("%w: field %s has invalid tag %q", ErrInvalidTableDefinition, f.Name, role)
+			}
+			if *target != nil {
+				return fmt.Errorf("%w: %q is declared by %s and %s", ErrInvalidTableDefinition, role, (*target).name, name)
+			}
+			*target = attr
+		}
+	}
+	return nil
+}
+
+// attributeName returns the attribute name dynamodbattribute uses for f.
+// Returns false if the field is not marshalled.
+func attributeName(f reflect.StructField) (string, bool) {
+	if f.PkgPath != "" && !f.Anonymous {
+		return "", false
+	}
+	for _, tagKey := range []string{"dynamodbav", "json"} {
+		tag := f.Tag.Get(tagKey)
+		if tag == "-" {
+			return "", false
+		}
+		if name, _, _ := strings.Cut(tag, ","); name != "" {
+			return name, true
+		}
+	}
+	return f.Name, true
+}
+
+// keyAttrType returns the DynamoDB key type for values of rt,
+// or an empty string if rt cannot be used as a key.
+func keyAttrType(rt reflect.Type) string {
+	switch rt.Kind() {
+	case reflect.String:
+		return "S"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "N"
+	case reflect.Slice:
+		if rt.Elem().Kind() == reflect.Uint8 {
+			return "B"
+		}
+	}
+	return ""
+}
+
+// ItemKey returns the Partition and Sort Key attributes of item, which may be
+// any value that marshals to the table's item schema, such as the item itself.
+// Returns ErrMissingKey if item has no value for a key attribute.
+func (t *Table) ItemKey(item any) (map[string]*dynamodb.AttributeValue, error) {
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return nil, fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
+	}
+	return t.keysOf(av)
+}
+
+// keysOf returns the Partition and Sort Key attributes of the item av.
+func (t *Table) keysOf(av map[string]*dynamodb.AttributeValue) (map[string]*dynamodb.AttributeValue, error) {
+	keys := make(map[string]*dynamodb.AttributeValue)
+	for _, name := range []string{t.PrimaryKeyName, t.SortKeyName} {
+		if name == "" {
+			continue
+		}
+		v := av[name]
+		if v == nil || v.NULL != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMissingKey, name)
+		}
+		keys[name] = v
+	}
+	return keys, nil
+}
+
+// keyString encodes the Partition and Sort Key attributes of the item av as a
+// string, so that keys can be compared. Numbers are compared by value.
+func (t *Table) keyString(av map[string]*dynamodb.AttributeValue) string {
+	var b strings.Builder
+	for _, name := range []string{t.PrimaryKeyName, t.SortKeyName} {
+		if name == "" {
+			continue
+		}
+		v := av[name]
+		switch {
+		case v == nil:
+			b.WriteString("-")
+		case v.S != nil:
+			fmt.Fprintf(&b, "S%d:%s", len(*v.S), *v.S)
+		case v.N != nil:
+			n := *v.N
+			if r, ok := new(big.Rat).SetString(n); ok {
+				n = r.RatString()
+			}
+			fmt.Fprintf(&b, "N%s", n)
+		case v.B != nil:
+			fmt.Fprintf(&b, "B%s", base64.StdEncoding.EncodeToString(v.B))
+		}
+		b.WriteByte(';')
+	}
+	return b.String()
+}
+
+// GetItemByKey reads the item with the same key as key from the table and returns it
+// as a T. key is usually a T with only its key fields set.
+// Returns the zero value of T if the item is not found.
+// ex: o, err := GetItemByKey(d, &Order{Customer: "c1", ID: "o1"}, "orders", NewExpression())
+func GetItemByKey[T any](d *DynamoDB, key T, tableName string, expr Expression) (T, error) {
+	var item T
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return item, NewTableNotFoundErr(tableName)
+	}
+
+	keys, err := t.ItemKey(key)
+	if err != nil {
+		return item, err
+	}
+	result, err := d.getItem(keys, t, expr)
+	if err != nil {
+		return item, err
+	}
+	if len(result) == 0 {
+		return item, nil
+	}
+
+	return unmarshalItem[T](result)
+}
+
+// DeleteItemByKey deletes the item with the same key as item from the table.
+// In tables with a VersionAttribute, item's version is the expected version.
+func (d *DynamoDB) DeleteItemByKey(item interface{}, tableName string) error {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	av, err := dynamodbattribute.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("dynamodbattribute.MarshalMap: %w", err)
+	}
+	keys, err := t.keysOf(av)
+	if err != nil {
+		return err
+	}
+	version, err := expectedVersion(t, &Query{Version: itemVersion(t, av)})
+	if err != nil {
+		return err
+	}
+	return d.deleteItem(keys, t, version, NewExpression(), ReturnValuesNone, nil)
+}
@@ -0,0 +1,228 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains operations for describing tables, waiting on table status
+// changes and discovering table definitions.
+package dynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// DefaultPollInterval is the time WaitUntilActive and WaitUntilDeleted wait between status checks.
+const DefaultPollInterval = 5 * time.Second
+
+// TableDescription describes an existing table.
+type TableDescription struct {
+	// Table is the table's definition, including its indexes and settings.
+	Table *Table
+	// Status is the table's status, such as "CREATING" or "ACTIVE".
+	Status    string
+	TableArn  string
+	ItemCount int64
+	SizeBytes int64
+}
+
+// DescribeTable returns the definition and status of the named table.
+// The table does not need to be known to the DynamoDB object.
+func (d *DynamoDB) DescribeTable(tableName string) (*TableDescription, error) {
+	return d.describeTable(context.Background(), tableName)
+}
+
+// WaitUntilActive waits until the named table and its global indexes are ACTIVE,
+// checking the table's status every pollInterval (DefaultPollInterval if 0).
+// Returns ctx.Err() if ctx is done first.
+func (d *DynamoDB) WaitUntilActive(ctx context.Context, tableName string, pollInterval time.Duration) error {
+	return poll(ctx, pollInterval, func() (bool, error) {
+		desc, err := d.svc.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			// a new table may not be visible immediately
+			if errors.Is(handleErr(err), ErrResourceNotFound) {
+				return false, nil
+			}
+			return false, fmt.Errorf("d.svc.DescribeTableWithContext: %w", handleErr(err))
+		}
+		if aws.StringValue(desc.Table.TableStatus) != dynamodb.TableStatusActive {
+			return false, nil
+		}
+		for _, gsi := range desc.Table.GlobalSecondaryIndexes {
+			if aws.StringValue(gsi.IndexStatus) != dynamodb.IndexStatusActive {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+}
+
+// WaitUntilDeleted waits until the named table no longer exists,
+// checking the table's status every pollInterval (DefaultPollInterval if 0).
+// Returns ctx.Err() if ctx is done first.
+func (d *DynamoDB) WaitUntilDeleted(ctx context.Context, tableName string, pollInterval time.Duration) error {
+	return poll(ctx, pollInterval, func() (bool, error) {
+		_, err := d.svc.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err == nil {
+			return false, nil
+		}
+		if errors.Is(handleErr(err), ErrResourceNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("d.svc.DescribeTableWithContext: %w", handleErr(err))
+	})
+}
+
+// LoadTables discovers the definitions of the named tables, or of every table
+// returned by ListTables if no names are given, and adds them to the tables
+// known to the DynamoDB object. Tables already known keep their VersionAttribute,
+// which DescribeTable does not return. Returns the loaded tables.
+func (d *DynamoDB) LoadTables(tableNames ...string) ([]*Table, error) {
+	if len(tableNames) == 0 {
+		names, _, err := d.ListTables()
+		if err != nil {
+			return nil, err
+		}
+		tableNames = names
+	}
+
+	tables := []*Table{}
+	for _, name := range tableNames {
+		desc, err := d.describeTable(context.Background(), name)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, desc.Table)
+	}
+	for _, t := range tables {
+		if known := d.tables[t.TableName]; known != nil {
+			t.VersionAttribute = known.VersionAttribute
+		}
+		d.tables[t.TableName] = t
+	}
+	return tables, nil
+}
+
+func (d *DynamoDB) describeTable(ctx context.Context, tableName string) (*TableDescription, error) {
+	result, err := d.svc.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.DescribeTableWithContext: %w", handleErr(err))
+	}
+
+	desc := result.Table
+	t, err := tableFromDescription(desc)
+	if err != nil {
+		return nil, err
+	}
+	return &TableDescription{
+		Table:     t,
+		Status:    aws.StringValue(desc.TableStatus),
+		TableArn:  aws.StringValue(desc.TableArn),
+		ItemCount: aws.Int64Value(desc.ItemCount),
+		SizeBytes: aws.Int64Value(desc.TableSizeBytes),
+	}, nil
+}
+
+// tableFromDescription converts a DynamoDB table description into a Table.
+func tableFromDescription(desc *dynamodb.TableDescription) (*Table, error) {
+	types := make(map[string]string)
+	for _, ad := range desc.AttributeDefinitions {
+		types[aws.StringValue(ad.AttributeName)] = aws.StringValue(ad.AttributeType)
+	}
+
+	t := &Table{TableName: aws.StringValue(desc.TableName), Options: &TableOptions{}}
+	t.PrimaryKeyName, t.SortKeyName = keyNames(desc.KeySchema)
+	t.PrimaryKeyType, t.SortKeyType = types[t.PrimaryKeyName], types[t.SortKeyName]
+	for _, gsi := range desc.GlobalSecondaryIndexes {
+		idx := &Index{IndexName: aws.StringValue(gsi.IndexName)}
+		idx.PrimaryKeyName, idx.SortKeyName = keyNames(gsi.KeySchema)
+		idx.PrimaryKeyType, idx.SortKeyType = types[idx.PrimaryKeyName], types[idx.SortKeyName]
+		setProjection(idx, gsi.Projection)
+		t.GlobalIndexes = append(t.GlobalIndexes, idx)
+	}
+	for _, lsi := range desc.LocalSecondaryIndexes {
+		idx := &Index{IndexName: aws.StringValue(lsi.IndexName)}
+		_, idx.SortKeyName = keyNames(lsi.KeySchema)
+		idx.SortKeyType = types[idx.SortKeyName]
+		setProjection(idx, lsi.Projection)
+		t.LocalIndexes = append(t.LocalIndexes, idx)
+	}
+
+	// tables created before on-demand billing was introduced have no billing mode summary
+	t.Options.BillingMode = dynamodb.BillingModeProvisioned
+	if desc.BillingModeSummary != nil {
+		t.Options.BillingMode = aws.StringValue(desc.BillingModeSummary.BillingMode)
+	}
+	if t.Options.BillingMode == dynamodb.BillingModeProvisioned && desc.ProvisionedThroughput != nil {
+		t.Options.ReadCapacity = aws.Int64Value(desc.ProvisionedThroughput.ReadCapacityUnits)
+		t.Options.WriteCapacity = aws.Int64Value(desc.ProvisionedThroughput.WriteCapacityUnits)
+	}
+	if desc.StreamSpecification != nil && aws.BoolValue(desc.StreamSpecification.StreamEnabled) {
+		t.Options.StreamViewType = aws.StringValue(desc.StreamSpecification.StreamViewType)
+	}
+	if desc.SSEDescription != nil && aws.StringValue(desc.SSEDescription.Status) == dynamodb.SSEStatusEnabled {
+		t.Options.SSEEnabled = true
+		t.Options.KMSKeyID = aws.StringValue(desc.SSEDescription.KMSMasterKeyArn)
+	}
+	if desc.TableClassSummary != nil {
+		t.Options.TableClass = aws.StringValue(desc.TableClassSummary.TableClass)
+	}
+	t.Options.DeletionProtection = aws.BoolValue(desc.DeletionProtectionEnabled)
+
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// keyNames returns the Partition and Sort Key names of a key schema.
+func keyNames(ks []*dynamodb.KeySchemaElement) (string, string) {
+	var pk, sk string
+	for _, e := range ks {
+		switch aws.StringValue(e.KeyType) {
+		case dynamodb.KeyTypeHash:
+			pk = aws.StringValue(e.AttributeName)
+		case dynamodb.KeyTypeRange:
+			sk = aws.StringValue(e.AttributeName)
+		}
+	}
+	return pk, sk
+}
+
+// setProjection sets the projection of idx from its description.
+func setProjection(idx *Index, p *dynamodb.Projection) {
+	if p == nil {
+		return
+	}
+	idx.ProjectionType = aws.StringValue(p.ProjectionType)
+	if len(p.NonKeyAttributes) > 0 {
+		idx.NonKeyAttributes = aws.StringValueSlice(p.NonKeyAttributes)
+	}
+}
+
+// poll calls done every interval until it returns true or an error, or ctx is done.
+func poll(ctx context.Context, interval time.Duration, done func() (bool, error)) error {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ok, err := done()
+		if err != nil && ctx.Err() != nil {
+			// the request was cancelled by ctx
+			return ctx.Err()
+		}
+		if err != nil || ok {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,10 +1,15 @@
 package dynamo
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 )
 
 // TransactionItem contains an item to create / update
@@ -87,9 +92,155 @@ func NewConditionCheckTxItem(name string, t *Table, q *Query, e Expression) Tran
 // return an error value, and a list of the TransactionItems that failed their condition checks. Successful condition
 // checks return an empty list of TransactionItems and nil error value.
 func (d *DynamoDB) TxWrite(items []TransactionItem, requestToken string) ([]TransactionItem, error) {
+	txInput, err := newTxWriteInput(items, requestToken)
+	if err != nil {
+		return []TransactionItem{}, err
+	}
+
+	// retry throttled requests, which are not applied
+	err = retryThrottled(context.Background(), d.retryPolicy(nil), func() error {
+		_, err := d.svc.TransactWriteItems(txInput)
+		return err
+	})
+	if err != nil {
+		return txErr("TransactWriteItems", items, err)
+	}
+
+	return []TransactionItem{}, nil
+}
+
+// TxRead reads items of type "R" (see NewReadTxItem) from any tables in a
+// single transaction, applying each item's projection. The item read for each
+// TransactionItem is unmarshalled into dest[Name], which must be a non-nil pointer.
+// Returns ErrDuplicateName if items share a Name, or ErrMissingDestination if an
+// item has no destination. Returns the TransactionItems whose items do not exist.
+func (d *DynamoDB) TxRead(items []TransactionItem, dest map[string]interface{}) ([]TransactionItem, error) {
+	// verify <= 100 tx items
+	if len(items) > 100 {
+		return []TransactionItem{}, fmt.Errorf("TX_ITEMS_EXCEEDS_LIMIT")
+	}
+
+	txInput := &dynamodb.TransactGetItemsInput{}
+	names := make(map[string]bool)
+	for _, ti := range items {
+		if names[ti.Name] {
+			return []TransactionItem{}, fmt.Errorf("%w: %s", ErrDuplicateName, ti.Name)
+		}
+		names[ti.Name] = true
+		if dest[ti.Name] == nil {
+			return []TransactionItem{}, fmt.Errorf("%w: %s", ErrMissingDestination, ti.Name)
+		}
+		txItem, err := newTxReadItem(ti)
+		if err != nil {
+			return []TransactionItem{}, fmt.Errorf("newTxReadItem: %w", err)
+		}
+		txInput.TransactItems = append(txInput.TransactItems, txItem)
+	}
+
+	var result *dynamodb.TransactGetItemsOutput
+	err := retryThrottled(context.Background(), d.retryPolicy(nil), func() (err error) {
+		result, err = d.svc.TransactGetItems(txInput)
+		return err
+	})
+	if err != nil {
+		var tce *dynamodb.TransactionCanceledException
+		if errors.As(err, &tce) {
+			return txErr("TransactGetItems", items, err)
+		}
+		return []TransactionItem{}, fmt.Errorf("d.svc.TransactGetItems: %w", handleErr(err))
+	}
+
+	notFound := []TransactionItem{}
+	for i, r := range result.Responses {
+		if r.Item == nil {
+			notFound = append(notFound, items[i])
+			continue
+		}
+		if err := dynamodbattribute.UnmarshalMap(r.Item, dest[items[i].Name]); err != nil {
+			return []TransactionItem{}, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+		}
+	}
+	return notFound, nil
+}
+
+// TxCancellation is the reason one item of a cancelled transaction was cancelled.
+type TxCancellation struct {
+	// Name is the Name of the TransactionItem.
+	Name string
+	// Code is the reason code, such as ConditionalCheckFailed, TransactionConflict
+	// or ThrottlingError.
+	Code    string
+	Message string
+	item    map[string]*dynamodb.AttributeValue
+}
+
+// Item unmarshals the item's current value into out if it was returned, which
+// DynamoDB does for items that failed their condition. Returns false if not.
+func (c *TxCancellation) Item(out interface{}) (bool, error) {
+	if c.item == nil {
+		return false, nil
+	}
+	if err := dynamodbattribute.UnmarshalMap(c.item, out); err != nil {
+		return false, fmt.Errorf("dynamodbattribute.UnmarshalMap: %w", err)
+	}
+	return true, nil
+}
+
+// TxReport reports the attempts of a transaction written by TxWriteWithRetry.
+type TxReport struct {
+	// RequestToken is the ClientRequestToken of every attempt.
+	RequestToken string
+	// Attempts is the number of attempts made.
+	Attempts int
+	// Cancellations holds the reasons the items of the last attempt were
+	// cancelled, in request order. Items with reason code None are omitted.
+	Cancellations []TxCancellation
+}
+
+// TxWriteWithRetry writes items in a transaction as TxWrite does, retrying
+// throttled requests and transactions cancelled by throttling or conflicts
+// (ErrTxThrottled, ErrTxConflict) with retry, or the client's RetryPolicy if nil.
+// Every attempt uses requestToken, or a generated token if empty, so that the
+// transaction is applied at most once. Returns the report of the attempts and
+// the error of the last attempt.
+func (d *DynamoDB) TxWriteWithRetry(ctx context.Context, items []TransactionItem, requestToken string, retry RetryPolicy) (*TxReport, error) {
+	if requestToken == "" {
+		requestToken = newRequestToken()
+	}
+	txInput, err := newTxWriteInput(items, requestToken)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TxReport{RequestToken: requestToken}
+	retrier := d.retryPolicy(retry).Start()
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		report.Attempts++
+		_, err := d.svc.TransactWriteItemsWithContext(ctx, txInput)
+		report.Cancellations = txCancellations(items, err)
+		if err == nil {
+			return report, nil
+		}
+		_, terr := txErr("TransactWriteItems", items, err)
+		if !errors.Is(terr, ErrTxThrottled) && !errors.Is(terr, ErrTxConflict) && !errors.Is(handleErr(err), ErrRateLimitExceeded) {
+			return report, terr
+		}
+		if werr := retrier.Wait(ctx); errors.Is(werr, ErrRetriesExhausted) {
+			return report, terr
+		} else if werr != nil {
+			return report, werr
+		}
+	}
+}
+
+// newTxWriteInput returns the TransactWriteItems input of items.
+func newTxWriteInput(items []TransactionItem, requestToken string) (*dynamodb.TransactWriteItemsInput, error) {
 	// verify <= 25 tx items
 	if len(items) > 25 {
-		return []TransactionItem{}, fmt.Errorf("TX_ITEMS_EXCEEDS_LIMIT")
+		return nil, fmt.Errorf("TX_ITEMS_EXCEEDS_LIMIT")
 	}
 
 	txInput := &dynamodb.TransactWriteItemsInput{}
@@ -102,52 +253,95 @@ func (d *DynamoDB) TxWrite(items []TransactionItem, requestToken string) ([]Tran
 	for _, ti := range items {
 		txItem, err := newTxWriteItem(ti)
 		if err != nil {
-			return []TransactionItem{}, fmt.Errorf("newTxWriteItem: %w", err)
+			return nil, fmt.Errorf("newTxWriteItem: %w", err)
 		}
 		txInput.TransactItems = append(txInput.TransactItems, txItem)
 	}
+	return txInput, nil
+}
 
+// txErr classifies the error of a transaction request to the named operation
+// and returns the items that caused it.
+func txErr(op string, items []TransactionItem, err error) ([]TransactionItem, error) {
 	failed := []TransactionItem{}
+	var (
+		canceled   *dynamodb.TransactionCanceledException
+		conflicted *dynamodb.TransactionConflictException
+		inProgress *dynamodb.TransactionInProgressException
+	)
+	switch {
+	case errors.As(err, &canceled):
+		check := false     // denotes conditional checks failed
+		throttled := false // denotes if tx failed due to throttling
+		conflict := false  // denotes if tx failed due to a conflicting transaction
 
-	if _, err := d.svc.TransactWriteItems(txInput); err != nil {
-		switch t := err.(type) {
-		case *dynamodb.TransactionCanceledException:
-			check := false     // denotes conditional checks failed
-			throttled := false // denotes if tx failed due to throttling
-
-			for i, r := range t.CancellationReasons {
-				if *r.Code == "ConditionalCheckFailed" {
-					check = true
-					failed = append(failed, items[i])
-				}
-				if *r.Code == "ThrottlingError" {
-					throttled = true
-					failed = append(failed, items[i])
-				}
+		for i, r := range canceled.CancellationReasons {
+			switch aws.StringValue(r.Code) {
+			case "ConditionalCheckFailed":
+				check = true
+				failed = append(failed, items[i])
+			case "ThrottlingError":
+				throttled = true
+				failed = append(failed, items[i])
+			case "TransactionConflict":
+				conflict = true
+				failed = append(failed, items[i])
 			}
+		}
 
-			if check {
-				// no retry
-				return failed, ErrTxConditionCheckFailed
-			}
-			if throttled {
-				// retry
-				return failed, ErrTxThrottled
-			}
+		if check {
 			// no retry
-			return failed, fmt.Errorf("d.svc.TransactWriteItems: %w", err)
-		case *dynamodb.TransactionConflictException:
+			return failed, ErrTxConditionCheckFailed
+		}
+		if throttled {
+			// retry
+			return failed, ErrTxThrottled
+		}
+		if conflict {
 			// retry
 			return failed, ErrTxConflict
-		case *dynamodb.TransactionInProgressException:
-			// no retry
-			return failed, ErrTxInProgress
-		default:
-			return failed, err
 		}
+		// no retry
+		return failed, fmt.Errorf("d.svc.%s: %w", op, err)
+	case errors.As(err, &conflicted):
+		// retry
+		return failed, ErrTxConflict
+	case errors.As(err, &inProgress):
+		// no retry
+		return failed, ErrTxInProgress
+	default:
+		return failed, err
 	}
+}
 
-	return failed, nil
+// txCancellations returns the cancellation reasons of the items of a cancelled
+// transaction, or nil if err is not a TransactionCanceledException.
+func txCancellations(items []TransactionItem, err error) []TxCancellation {
+	var tce *dynamodb.TransactionCanceledException
+	if !errors.As(err, &tce) {
+		return nil
+	}
+	cancellations := []TxCancellation{}
+	for i, r := range tce.CancellationReasons {
+		code := aws.StringValue(r.Code)
+		if code == "None" || i >= len(items) {
+			continue
+		}
+		cancellations = append(cancellations, TxCancellation{
+			Name:    items[i].Name,
+			Code:    code,
+			Message: aws.StringValue(r.Message),
+			item:    r.Item,
+		})
+	}
+	return cancellations
+}
+
+// newRequestToken returns a random ClientRequestToken.
+func newRequestToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
 }
 
 func newTxWriteItem(ti TransactionItem) (*dynamodb.TransactWriteItem, error) {
@@ -161,45 +355,61 @@ func newTxWriteItem(ti TransactionItem) (*dynamodb.TransactWriteItem, error) {
 		}
 		txItem := &dynamodb.TransactWriteItem{
 			Put: &dynamodb.Put{
-				Item:                      m,
-				ConditionExpression:       ti.Expr.Condition(),
-				ExpressionAttributeNames:  ti.Expr.Names(),
-				ExpressionAttributeValues: ti.Expr.Values(),
-				TableName:                 aws.String(ti.Table.TableName),
+				Item:                                m,
+				ConditionExpression:                 ti.Expr.Condition(),
+				ExpressionAttributeNames:            ti.Expr.Names(),
+				ExpressionAttributeValues:           ti.Expr.Values(),
+				ReturnValuesOnConditionCheckFailure: txOnConditionCheckFailure(ti.Expr),
+				TableName:                           aws.String(ti.Table.TableName),
 			},
 		}
 		return txItem, nil
 	case "U":
+		key, err := keyMaker(ti.Query, ti.Table)
+		if err != nil {
+			return nil, fmt.Errorf("keyMaker: %w", err)
+		}
 		txItem := &dynamodb.TransactWriteItem{
 			Update: &dynamodb.Update{
-				ConditionExpression:       ti.Expr.Condition(),
-				ExpressionAttributeNames:  ti.Expr.Names(),
-				ExpressionAttributeValues: ti.Expr.Values(),
-				TableName:                 aws.String(ti.Table.TableName),
-				Key:                       keyMaker(ti.Query, ti.Table),
-				UpdateExpression:          ti.Expr.Update(),
+				ConditionExpression:                 ti.Expr.Condition(),
+				ExpressionAttributeNames:            ti.Expr.Names(),
+				ExpressionAttributeValues:           ti.Expr.Values(),
+				ReturnValuesOnConditionCheckFailure: txOnConditionCheckFailure(ti.Expr),
+				TableName:                           aws.String(ti.Table.TableName),
+				Key:                                 key,
+				UpdateExpression:                    ti.Expr.Update(),
 			},
 		}
 		return txItem, nil
 	case "D":
+		key, err := keyMaker(ti.Query, ti.Table)
+		if err != nil {
+			return nil, fmt.Errorf("keyMaker: %w", err)
+		}
 		txItem := &dynamodb.TransactWriteItem{
 			Delete: &dynamodb.Delete{
-				ConditionExpression:       ti.Expr.Condition(),
-				ExpressionAttributeNames:  ti.Expr.Names(),
-				ExpressionAttributeValues: ti.Expr.Values(),
-				TableName:                 aws.String(ti.Table.TableName),
-				Key:                       keyMaker(ti.Query, ti.Table),
+				ConditionExpression:                 ti.Expr.Condition(),
+				ExpressionAttributeNames:            ti.Expr.Names(),
+				ExpressionAttributeValues:           ti.Expr.Values(),
+				ReturnValuesOnConditionCheckFailure: txOnConditionCheckFailure(ti.Expr),
+				TableName:                           aws.String(ti.Table.TableName),
+				Key:                                 key,
 			},
 		}
 		return txItem, nil
 	case "CC":
+		key, err := keyMaker(ti.Query, ti.Table)
+		if err != nil {
+			return nil, fmt.Errorf("keyMaker: %w", err)
+		}
 		txItem := &dynamodb.TransactWriteItem{
 			ConditionCheck: &dynamodb.ConditionCheck{
-				ConditionExpression:       ti.Expr.Condition(),
-				ExpressionAttributeNames:  ti.Expr.Names(),
-				ExpressionAttributeValues: ti.Expr.Values(),
-				TableName:                 aws.String(ti.Table.TableName),
-				Key:                       keyMaker(ti.Query, ti.Table),
+				ConditionExpression:                 ti.Expr.Condition(),
+				ExpressionAttributeNames:            ti.Expr.Names(),
+				ExpressionAttributeValues:           ti.Expr.Values(),
+				ReturnValuesOnConditionCheckFailure: txOnConditionCheckFailure(ti.Expr),
+				TableName:                           aws.String(ti.Table.TableName),
+				Key:                                 key,
 			},
 		}
 		return txItem, nil
@@ -208,3 +418,35 @@ func newTxWriteItem(ti TransactionItem) (*dynamodb.TransactWriteItem, error) {
 	}
 
 }
+
+// txOnConditionCheckFailure returns the ReturnValuesOnConditionCheckFailure of
+// a transaction item, so that cancellations include the current item of failed conditions.
+func txOnConditionCheckFailure(expr Expression) *string {
+	if expr.Condition() == nil {
+		return nil
+	}
+	return aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld)
+}
+
+func newTxReadItem(ti TransactionItem) (*dynamodb.TransactGetItem, error) {
+	if ti.GetRequest() != "R" {
+		return nil, ErrInvalidRequestType
+	}
+	e := ti.Expr
+	if e.Condition() != nil || e.Filter() != nil || e.KeyCondition() != nil || e.Update() != nil {
+		return nil, fmt.Errorf("%w: reads accept projection expressions only", ErrInvalidExpression)
+	}
+	key, err := keyMaker(ti.Query, ti.Table)
+	if err != nil {
+		return nil, fmt.Errorf("keyMaker: %w", err)
+	}
+	txItem := &dynamodb.TransactGetItem{
+		Get: &dynamodb.Get{
+			ExpressionAttributeNames: ti.Expr.Names(),
+			Key:                      key,
+			ProjectionExpression:     ti.Expr.Projection(),
+			TableName:                aws.String(ti.Table.TableName),
+		},
+	}
+	return txItem, nil
+}
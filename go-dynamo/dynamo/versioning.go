@@ -0,0 +1,91 @@
+// Package dynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains the optimistic locking controls for tables with a
+// VersionAttribute.
+//
+// In a versioned table, CreateItem sets the version of new items to 1 and fails
+// if the item already exists. UpdateItem and DeleteItem fail unless the item's
+// version equals the Query's Version, and UpdateItem increments it. Failed
+// version checks return a *VersionConflictErr. Batch writes to versioned tables
+// return ErrVersionedTable, and transactions are not versioned.
+package dynamo
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// Expression placeholders used by version checks and projections. Expressions built by
+// ExprBuilder use numbered placeholders, so these never collide.
+const (
+	versionName      = "#version"
+	versionValue     = ":version"
+	nextVersionValue = ":nextVersion"
+	partitionKeyName = "#partitionKey"
+	sortKeyName      = "#sortKey"
+)
+
+// requireNew adds a check that the item does not exist.
+func (w *writeExpr) requireNew(t *Table) {
+	w.name(partitionKeyName, t.PrimaryKeyName)
+	w.and("attribute_not_exists(" + partitionKeyName + ")")
+}
+
+// requireVersion adds a check that the item's version is version.
+func (w *writeExpr) requireVersion(t *Table, version int64) {
+	w.name(versionName, t.VersionAttribute)
+	w.value(versionValue, versionAV(version))
+	w.and(versionName + " = " + versionValue)
+}
+
+// incrementVersion sets the item's version to version + 1.
+func (w *writeExpr) incrementVersion(t *Table, version int64) {
+	w.name(versionName, t.VersionAttribute)
+	w.value(nextVersionValue, versionAV(version+1))
+	w.set(versionName + " = " + nextVersionValue)
+}
+
+// versionAV returns the Attribute Value of a version.
+func versionAV(version int64) *dynamodb.AttributeValue {
+	return (&dynamodb.AttributeValue{}).SetN(strconv.FormatInt(version, 10))
+}
+
+// itemVersion returns the version of the item av, or 0 if it has none.
+func itemVersion(t *Table, av map[string]*dynamodb.AttributeValue) int64 {
+	v := av[t.VersionAttribute]
+	if v == nil || v.N == nil {
+		return 0
+	}
+	n, _ := strconv.ParseInt(*v.N, 10, 64)
+	return n
+}
+
+// expectedVersion returns q's Version, or ErrMissingVersion if t is versioned
+// and q has no Version.
+func expectedVersion(t *Table, q *Query) (int64, error) {
+	if t.VersionAttribute != "" && q.Version <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrMissingVersion, t.VersionAttribute)
+	}
+	return q.Version, nil
+}
+
+// versionErr returns a *VersionConflictErr if err is a failed version check of
+// a write to t that expected the given version. Other errors are handled by handleErr.
+// The item's current value is returned on failed checks of versioned writes to
+// tell version conflicts from failures of the caller's condition.
+func versionErr(t *Table, expected int64, err error) error {
+	var ccf *dynamodb.ConditionalCheckFailedException
+	if t.VersionAttribute == "" || !errors.As(err, &ccf) {
+		return handleErr(err)
+	}
+	exists := ccf.Item != nil
+	current := itemVersion(t, ccf.Item)
+	if (expected == 0 && exists) || (expected != 0 && (!exists || current != expected)) {
+		return NewVersionConflictErr(t.TableName, expected, current)
+	}
+	return handleErr(err)
+}
@@ -0,0 +1,262 @@
+package s3test
+
+// This file contains multipart upload operations.
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minPartSize is the minimum size of every part but the last.
+	minPartSize = 5 << 20
+	maxParts    = 10000
+)
+
+// upload is an in-progress multipart upload.
+type upload struct {
+	id        string
+	bucket    *bucket
+	key       string
+	initiated time.Time
+	headers   headers
+	// checksumAlgorithm is set if parts must carry checksums
+	checksumAlgorithm string
+	checksumType      string
+	parts             map[int]*part
+}
+
+type part struct {
+	number   int
+	data     []byte
+	etag     string
+	checksum string
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+func (s *Server) createMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	u := &upload{
+		bucket:            b,
+		key:               key,
+		initiated:         s.Now(),
+		headers:           readHeaders(r),
+		checksumAlgorithm: strings.ToUpper(r.Header.Get("x-amz-checksum-algorithm")),
+		checksumType:      strings.ToUpper(r.Header.Get("x-amz-checksum-type")),
+		parts:             make(map[int]*part),
+	}
+	if u.checksumAlgorithm != "" {
+		if _, ok := checksumAlgorithms[u.checksumAlgorithm]; !ok {
+			return newError(http.StatusBadRequest, "InvalidRequest", "Checksum algorithm provided is unsupported.")
+		}
+		if u.checksumType == "" {
+			u.checksumType = "COMPOSITE"
+		}
+	}
+	s.seq++
+	u.id = base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s/%s/%d", b.name, key, s.seq)))
+	s.uploads[u.id] = u
+
+	if u.checksumAlgorithm != "" {
+		w.Header().Set("x-amz-checksum-algorithm", u.checksumAlgorithm)
+		w.Header().Set("x-amz-checksum-type", u.checksumType)
+	}
+	writeXML(w, http.StatusOK, initiateMultipartUploadResult{Xmlns: xmlns, Bucket: b.name, Key: key, UploadID: u.id})
+	return nil
+}
+
+// findUpload returns the upload identified by the uploadId parameter of r.
+func (s *Server) findUpload(r *http.Request, b *bucket, key string) (*upload, *s3Error) {
+	u := s.uploads[r.URL.Query().Get("uploadId")]
+	if u == nil || u.bucket != b || u.key != key {
+		return nil, newError(http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist. The upload ID may be invalid, or the upload may have been aborted or completed.")
+	}
+	return u, nil
+}
+
+func (s *Server) uploadPart(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	u, err := s.findUpload(r, b, key)
+	if err != nil {
+		return err
+	}
+	n, perr := strconv.Atoi(r.URL.Query().Get("partNumber"))
+	if perr != nil || n < 1 || n > maxParts {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and %d, inclusive", maxParts)
+	}
+	data, algorithm, checksum, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	if u.checksumAlgorithm != "" {
+		if algorithm != "" && algorithm != u.checksumAlgorithm {
+			return newError(http.StatusBadRequest, "InvalidRequest", "Checksum Type mismatch occurred, expected checksum Type: %s, actual checksum Type: %s", strings.ToLower(u.checksumAlgorithm), strings.ToLower(algorithm))
+		}
+		checksum = digest(u.checksumAlgorithm, data)
+	}
+	p := &part{number: n, data: data, etag: etag(data), checksum: checksum}
+	u.parts[n] = p
+
+	w.Header().Set("ETag", p.etag)
+	if u.checksumAlgorithm != "" {
+		w.Header().Set(checksumHdr+strings.ToLower(u.checksumAlgorithm), p.checksum)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+type completeMultipartUpload struct {
+	Parts []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber        int    `xml:"PartNumber"`
+	ETag              string `xml:"ETag"`
+	ChecksumCRC32     string `xml:"ChecksumCRC32"`
+	ChecksumCRC32C    string `xml:"ChecksumCRC32C"`
+	ChecksumCRC64NVME string `xml:"ChecksumCRC64NVME"`
+	ChecksumSHA1      string `xml:"ChecksumSHA1"`
+	ChecksumSHA256    string `xml:"ChecksumSHA256"`
+}
+
+// checksum returns the part checksum sent for the named algorithm.
+func (p completedPart) checksum(algorithm string) string {
+	switch algorithm {
+	case "CRC32":
+		return p.ChecksumCRC32
+	case "CRC32C":
+		return p.ChecksumCRC32C
+	case "CRC64NVME":
+		return p.ChecksumCRC64NVME
+	case "SHA1":
+		return p.ChecksumSHA1
+	case "SHA256":
+		return p.ChecksumSHA256
+	}
+	return ""
+}
+
+type completeMultipartUploadResult struct {
+	XMLName           xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns             string   `xml:"xmlns,attr"`
+	Location          string   `xml:"Location"`
+	Bucket            string   `xml:"Bucket"`
+	Key               string   `xml:"Key"`
+	ETag              string   `xml:"ETag"`
+	ChecksumCRC32     string   `xml:"ChecksumCRC32,omitempty"`
+	ChecksumCRC32C    string   `xml:"ChecksumCRC32C,omitempty"`
+	ChecksumCRC64NVME string   `xml:"ChecksumCRC64NVME,omitempty"`
+	ChecksumSHA1      string   `xml:"ChecksumSHA1,omitempty"`
+	ChecksumSHA256    string   `xml:"ChecksumSHA256,omitempty"`
+	ChecksumType      string   `xml:"ChecksumType,omitempty"`
+}
+
+// completeMultipartUpload assembles the listed parts into an object. The
+// ETag is the MD5 of the part MD5s followed by the part count; composite
+// checksums are computed the same way from the part checksums.
+func (s *Server) completeMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	u, err := s.findUpload(r, b, key)
+	if err != nil {
+		return err
+	}
+	var in completeMultipartUpload
+	if err := xml.NewDecoder(r.Body).Decode(&in); err != nil || len(in.Parts) == 0 {
+		return newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	}
+
+	var data, etags, checksums []byte
+	for i, cp := range in.Parts {
+		if i > 0 && cp.PartNumber <= in.Parts[i-1].PartNumber {
+			return newError(http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order. The parts list must be specified in order of the part number.")
+		}
+		p := u.parts[cp.PartNumber]
+		if p == nil || strings.Trim(cp.ETag, `"`) != strings.Trim(p.etag, `"`) {
+			return newError(http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found. The part might not have been uploaded, or the specified entity tag might not have matched the part's entity tag.")
+		}
+		if v := cp.checksum(u.checksumAlgorithm); u.checksumAlgorithm != "" && v != "" && v != p.checksum {
+			return newError(http.StatusBadRequest, "InvalidPart", "The %s checksum of part %d did not match the uploaded part.", strings.ToLower(u.checksumAlgorithm), cp.PartNumber)
+		}
+		if i < len(in.Parts)-1 && len(p.data) < minPartSize {
+			return newError(http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.")
+		}
+		data = append(data, p.data...)
+		sum, _ := hex.DecodeString(strings.Trim(p.etag, `"`))
+		etags = append(etags, sum...)
+		raw, _ := base64.StdEncoding.DecodeString(p.checksum)
+		checksums = append(checksums, raw...)
+	}
+
+	sum := md5.Sum(etags)
+	o := &object{
+		key:                key,
+		versionID:          s.nextVersionID(b),
+		modified:           s.Now(),
+		data:               data,
+		etag:               fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(in.Parts)),
+		contentType:        u.headers.contentType,
+		contentEncoding:    u.headers.contentEncoding,
+		contentDisposition: u.headers.contentDisposition,
+		cacheControl:       u.headers.cacheControl,
+		metadata:           u.headers.metadata,
+		checksumAlgorithm:  u.checksumAlgorithm,
+		checksumType:       u.checksumType,
+	}
+	switch {
+	case u.checksumAlgorithm == "":
+	case u.checksumType == "FULL_OBJECT":
+		o.checksum = digest(u.checksumAlgorithm, data)
+	default:
+		o.checksum = fmt.Sprintf("%s-%d", digest(u.checksumAlgorithm, checksums), len(in.Parts))
+	}
+	b.put(o)
+	delete(s.uploads, u.id)
+
+	out := completeMultipartUploadResult{
+		Xmlns:        xmlns,
+		Location:     s.URL + "/" + b.name + "/" + key,
+		Bucket:       b.name,
+		Key:          key,
+		ETag:         o.etag,
+		ChecksumType: o.checksumType,
+	}
+	switch o.checksumAlgorithm {
+	case "CRC32":
+		out.ChecksumCRC32 = o.checksum
+	case "CRC32C":
+		out.ChecksumCRC32C = o.checksum
+	case "CRC64NVME":
+		out.ChecksumCRC64NVME = o.checksum
+	case "SHA1":
+		out.ChecksumSHA1 = o.checksum
+	case "SHA256":
+		out.ChecksumSHA256 = o.checksum
+	}
+	setVersionHeader(w, o)
+	writeXML(w, http.StatusOK, out)
+	return nil
+}
+
+func (s *Server) abortMultipartUpload(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	u, err := s.findUpload(r, b, key)
+	if err != nil {
+		return err
+	}
+	delete(s.uploads, u.id)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
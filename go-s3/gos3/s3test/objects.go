@@ -0,0 +1,526 @@
+package s3test
+
+// This file contains object level operations and request body handling.
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nullVersion   = "null"
+	maxKeyLength  = 1024
+	maxListKeys   = 1000
+	metaPrefix    = "X-Amz-Meta-"
+	checksumHdr   = "x-amz-checksum-"
+	streamingHash = "STREAMING-"
+)
+
+// checksumAlgorithms contains the supported checksum algorithms by name.
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"CRC32":     func() hash.Hash { return crc32.NewIEEE() },
+	"CRC32C":    func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"CRC64NVME": func() hash.Hash { return crc64.New(crc64.MakeTable(0x9a6c9329ac4bc9b5)) },
+	"SHA1":      sha1.New,
+	"SHA256":    sha256.New,
+}
+
+// object is a version of an object or a delete marker.
+type object struct {
+	key          string
+	versionID    string
+	deleteMarker bool
+	modified     time.Time
+
+	data               []byte
+	etag               string
+	contentType        string
+	contentEncoding    string
+	contentDisposition string
+	cacheControl       string
+	metadata           map[string]string
+
+	// checksumAlgorithm is the algorithm of checksum, which is a base64 digest
+	// or a composite checksum of parts
+	checksumAlgorithm string
+	checksum          string
+	checksumType      string
+}
+
+// headers contains the object headers taken from a PutObject or CreateMultipartUpload request.
+type headers struct {
+	contentType        string
+	contentEncoding    string
+	contentDisposition string
+	cacheControl       string
+	metadata           map[string]string
+}
+
+func readHeaders(r *http.Request) headers {
+	h := headers{
+		contentType:        r.Header.Get("Content-Type"),
+		contentDisposition: r.Header.Get("Content-Disposition"),
+		cacheControl:       r.Header.Get("Cache-Control"),
+		metadata:           make(map[string]string),
+	}
+	// aws-chunked is a transfer encoding and is not stored
+	var encodings []string
+	for _, e := range strings.Split(r.Header.Get("Content-Encoding"), ",") {
+		if e = strings.TrimSpace(e); e != "" && e != "aws-chunked" {
+			encodings = append(encodings, e)
+		}
+	}
+	h.contentEncoding = strings.Join(encodings, ",")
+	if h.contentType == "" {
+		h.contentType = "binary/octet-stream"
+	}
+	for k, v := range r.Header {
+		if strings.HasPrefix(k, metaPrefix) {
+			h.metadata[strings.ToLower(strings.TrimPrefix(k, metaPrefix))] = strings.Join(v, ",")
+		}
+	}
+	return h
+}
+
+// readBody reads and validates a request body, decoding aws-chunked bodies.
+// It returns the body and the checksum sent in a header or trailer, if any.
+func readBody(r *http.Request) (data []byte, algorithm, checksum string, err *s3Error) {
+	trailer := make(http.Header)
+	contentSHA := r.Header.Get("x-amz-content-sha256")
+	if strings.HasPrefix(contentSHA, streamingHash) || strings.Contains(r.Header.Get("Content-Encoding"), "aws-chunked") {
+		data, err = readChunked(r.Body, trailer)
+		if err != nil {
+			return nil, "", "", err
+		}
+		if n := r.Header.Get("x-amz-decoded-content-length"); n != "" && n != strconv.Itoa(len(data)) {
+			return nil, "", "", newError(http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.")
+		}
+	} else {
+		var rerr error
+		if data, rerr = io.ReadAll(r.Body); rerr != nil {
+			return nil, "", "", newError(http.StatusBadRequest, "IncompleteBody", "%v", rerr)
+		}
+		if len(contentSHA) == sha256.Size*2 {
+			sum := sha256.Sum256(data)
+			if hex.EncodeToString(sum[:]) != contentSHA {
+				return nil, "", "", newError(http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.")
+			}
+		}
+	}
+
+	if md5Header := r.Header.Get("Content-MD5"); md5Header != "" {
+		sum := md5.Sum(data)
+		if base64.StdEncoding.EncodeToString(sum[:]) != md5Header {
+			return nil, "", "", newError(http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what we received.")
+		}
+	}
+
+	for name := range checksumAlgorithms {
+		h := checksumHdr + strings.ToLower(name)
+		v := r.Header.Get(h)
+		if v == "" {
+			v = trailer.Get(h)
+		}
+		if v == "" {
+			continue
+		}
+		if algorithm != "" {
+			return nil, "", "", newError(http.StatusBadRequest, "InvalidRequest", "Expecting a single x-amz-checksum- header. Multiple checksum Types are not allowed.")
+		}
+		if digest(name, data) != v {
+			return nil, "", "", newError(http.StatusBadRequest, "BadDigest", "The %s you specified did not match the calculated checksum.", name)
+		}
+		algorithm, checksum = name, v
+	}
+	return data, algorithm, checksum, nil
+}
+
+// readChunked decodes an aws-chunked body, storing any trailing headers in trailer.
+// Chunk signatures are not verified.
+func readChunked(body io.Reader, trailer http.Header) ([]byte, *s3Error) {
+	malformed := newError(http.StatusBadRequest, "IncompleteBody", "The request body is not a valid aws-chunked encoding.")
+	br := bufio.NewReader(body)
+	var data bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, malformed
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil || size < 0 {
+			return nil, malformed
+		}
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&data, br, size); err != nil {
+			return nil, malformed
+		}
+		if crlf, err := br.ReadString('\n'); err != nil || strings.TrimSpace(crlf) != "" {
+			return nil, malformed
+		}
+	}
+	for {
+		line, err := br.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line != "" {
+			k, v, ok := strings.Cut(line, ":")
+			if !ok {
+				return nil, malformed
+			}
+			trailer.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+		}
+		if err != nil || line == "" {
+			break
+		}
+	}
+	return data.Bytes(), nil
+}
+
+// digest returns the base64 checksum of data using the named algorithm.
+func digest(algorithm string, data []byte) string {
+	h := checksumAlgorithms[algorithm]()
+	h.Write(data)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func validKey(key string) *s3Error {
+	if len(key) > maxKeyLength {
+		return newError(http.StatusBadRequest, "KeyTooLongError", "Your key is too long")
+	}
+	return nil
+}
+
+func (s *Server) putObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	if err := validKey(key); err != nil {
+		return err
+	}
+	data, algorithm, checksum, err := readBody(r)
+	if err != nil {
+		return err
+	}
+	h := readHeaders(r)
+	o := &object{
+		key:                key,
+		versionID:          s.nextVersionID(b),
+		modified:           s.Now(),
+		data:               data,
+		etag:               etag(data),
+		contentType:        h.contentType,
+		contentEncoding:    h.contentEncoding,
+		contentDisposition: h.contentDisposition,
+		cacheControl:       h.cacheControl,
+		metadata:           h.metadata,
+		checksumAlgorithm:  algorithm,
+		checksum:           checksum,
+	}
+	if algorithm != "" {
+		o.checksumType = "FULL_OBJECT"
+	}
+	b.put(o)
+
+	w.Header().Set("ETag", o.etag)
+	setVersionHeader(w, o)
+	if algorithm != "" {
+		w.Header().Set(checksumHdr+strings.ToLower(algorithm), checksum)
+		w.Header().Set("x-amz-checksum-type", o.checksumType)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func setVersionHeader(w http.ResponseWriter, o *object) {
+	if o.versionID != nullVersion {
+		w.Header().Set("x-amz-version-id", o.versionID)
+	}
+}
+
+// lookup returns the requested version of key, or the current version.
+func lookup(b *bucket, key string, q url.Values) (*object, *s3Error) {
+	if q.Has("versionId") {
+		o := b.version(key, q.Get("versionId"))
+		if o == nil {
+			return nil, newError(http.StatusNotFound, "NoSuchVersion", "The specified version does not exist.").withResource(key)
+		}
+		if o.deleteMarker {
+			return nil, newError(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.").
+				withHeader("x-amz-delete-marker", "true").withHeader("x-amz-version-id", o.versionID)
+		}
+		return o, nil
+	}
+	o := b.latest(key)
+	if o == nil {
+		return nil, errNoSuchKey(key)
+	}
+	if o.deleteMarker {
+		err := errNoSuchKey(key).withHeader("x-amz-delete-marker", "true")
+		if o.versionID != nullVersion {
+			err.withHeader("x-amz-version-id", o.versionID)
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// getObject handles GetObject and HeadObject requests, including single byte ranges.
+func (s *Server) getObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	o, err := lookup(b, key, r.URL.Query())
+	if err != nil {
+		return err
+	}
+
+	hdr := w.Header()
+	hdr.Set("ETag", o.etag)
+	hdr.Set("Last-Modified", o.modified.UTC().Format(http.TimeFormat))
+	hdr.Set("Content-Type", o.contentType)
+	hdr.Set("Accept-Ranges", "bytes")
+	setVersionHeader(w, o)
+	for k, v := range map[string]string{
+		"Content-Encoding":    o.contentEncoding,
+		"Content-Disposition": o.contentDisposition,
+		"Cache-Control":       o.cacheControl,
+	} {
+		if v != "" {
+			hdr.Set(k, v)
+		}
+	}
+	for k, v := range o.metadata {
+		hdr.Set(metaPrefix+k, v)
+	}
+
+	body, status := o.data, http.StatusOK
+	if rng := r.Header.Get("Range"); rng != "" {
+		start, end, ok := parseRange(rng, len(o.data))
+		if !ok {
+			return newError(http.StatusRequestedRangeNotSatisfiable, "InvalidRange", "The requested range is not satisfiable").
+				withHeader("Content-Range", "bytes */"+strconv.Itoa(len(o.data)))
+		}
+		body, status = o.data[start:end+1], http.StatusPartialContent
+		hdr.Set("Content-Range", "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(end)+"/"+strconv.Itoa(len(o.data)))
+	} else if o.checksumAlgorithm != "" && strings.EqualFold(r.Header.Get("x-amz-checksum-mode"), "ENABLED") {
+		hdr.Set(checksumHdr+strings.ToLower(o.checksumAlgorithm), o.checksum)
+		hdr.Set("x-amz-checksum-type", o.checksumType)
+	}
+	hdr.Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	if r.Method == http.MethodGet {
+		w.Write(body)
+	}
+	return nil
+}
+
+// parseRange parses a single range of the form bytes=a-b, bytes=a- or bytes=-n
+// and returns the inclusive bounds.
+func parseRange(h string, size int) (start, end int, ok bool) {
+	spec, found := strings.CutPrefix(h, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false
+	}
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false
+	}
+	var err error
+	switch {
+	case first == "":
+		n, err := strconv.Atoi(last)
+		if err != nil || n <= 0 || size == 0 {
+			return 0, 0, false
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true
+	case last == "":
+		if start, err = strconv.Atoi(first); err != nil || start >= size {
+			return 0, 0, false
+		}
+		return start, size - 1, true
+	}
+	if start, err = strconv.Atoi(first); err != nil || start >= size {
+		return 0, 0, false
+	}
+	if end, err = strconv.Atoi(last); err != nil || end < start {
+		return 0, 0, false
+	}
+	if end >= size {
+		end = size - 1
+	}
+	return start, end, true
+}
+
+// deleteObject deletes a version of an object, or adds a delete marker in a versioned bucket.
+func (s *Server) deleteObject(w http.ResponseWriter, r *http.Request, b *bucket, key string) *s3Error {
+	q := r.URL.Query()
+	switch {
+	case q.Has("versionId"):
+		if o := b.version(key, q.Get("versionId")); o != nil {
+			b.remove(key, o.versionID)
+			w.Header().Set("x-amz-version-id", o.versionID)
+			if o.deleteMarker {
+				w.Header().Set("x-amz-delete-marker", "true")
+			}
+		}
+	case b.versioning == "":
+		b.remove(key, nullVersion)
+	default:
+		marker := &object{key: key, versionID: s.nextVersionID(b), deleteMarker: true, modified: s.Now()}
+		b.put(marker)
+		setVersionHeader(w, marker)
+		w.Header().Set("x-amz-delete-marker", "true")
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"ListBucketResult"`
+	Xmlns                 string         `xml:"xmlns,attr"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	KeyCount              int            `xml:"KeyCount"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	Contents              []objectEntry  `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type objectEntry struct {
+	Key               string `xml:"Key"`
+	LastModified      string `xml:"LastModified"`
+	ETag              string `xml:"ETag"`
+	Size              int    `xml:"Size"`
+	StorageClass      string `xml:"StorageClass"`
+	ChecksumAlgorithm string `xml:"ChecksumAlgorithm,omitempty"`
+	ChecksumType      string `xml:"ChecksumType,omitempty"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// listObjectsV2 lists the current versions of objects in key order. Continuation
+// tokens encode the last key or common prefix returned.
+func (s *Server) listObjectsV2(w http.ResponseWriter, r *http.Request, name string) *s3Error {
+	b := s.buckets[name]
+	if b == nil {
+		return errNoSuchBucket(name)
+	}
+	q := r.URL.Query()
+	prefix, delimiter := q.Get("prefix"), q.Get("delimiter")
+	maxKeys := maxListKeys
+	if v := q.Get("max-keys"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return newError(http.StatusBadRequest, "InvalidArgument", "Provided max-keys not an integer or within integer range")
+		}
+		if n < maxKeys {
+			maxKeys = n
+		}
+	}
+	encode := func(v string) string { return v }
+	if et := q.Get("encoding-type"); et == "url" {
+		encode = url.QueryEscape
+	} else if et != "" {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Invalid Encoding Method specified in Request")
+	}
+
+	// after is the key or common prefix after which listing starts
+	after, afterPrefix := q.Get("start-after"), false
+	if token := q.Get("continuation-token"); token != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil || len(raw) == 0 {
+			return newError(http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect")
+		}
+		after, afterPrefix = string(raw[1:]), raw[0] == 'p'
+	}
+
+	out := listBucketResult{
+		Xmlns:             xmlns,
+		Name:              name,
+		Prefix:            encode(prefix),
+		Delimiter:         encode(delimiter),
+		MaxKeys:           maxKeys,
+		ContinuationToken: q.Get("continuation-token"),
+		StartAfter:        encode(q.Get("start-after")),
+		EncodingType:      q.Get("encoding-type"),
+	}
+	keys := make([]string, 0, len(b.objects))
+	for k := range b.objects {
+		if o := b.latest(k); strings.HasPrefix(k, prefix) && !o.deleteMarker {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	last := ""
+	for _, k := range keys {
+		if k <= after || (afterPrefix && strings.HasPrefix(k, after)) {
+			continue
+		}
+		cp := ""
+		if delimiter != "" {
+			if i := strings.Index(k[len(prefix):], delimiter); i >= 0 {
+				cp = k[:len(prefix)+i+len(delimiter)]
+			}
+		}
+		if cp != "" && cp == last {
+			continue
+		}
+		if out.KeyCount == maxKeys {
+			out.IsTruncated = true
+			break
+		}
+		out.KeyCount++
+		if cp != "" {
+			out.CommonPrefixes = append(out.CommonPrefixes, commonPrefix{Prefix: encode(cp)})
+			last = cp
+			out.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte("p" + cp))
+			continue
+		}
+		o := b.latest(k)
+		out.Contents = append(out.Contents, objectEntry{
+			Key:               encode(k),
+			LastModified:      isoTime(o.modified),
+			ETag:              o.etag,
+			Size:              len(o.data),
+			StorageClass:      "STANDARD",
+			ChecksumAlgorithm: o.checksumAlgorithm,
+			ChecksumType:      o.checksumType,
+		})
+		last = k
+		out.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte("k" + k))
+	}
+	if !out.IsTruncated {
+		out.NextContinuationToken = ""
+	}
+	writeXML(w, http.StatusOK, out)
+	return nil
+}
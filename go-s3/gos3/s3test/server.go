@@ -0,0 +1,422 @@
+// Package s3test provides a local S3-compatible HTTP server for testing
+// code built on the gos3 and v2/gos3 packages without network access.
+// The server supports path-style bucket addressing only and does not verify
+// request signatures. This file contains the Server type, request routing
+// and bucket level operations.
+package s3test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	awsv2 "github.com/aws/aws-sdk-go-v2/aws"
+	credentialsv2 "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/ggarcia209/go-aws/goaws"
+	goawsv2 "github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+const (
+	// Region is the region reported to clients created by the helpers.
+	Region = "us-east-1"
+
+	accessKeyID     = "s3test"
+	secretAccessKey = "s3test"
+
+	xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+)
+
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// Server is an in-memory S3-compatible server listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	uploads map[string]*upload
+	seq     int64
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewServer starts and returns a new Server. The caller should call Close when finished.
+func NewServer() *Server {
+	s := &Server{
+		buckets: make(map[string]*bucket),
+		uploads: make(map[string]*upload),
+		Now:     time.Now,
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Session returns a goaws.Session for the aws-sdk-go v1 clients pointed at the server.
+func (s *Server) Session() goaws.Session {
+	return goaws.NewSessionWithConfig(&aws.Config{
+		Credentials:      credentials.NewStaticCredentials(accessKeyID, secretAccessKey, ""),
+		Endpoint:         aws.String(s.URL),
+		Region:           aws.String(Region),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+	})
+}
+
+// AwsConfig returns a goaws.AwsConfig for the aws-sdk-go-v2 clients pointed at the server.
+// The server's address is an IP address, so the S3 client uses path-style requests.
+func (s *Server) AwsConfig() *goawsv2.AwsConfig {
+	return &goawsv2.AwsConfig{Config: awsv2.Config{
+		Credentials:  credentialsv2.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, ""),
+		BaseEndpoint: awsv2.String(s.URL),
+		Region:       Region,
+	}}
+}
+
+// CreateBucket creates a bucket, returning an error if the name is invalid or already in use.
+func (s *Server) CreateBucket(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.createBucket(name); err != nil {
+		return fmt.Errorf("%s: %s", err.Code, err.Message)
+	}
+	return nil
+}
+
+// bucket is an in-memory bucket.
+type bucket struct {
+	name       string
+	created    time.Time
+	versioning string // "", "Enabled" or "Suspended"
+	// objects maps keys to their versions, oldest first
+	objects map[string][]*object
+}
+
+// latest returns the current version of key, or nil.
+func (b *bucket) latest(key string) *object {
+	versions := b.objects[key]
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions[len(versions)-1]
+}
+
+// version returns the given version of key, or nil.
+func (b *bucket) version(key, versionID string) *object {
+	for _, o := range b.objects[key] {
+		if o.versionID == versionID {
+			return o
+		}
+	}
+	return nil
+}
+
+// put adds o as the current version of its key. Unless versioning is
+// enabled, o replaces the existing null version.
+func (b *bucket) put(o *object) {
+	versions := b.objects[o.key]
+	if o.versionID == nullVersion {
+		kept := versions[:0]
+		for _, v := range versions {
+			if v.versionID != nullVersion {
+				kept = append(kept, v)
+			}
+		}
+		versions = kept
+	}
+	b.objects[o.key] = append(versions, o)
+}
+
+// remove permanently deletes a version of key.
+func (b *bucket) remove(key, versionID string) {
+	versions := b.objects[key]
+	for i, v := range versions {
+		if v.versionID == versionID {
+			versions = append(versions[:i], versions[i+1:]...)
+			break
+		}
+	}
+	if len(versions) == 0 {
+		delete(b.objects, key)
+		return
+	}
+	b.objects[key] = versions
+}
+
+// nextVersionID returns the version ID for a new object in b. s.mu must be held.
+func (s *Server) nextVersionID(b *bucket) string {
+	if b.versioning != "Enabled" {
+		return nullVersion
+	}
+	s.seq++
+	return fmt.Sprintf("%032x", s.seq)
+}
+
+// s3Error is an S3 error response.
+type s3Error struct {
+	XMLName   xml.Name `xml:"Error"`
+	Code      string   `xml:"Code"`
+	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource,omitempty"`
+	RequestID string   `xml:"RequestId"`
+
+	status int
+	header http.Header
+}
+
+func newError(status int, code, format string, args ...any) *s3Error {
+	return &s3Error{status: status, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+func errNoSuchBucket(name string) *s3Error {
+	return newError(http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist").withResource(name)
+}
+
+func errNoSuchKey(key string) *s3Error {
+	return newError(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.").withResource(key)
+}
+
+func errNotImplemented() *s3Error {
+	return newError(http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented.")
+}
+
+func (e *s3Error) withResource(r string) *s3Error {
+	e.Resource = r
+	return e
+}
+
+func (e *s3Error) withHeader(k, v string) *s3Error {
+	if e.header == nil {
+		e.header = make(http.Header)
+	}
+	e.header.Set(k, v)
+	return e
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	requestID := fmt.Sprintf("%016X", s.seq)
+	w.Header().Set("x-amz-request-id", requestID)
+	if err := s.route(w, r); err != nil {
+		for k, v := range err.header {
+			w.Header()[k] = v
+		}
+		err.RequestID = requestID
+		if r.Method == http.MethodHead {
+			w.WriteHeader(err.status)
+			return
+		}
+		writeXML(w, err.status, err)
+	}
+}
+
+// route dispatches r to the handler for its operation.
+func (s *Server) route(w http.ResponseWriter, r *http.Request) *s3Error {
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/")
+	name, rawKey, hasKey := strings.Cut(path, "/")
+	key, err := url.PathUnescape(rawKey)
+	if err != nil {
+		return newError(http.StatusBadRequest, "InvalidURI", "Couldn't parse the specified URI.")
+	}
+	q := r.URL.Query()
+	// the v2 SDK names the operation in the query
+	q.Del("x-id")
+
+	if name == "" {
+		if r.Method != http.MethodGet {
+			return newError(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		}
+		return s.listBuckets(w)
+	}
+
+	if !hasKey || key == "" {
+		switch {
+		case r.Method == http.MethodPut && q.Has("versioning"):
+			return s.putBucketVersioning(w, r, name)
+		case r.Method == http.MethodGet && q.Has("versioning"):
+			return s.getBucketVersioning(w, name)
+		case r.Method == http.MethodGet && q.Get("list-type") == "2":
+			return s.listObjectsV2(w, r, name)
+		case len(q) > 0:
+			return errNotImplemented()
+		case r.Method == http.MethodPut:
+			if err := s.createBucket(name); err != nil {
+				return err
+			}
+			w.Header().Set("Location", "/"+name)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		case r.Method == http.MethodHead:
+			if s.buckets[name] == nil {
+				return errNoSuchBucket(name)
+			}
+			w.Header().Set("x-amz-bucket-region", Region)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		case r.Method == http.MethodDelete:
+			return s.deleteBucket(w, name)
+		}
+		return errNotImplemented()
+	}
+
+	b := s.buckets[name]
+	if b == nil {
+		return errNoSuchBucket(name)
+	}
+	switch {
+	case r.Header.Get("x-amz-copy-source") != "":
+		return errNotImplemented()
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		return s.createMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		return s.uploadPart(w, r, b, key)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		return s.completeMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		return s.abortMultipartUpload(w, r, b, key)
+	case r.Method == http.MethodPut && len(q) == 0:
+		return s.putObject(w, r, b, key)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && onlyParams(q, "versionId"):
+		return s.getObject(w, r, b, key)
+	case r.Method == http.MethodDelete && onlyParams(q, "versionId"):
+		return s.deleteObject(w, r, b, key)
+	}
+	return errNotImplemented()
+}
+
+// onlyParams reports whether q contains no parameters other than names.
+func onlyParams(q url.Values, names ...string) bool {
+	for k := range q {
+		found := false
+		for _, n := range names {
+			found = found || k == n
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// createBucket creates a bucket. s.mu must be held.
+func (s *Server) createBucket(name string) *s3Error {
+	if !bucketName.MatchString(name) || strings.Contains(name, "..") {
+		return newError(http.StatusBadRequest, "InvalidBucketName", "The specified bucket is not valid.").withResource(name)
+	}
+	if s.buckets[name] != nil {
+		return newError(http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.").withResource(name)
+	}
+	s.buckets[name] = &bucket{name: name, created: s.Now(), objects: make(map[string][]*object)}
+	return nil
+}
+
+func (s *Server) deleteBucket(w http.ResponseWriter, name string) *s3Error {
+	b := s.buckets[name]
+	if b == nil {
+		return errNoSuchBucket(name)
+	}
+	if len(b.objects) > 0 {
+		return newError(http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty").withResource(name)
+	}
+	for id, u := range s.uploads {
+		if u.bucket == b {
+			delete(s.uploads, id)
+		}
+	}
+	delete(s.buckets, name)
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Owner   struct {
+		ID string `xml:"ID"`
+	} `xml:"Owner"`
+	Buckets []bucketEntry `xml:"Buckets>Bucket"`
+}
+
+type bucketEntry struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+func (s *Server) listBuckets(w http.ResponseWriter) *s3Error {
+	out := listAllMyBucketsResult{Xmlns: xmlns}
+	out.Owner.ID = accessKeyID
+	names := make([]string, 0, len(s.buckets))
+	for name := range s.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.Buckets = append(out.Buckets, bucketEntry{Name: name, CreationDate: isoTime(s.buckets[name].created)})
+	}
+	writeXML(w, http.StatusOK, out)
+	return nil
+}
+
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"VersioningConfiguration"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status,omitempty"`
+}
+
+func (s *Server) putBucketVersioning(w http.ResponseWriter, r *http.Request, name string) *s3Error {
+	b := s.buckets[name]
+	if b == nil {
+		return errNoSuchBucket(name)
+	}
+	var in versioningConfiguration
+	if err := xml.NewDecoder(r.Body).Decode(&in); err != nil {
+		return newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema")
+	}
+	switch in.Status {
+	case "Enabled", "Suspended":
+		b.versioning = in.Status
+	default:
+		return newError(http.StatusBadRequest, "IllegalVersioningConfigurationException", "The Versioning element must be specified")
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+func (s *Server) getBucketVersioning(w http.ResponseWriter, name string) *s3Error {
+	b := s.buckets[name]
+	if b == nil {
+		return errNoSuchBucket(name)
+	}
+	writeXML(w, http.StatusOK, versioningConfiguration{Xmlns: xmlns, Status: b.versioning})
+	return nil
+}
+
+func writeXML(w http.ResponseWriter, status int, v any) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+// isoTime formats t as S3 does in XML responses.
+func isoTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
@@ -0,0 +1,272 @@
+package s3test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/ggarcia209/go-aws/go-s3/gos3"
+	gos3v2 "github.com/ggarcia209/go-aws/v2/gos3"
+)
+
+const testBucket = "acamoprjct-dev"
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	s := NewServer()
+	t.Cleanup(s.Close)
+	if err := s.CreateBucket(testBucket); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return s
+}
+
+func TestV1UploadAndGet(t *testing.T) {
+	server := newServer(t)
+	svc := gos3.NewS3(server.Session(), gos3.DefaultPartitionSize)
+
+	var tests = []struct {
+		key  string
+		size int
+	}{
+		{key: "img/test001.jpg", size: 1024},
+		{key: "img/test002.jpg", size: 0},
+		{key: "dir with spaces/test003+.txt", size: 10},
+	}
+	for _, test := range tests {
+		body := bytes.Repeat([]byte("a"), test.size)
+		if _, err := svc.UploadFile(testBucket, test.key, bytes.NewReader(body), true); err != nil {
+			t.Errorf("FAIL: %s: %v", test.key, err)
+			continue
+		}
+		got, err := svc.GetObject(testBucket, test.key)
+		if err != nil {
+			t.Errorf("FAIL: %s: %v", test.key, err)
+			continue
+		}
+		if !bytes.Equal(got, body) {
+			t.Errorf("FAIL - DATA: %s: %d bytes; want: %d", test.key, len(got), len(body))
+		}
+	}
+
+	if err := svc.DeleteFile(testBucket, "img/test001.jpg"); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if _, err := svc.GetObject(testBucket, "img/test001.jpg"); !errors.Is(err, gos3.ErrNoSuchKey) {
+		t.Errorf("FAIL: %v; want: %v", err, gos3.ErrNoSuchKey)
+	}
+	if _, err := svc.GetObject("missing-bucket", "img/test001.jpg"); err == nil {
+		t.Errorf("FAIL: missing bucket accepted")
+	}
+}
+
+func TestV1MultipartUpload(t *testing.T) {
+	server := newServer(t)
+	// the minimum part size is 5 MiB, so this uploads 3 parts
+	svc := gos3.NewS3(server.Session(), 5<<20)
+
+	body := make([]byte, 12<<20)
+	for i := range body {
+		body[i] = byte(i % 251)
+	}
+	resp, err := svc.UploadFile(testBucket, "large.bin", bytes.NewReader(body), false)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if resp.UploadID == "" {
+		t.Errorf("FAIL: upload was not multipart: %+v", resp)
+	}
+	got, err := svc.GetObject(testBucket, "large.bin")
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if !bytes.Equal(got, body) {
+		t.Errorf("FAIL - DATA: %d bytes; want: %d", len(got), len(body))
+	}
+
+	out, err := s3.NewFromConfig(server.AwsConfig().Config).HeadObject(context.Background(), &s3.HeadObjectInput{
+		Bucket: aws.String(testBucket),
+		Key:    aws.String("large.bin"),
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if etag := aws.ToString(out.ETag); !strings.HasSuffix(etag, `-3"`) {
+		t.Errorf("FAIL - DATA: ETag %s; want 3 parts", etag)
+	}
+}
+
+func TestV2UploadWithChecksum(t *testing.T) {
+	server := newServer(t)
+	svc := gos3v2.NewS3(*server.AwsConfig(), 0)
+	ctx := context.Background()
+
+	body := []byte("<html>receipt</html>")
+	sum := sha256.Sum256(body)
+	checksum := gos3v2.SHA256Checksum(base64.StdEncoding.EncodeToString(sum[:]))
+	bad := gos3v2.SHA256Checksum(base64.StdEncoding.EncodeToString(make([]byte, sha256.Size)))
+
+	var tests = []struct {
+		key      string
+		checksum *gos3v2.SHA256Checksum
+		wantErr  bool
+	}{
+		{key: "html/email-receipt-tmpl.html", checksum: &checksum},
+		{key: "html/no-checksum.html"},
+		{key: "html/bad-checksum.html", checksum: &bad, wantErr: true},
+	}
+	for _, test := range tests {
+		_, err := svc.UploadFile(ctx, gos3v2.UploadFileRequest{
+			Bucket:   testBucket,
+			Key:      test.key,
+			File:     bytes.NewReader(body),
+			Checksum: test.checksum,
+			Metadata: map[string]string{"owner": "it-eng"},
+		})
+		if (err != nil) != test.wantErr {
+			t.Errorf("FAIL: %s: %v; want error: %v", test.key, err, test.wantErr)
+		}
+	}
+
+	head, err := svc.HeadObject(ctx, gos3v2.GetFileRequest{Bucket: testBucket, Key: tests[0].key, UseChecksum: true})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if head.Sha256Checksum != string(checksum) || head.Metadata["owner"] != "it-eng" {
+		t.Errorf("FAIL - DATA: %+v", head)
+	}
+	got, err := svc.GetObject(ctx, gos3v2.GetFileRequest{Bucket: testBucket, Key: tests[0].key, UseChecksum: true})
+	if err != nil || !bytes.Equal(got, body) {
+		t.Errorf("FAIL: %q, %v", got, err)
+	}
+
+	for _, key := range []string{tests[1].key, tests[2].key} {
+		exists, err := svc.CheckIfObjectExists(ctx, gos3v2.GetFileRequest{Bucket: testBucket, Key: key})
+		if err != nil || exists != (key == tests[1].key) {
+			t.Errorf("FAIL: %s: %v, %v", key, exists, err)
+		}
+	}
+	if _, err := svc.GetObject(ctx, gos3v2.GetFileRequest{Bucket: testBucket, Key: "missing"}); !errors.Is(err, gos3v2.ErrItemNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, gos3v2.ErrItemNotFound)
+	}
+}
+
+func TestVersioning(t *testing.T) {
+	server := newServer(t)
+	ctx := context.Background()
+	client := s3.NewFromConfig(server.AwsConfig().Config)
+	svc := gos3v2.NewS3(*server.AwsConfig(), 0)
+
+	if _, err := client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  aws.String(testBucket),
+		VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+	}); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	versions := []string{}
+	for _, body := range []string{"v1", "v2"} {
+		resp, err := svc.UploadFile(ctx, gos3v2.UploadFileRequest{Bucket: testBucket, Key: "doc.txt", File: strings.NewReader(body)})
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		versions = append(versions, resp.VersionID)
+	}
+	if versions[0] == "" || versions[0] == versions[1] {
+		t.Fatalf("FAIL - DATA: versions %v", versions)
+	}
+
+	// deleting without a version adds a delete marker
+	if err := svc.DeleteFile(ctx, testBucket, "doc.txt", nil); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if _, err := svc.GetObject(ctx, gos3v2.GetFileRequest{Bucket: testBucket, Key: "doc.txt"}); !errors.Is(err, gos3v2.ErrItemNotFound) {
+		t.Errorf("FAIL: %v; want: %v", err, gos3v2.ErrItemNotFound)
+	}
+	out, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("doc.txt"), VersionId: aws.String(versions[0])})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	out.Body.Close()
+	if aws.ToString(out.VersionId) != versions[0] {
+		t.Errorf("FAIL - DATA: version %s; want: %s", aws.ToString(out.VersionId), versions[0])
+	}
+
+	// deleting a version removes it permanently; the delete marker stays current
+	if err := svc.DeleteFile(ctx, testBucket, "doc.txt", &versions[1]); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if _, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(testBucket), Key: aws.String("doc.txt"), VersionId: aws.String(versions[1])}); err == nil {
+		t.Errorf("FAIL: deleted version %s returned", versions[1])
+	}
+	list, err := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String(testBucket)})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(list.Contents) != 0 {
+		t.Errorf("FAIL: delete marker is current, listed %d objects", len(list.Contents))
+	}
+}
+
+func TestListObjectsV2(t *testing.T) {
+	server := newServer(t)
+	ctx := context.Background()
+	client := s3.NewFromConfig(server.AwsConfig().Config)
+
+	keys := []string{"a.txt", "img/1.jpg", "img/2.jpg", "img/raw/3.raw", "txt/4.txt", "z.txt"}
+	for _, key := range keys {
+		if _, err := client.PutObject(ctx, &s3.PutObjectInput{Bucket: aws.String(testBucket), Key: aws.String(key), Body: strings.NewReader(key)}); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+
+	var tests = []struct {
+		prefix    string
+		delimiter string
+		maxKeys   int32
+		want      string
+	}{
+		{maxKeys: 2, want: "[a.txt img/1.jpg img/2.jpg img/raw/3.raw txt/4.txt z.txt]"},
+		{delimiter: "/", maxKeys: 2, want: "[a.txt img/ txt/ z.txt]"},
+		{prefix: "img/", delimiter: "/", maxKeys: 1000, want: "[img/1.jpg img/2.jpg img/raw/]"},
+		{prefix: "none/", maxKeys: 10, want: "[]"},
+	}
+	for _, test := range tests {
+		got := []string{}
+		p := s3.NewListObjectsV2Paginator(client, &s3.ListObjectsV2Input{
+			Bucket:    aws.String(testBucket),
+			Prefix:    aws.String(test.prefix),
+			Delimiter: aws.String(test.delimiter),
+			MaxKeys:   aws.Int32(test.maxKeys),
+		})
+		for pages := 0; p.HasMorePages(); pages++ {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				t.Fatalf("FAIL: %v", err)
+			}
+			if pages > len(keys) {
+				t.Fatalf("FAIL: listing did not terminate")
+			}
+			for _, o := range page.Contents {
+				got = append(got, aws.ToString(o.Key))
+			}
+			for _, cp := range page.CommonPrefixes {
+				got = append(got, aws.ToString(cp.Prefix))
+			}
+		}
+		// contents and common prefixes are each in key order
+		sort.Strings(got)
+		if s := fmt.Sprint(got); s != test.want {
+			t.Errorf("FAIL - DATA: %s; want: %s", s, test.want)
+		}
+	}
+}
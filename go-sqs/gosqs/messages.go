@@ -11,6 +11,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/ggarcia209/go-aws/goaws"
 )
 
@@ -218,12 +219,18 @@ type SqsMessagesLogic interface {
 }
 
 type SqsMessages struct {
-	svc *sqs.SQS
+	svc sqsiface.SQSAPI
 }
 
 func NewSqsMessages(sess goaws.Session) *SqsMessages {
+	return NewSqsMessagesWithClient(sqs.New(sess.GetSession()))
+}
+
+// NewSqsMessagesWithClient returns an SqsMessages using the given client,
+// such as an in-memory implementation for tests.
+func NewSqsMessagesWithClient(svc sqsiface.SQSAPI) *SqsMessages {
 	return &SqsMessages{
-		svc: sqs.New(sess.GetSession()),
+		svc: svc,
 	}
 }
 
@@ -442,6 +449,9 @@ func (s *SqsMessages) DeleteMessageBatch(req DeleteMessageBatchRequest) (DeleteM
 func wrapBatchDeleteOutput(output *sqs.DeleteMessageBatchOutput, handles map[string]string) DeleteMessageBatchResponse {
 	wrapSuccessful := []BatchDeleteResultEntry{}
 	wrapFailed := []BatchDeleteErrEntry{}
+	if output == nil {
+		return DeleteMessageBatchResponse{Successful: wrapSuccessful, Failed: wrapFailed}
+	}
 	successful := output.Successful
 	failed := output.Failed
 
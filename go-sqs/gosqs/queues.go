@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/ggarcia209/go-aws/goaws"
 )
 
@@ -55,16 +56,22 @@ type SqsQueuesLogic interface {
 	CreateQueue(name string, options QueueOptions, tags map[string]*string) (string, error)
 	GetQueueURL(name string) (string, error)
 	DeleteQueue(url string) error
-	PurgeQueue(url string)
+	PurgeQueue(url string) error
 }
 
 type SqsQueues struct {
-	svc *sqs.SQS
+	svc sqsiface.SQSAPI
 }
 
 func NewSqsQueues(sess goaws.Session) *SqsQueues {
+	return NewSqsQueuesWithClient(sqs.New(sess.GetSession()))
+}
+
+// NewSqsQueuesWithClient returns an SqsQueues using the given client,
+// such as an in-memory implementation for tests.
+func NewSqsQueuesWithClient(svc sqsiface.SQSAPI) *SqsQueues {
 	return &SqsQueues{
-		svc: sqs.New(sess.GetSession()),
+		svc: svc,
 	}
 }
 
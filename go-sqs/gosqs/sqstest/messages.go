@@ -0,0 +1,637 @@
+package sqstest
+
+// This file contains message level operations.
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+const (
+	maxBatchEntries      = 10
+	maxMessageAttributes = 10
+	maxReceiveMessages   = 10
+	maxDelaySeconds      = 900
+	maxVisibilityTimeout = 43200
+	maxWaitTimeSeconds   = 20
+
+	// dedupInterval is the deduplication interval of FIFO queues, which also
+	// applies to ReceiveRequestAttemptId.
+	dedupInterval = 5 * time.Minute
+)
+
+// message is a message stored in a queue.
+type message struct {
+	id       string
+	body     string
+	attrs    map[string]*sqs.MessageAttributeValue
+	sysAttrs map[string]*sqs.MessageSystemAttributeValue
+	md5Body  string
+	md5Attrs string
+	md5Sys   string
+	groupID  string
+	dedupID  string
+	seq      string
+	sent     time.Time
+
+	// visibleAt is the end of the message's delay if it has not been
+	// received, or the end of its visibility timeout if it has.
+	visibleAt    time.Time
+	received     bool
+	receiveCount int
+	firstReceive time.Time
+	// handle is the most recently issued receipt handle
+	handle  string
+	deleted bool
+}
+
+// inflight reports whether m has been received and its visibility timeout has not expired.
+func (m *message) inflight(now time.Time) bool {
+	return m.received && now.Before(m.visibleAt)
+}
+
+// available reports whether m can be received.
+func (m *message) available(now time.Time) bool {
+	return !m.deleted && !now.Before(m.visibleAt)
+}
+
+// expire removes messages older than the queue's retention period. s.mu must be held.
+func (s *Service) expire(q *queue) {
+	now := s.Now()
+	retention := time.Duration(q.intAttr(sqs.QueueAttributeNameMessageRetentionPeriod)) * time.Second
+	kept := q.messages[:0]
+	for _, m := range q.messages {
+		if m.deleted {
+			continue
+		}
+		if now.Sub(m.sent) >= retention {
+			m.deleted = true
+			continue
+		}
+		kept = append(kept, m)
+	}
+	q.messages = kept
+	for k, m := range q.dedup {
+		if now.Sub(m.sent) >= dedupInterval {
+			delete(q.dedup, k)
+		}
+	}
+	for k, a := range q.attempts {
+		if now.Sub(a.at) >= dedupInterval {
+			delete(q.attempts, k)
+		}
+	}
+}
+
+// SendMessage adds a message to a queue. Messages sent to a FIFO queue
+// with a deduplication ID seen in the last 5 minutes are accepted but not delivered.
+func (s *Service) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if input.MessageBody == nil || *input.MessageBody == "" {
+		return nil, awserr.New(errCodeMissingParameter, "The request must contain the parameter MessageBody.", nil)
+	}
+	size := len(*input.MessageBody)
+	if len(input.MessageAttributes) > maxMessageAttributes {
+		return nil, invalidParameter("Number of message attributes [%d] exceeds the allowed maximum [%d].", len(input.MessageAttributes), maxMessageAttributes)
+	}
+	for name, av := range input.MessageAttributes {
+		n, err := attributeSize(name, av.DataType, av.StringValue, av.BinaryValue)
+		if err != nil {
+			return nil, err
+		}
+		size += n
+	}
+	if max := q.intAttr(sqs.QueueAttributeNameMaximumMessageSize); size > max {
+		return nil, invalidParameter("One or more parameters are invalid. Reason: Message must be shorter than %d bytes.", max)
+	}
+	for name, av := range input.MessageSystemAttributes {
+		if name != sqs.MessageSystemAttributeNameForSendsAwstraceHeader {
+			return nil, invalidParameter("Message system attribute name '%s' is invalid.", name)
+		}
+		if _, err := attributeSize(name, av.DataType, av.StringValue, av.BinaryValue); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.Now()
+	delay := int64(q.intAttr(sqs.QueueAttributeNameDelaySeconds))
+	if input.DelaySeconds != nil {
+		if *input.DelaySeconds < 0 || *input.DelaySeconds > maxDelaySeconds {
+			return nil, invalidParameter("Value %d for parameter DelaySeconds is invalid. Reason: must be between 0 and %d.", *input.DelaySeconds, maxDelaySeconds)
+		}
+		if q.fifo() && *input.DelaySeconds != 0 {
+			return nil, invalidParameter("Value %d for parameter DelaySeconds is invalid. Reason: The request include parameter that is not valid for this queue type.", *input.DelaySeconds)
+		}
+		if !q.fifo() {
+			delay = *input.DelaySeconds
+		}
+	}
+
+	m := &message{
+		body:      *input.MessageBody,
+		attrs:     input.MessageAttributes,
+		sysAttrs:  input.MessageSystemAttributes,
+		md5Body:   md5Hex([]byte(*input.MessageBody)),
+		md5Attrs:  md5OfAttributes(input.MessageAttributes),
+		md5Sys:    md5OfSystemAttributes(input.MessageSystemAttributes),
+		sent:      now,
+		visibleAt: now.Add(time.Duration(delay) * time.Second),
+	}
+
+	if !q.fifo() {
+		if aws.StringValue(input.MessageDeduplicationId) != "" {
+			return nil, invalidParameter("The request include parameter MessageDeduplicationId that is not valid for this queue type.")
+		}
+		s.seq++
+		m.id = messageID(s.seq)
+		q.messages = append(q.messages, m)
+		return sendOutput(m), nil
+	}
+
+	m.groupID = aws.StringValue(input.MessageGroupId)
+	if m.groupID == "" {
+		return nil, awserr.New(errCodeMissingParameter, "The request must contain the parameter MessageGroupId.", nil)
+	}
+	m.dedupID = aws.StringValue(input.MessageDeduplicationId)
+	if m.dedupID == "" {
+		if q.attrs[sqs.QueueAttributeNameContentBasedDeduplication] != "true" {
+			return nil, invalidParameter("The queue should either have ContentBasedDeduplication enabled or MessageDeduplicationId provided explicitly.")
+		}
+		sum := sha256.Sum256([]byte(m.body))
+		m.dedupID = hex.EncodeToString(sum[:])
+	}
+	key := m.dedupID
+	if q.attrs[sqs.QueueAttributeNameDeduplicationScope] == "messageGroup" {
+		key = m.groupID + "/" + m.dedupID
+	}
+	if orig, ok := q.dedup[key]; ok {
+		return sendOutput(orig), nil
+	}
+
+	s.seq++
+	m.id = messageID(s.seq)
+	m.seq = fmt.Sprintf("%020d", s.seq)
+	q.dedup[key] = m
+	q.messages = append(q.messages, m)
+	return sendOutput(m), nil
+}
+
+func sendOutput(m *message) *sqs.SendMessageOutput {
+	out := &sqs.SendMessageOutput{
+		MessageId:        aws.String(m.id),
+		MD5OfMessageBody: aws.String(m.md5Body),
+	}
+	if m.md5Attrs != "" {
+		out.MD5OfMessageAttributes = aws.String(m.md5Attrs)
+	}
+	if m.md5Sys != "" {
+		out.MD5OfMessageSystemAttributes = aws.String(m.md5Sys)
+	}
+	if m.seq != "" {
+		out.SequenceNumber = aws.String(m.seq)
+	}
+	return out
+}
+
+// ReceiveMessage returns up to 10 available messages and makes them
+// invisible for the visibility timeout. Messages received maxReceiveCount
+// times are moved to the queue's dead-letter queue instead of being returned.
+// ReceiveMessage never waits for messages to arrive.
+func (s *Service) ReceiveMessage(input *sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	max := aws.Int64Value(input.MaxNumberOfMessages)
+	if input.MaxNumberOfMessages == nil {
+		max = 1
+	}
+	if max < 1 || max > maxReceiveMessages {
+		return nil, invalidParameter("Value %d for parameter MaxNumberOfMessages is invalid. Reason: Must be between 1 and %d, if provided.", max, maxReceiveMessages)
+	}
+	visibility := int64(q.intAttr(sqs.QueueAttributeNameVisibilityTimeout))
+	if input.VisibilityTimeout != nil {
+		visibility = *input.VisibilityTimeout
+	}
+	if visibility < 0 || visibility > maxVisibilityTimeout {
+		return nil, invalidParameter("Value %d for parameter VisibilityTimeout is invalid. Reason: Must be between 0 and %d, if provided.", visibility, maxVisibilityTimeout)
+	}
+	if wait := aws.Int64Value(input.WaitTimeSeconds); wait < 0 || wait > maxWaitTimeSeconds {
+		return nil, invalidParameter("Value %d for parameter WaitTimeSeconds is invalid. Reason: Must be >= 0 and <= %d, if provided.", wait, maxWaitTimeSeconds)
+	}
+
+	now := s.Now()
+	attemptID := aws.StringValue(input.ReceiveRequestAttemptId)
+	if !q.fifo() {
+		attemptID = ""
+	}
+	var received []*message
+	if a := q.attempts[attemptID]; attemptID != "" && a != nil && a.valid(now) {
+		// retried attempt: return the same messages and handles
+		received = a.messages
+		for _, m := range received {
+			m.visibleAt = now.Add(time.Duration(visibility) * time.Second)
+		}
+	} else {
+		received = s.receive(q, now, int(max), visibility)
+		if attemptID != "" {
+			a := &receiveAttempt{at: now, messages: received}
+			for _, m := range received {
+				a.handles = append(a.handles, m.handle)
+			}
+			q.attempts[attemptID] = a
+		}
+	}
+
+	out := &sqs.ReceiveMessageOutput{}
+	for _, m := range received {
+		out.Messages = append(out.Messages, m.output(q, input.AttributeNames, input.MessageAttributeNames))
+	}
+	return out, nil
+}
+
+// valid reports whether every message returned by the attempt is still in
+// flight with the receipt handle it was returned with.
+func (a *receiveAttempt) valid(now time.Time) bool {
+	if len(a.messages) == 0 {
+		return false
+	}
+	for i, m := range a.messages {
+		if m.deleted || !m.inflight(now) || m.handle != a.handles[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// receive selects up to max available messages from q and marks them in flight. s.mu must be held.
+func (s *Service) receive(q *queue, now time.Time, max int, visibility int64) []*message {
+	var dlq *queue
+	if q.redrive != nil {
+		dlq = s.queueByARN(q.redrive.deadLetterTargetArn)
+	}
+	blocked := make(map[string]bool) // FIFO message groups with a message in flight or delayed
+	received := []*message{}
+	kept := q.messages[:0]
+	for _, m := range q.messages {
+		switch {
+		case m.deleted:
+			continue
+		case len(received) == max:
+		case q.fifo() && blocked[m.groupID]:
+		case !m.available(now):
+			blocked[m.groupID] = q.fifo()
+		case dlq != nil && m.receiveCount >= q.redrive.maxReceiveCount:
+			// move to the dead-letter queue, keeping its ID, body and sent timestamp
+			m.received, m.receiveCount, m.firstReceive, m.handle = false, 0, time.Time{}, ""
+			m.visibleAt = now
+			dlq.messages = append(dlq.messages, m)
+			continue
+		default:
+			s.seq++
+			m.received = true
+			m.receiveCount++
+			if m.firstReceive.IsZero() {
+				m.firstReceive = now
+			}
+			m.visibleAt = now.Add(time.Duration(visibility) * time.Second)
+			m.handle = receiptHandle(q.name, m.id, s.seq)
+			s.handles[m.handle] = m
+			received = append(received, m)
+		}
+		kept = append(kept, m)
+	}
+	q.messages = kept
+	return received
+}
+
+// output converts m to an sqs.Message, including the requested attributes.
+func (m *message) output(q *queue, attributeNames, messageAttributeNames []*string) *sqs.Message {
+	sys := map[string]string{
+		sqs.MessageSystemAttributeNameSenderId:                         "000000000000",
+		sqs.MessageSystemAttributeNameSentTimestamp:                    strconv.FormatInt(m.sent.UnixMilli(), 10),
+		sqs.MessageSystemAttributeNameApproximateReceiveCount:          strconv.Itoa(m.receiveCount),
+		sqs.MessageSystemAttributeNameApproximateFirstReceiveTimestamp: strconv.FormatInt(m.firstReceive.UnixMilli(), 10),
+	}
+	if q.fifo() {
+		sys[sqs.MessageSystemAttributeNameMessageGroupId] = m.groupID
+		sys[sqs.MessageSystemAttributeNameMessageDeduplicationId] = m.dedupID
+		sys[sqs.MessageSystemAttributeNameSequenceNumber] = m.seq
+	}
+	if av := m.sysAttrs[sqs.MessageSystemAttributeNameForSendsAwstraceHeader]; av != nil {
+		sys[sqs.MessageSystemAttributeNameAwstraceHeader] = aws.StringValue(av.StringValue)
+	}
+
+	out := &sqs.Message{
+		Body:          aws.String(m.body),
+		MD5OfBody:     aws.String(m.md5Body),
+		MessageId:     aws.String(m.id),
+		ReceiptHandle: aws.String(m.handle),
+	}
+	for _, n := range attributeNames {
+		name := aws.StringValue(n)
+		for k, v := range sys {
+			if name == sqs.QueueAttributeNameAll || name == k {
+				if out.Attributes == nil {
+					out.Attributes = make(map[string]*string)
+				}
+				out.Attributes[k] = aws.String(v)
+			}
+		}
+	}
+	for k, v := range m.attrs {
+		for _, n := range messageAttributeNames {
+			if matchAttributeName(aws.StringValue(n), k) {
+				if out.MessageAttributes == nil {
+					out.MessageAttributes = make(map[string]*sqs.MessageAttributeValue)
+				}
+				out.MessageAttributes[k] = v
+				break
+			}
+		}
+	}
+	if len(out.MessageAttributes) > 0 {
+		out.MD5OfMessageAttributes = aws.String(md5OfAttributes(out.MessageAttributes))
+	}
+	return out
+}
+
+// matchAttributeName reports whether a message attribute name matches a
+// requested name, which may be "All", ".*" or a prefix ending in ".*".
+func matchAttributeName(pattern, name string) bool {
+	switch {
+	case pattern == "All" || pattern == ".*":
+		return true
+	case strings.HasSuffix(pattern, ".*"):
+		return strings.HasPrefix(name, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == name
+}
+
+// DeleteMessage deletes the message with the given receipt handle. Deleting a
+// message that was already deleted succeeds. On standard queues a stale
+// handle succeeds without deleting the message, as in SQS; on FIFO queues it
+// returns ReceiptHandleIsInvalid.
+func (s *Service) DeleteMessage(input *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.deleteMessage(q, aws.StringValue(input.ReceiptHandle)); err != nil {
+		return nil, err
+	}
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+// deleteMessage deletes the message with the given handle from q. s.mu must be held.
+func (s *Service) deleteMessage(q *queue, handle string) error {
+	if handle == "" {
+		return awserr.New(errCodeMissingParameter, "The request must contain the parameter ReceiptHandle.", nil)
+	}
+	m := s.handles[handle]
+	if m == nil || !strings.HasPrefix(handle, receiptHandlePrefix(q.name)) {
+		return awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "The input receipt handle \""+handle+"\" is not a valid receipt handle.", nil)
+	}
+	switch {
+	case m.deleted:
+	case m.handle == handle:
+		m.deleted = true
+	case q.fifo():
+		return awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "The receipt handle has expired.", nil)
+	}
+	return nil
+}
+
+// DeleteMessageBatch deletes up to 10 messages.
+func (s *Service) DeleteMessageBatch(input *sqs.DeleteMessageBatchInput) (*sqs.DeleteMessageBatchOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	ids := []*string{}
+	for _, e := range input.Entries {
+		ids = append(ids, e.Id)
+	}
+	if err := validateBatch(ids); err != nil {
+		return nil, err
+	}
+	out := &sqs.DeleteMessageBatchOutput{}
+	for _, e := range input.Entries {
+		if err := s.deleteMessage(q, aws.StringValue(e.ReceiptHandle)); err != nil {
+			out.Failed = append(out.Failed, batchError(e.Id, err))
+			continue
+		}
+		out.Successful = append(out.Successful, &sqs.DeleteMessageBatchResultEntry{Id: e.Id})
+	}
+	return out, nil
+}
+
+// ChangeMessageVisibility sets the visibility timeout of an in flight message,
+// measured from the time of the call.
+func (s *Service) ChangeMessageVisibility(input *sqs.ChangeMessageVisibilityInput) (*sqs.ChangeMessageVisibilityOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.changeVisibility(q, aws.StringValue(input.ReceiptHandle), input.VisibilityTimeout); err != nil {
+		return nil, err
+	}
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// changeVisibility sets the visibility timeout of the message with the given handle. s.mu must be held.
+func (s *Service) changeVisibility(q *queue, handle string, timeout *int64) error {
+	if handle == "" {
+		return awserr.New(errCodeMissingParameter, "The request must contain the parameter ReceiptHandle.", nil)
+	}
+	if timeout == nil {
+		return awserr.New(errCodeMissingParameter, "The request must contain the parameter VisibilityTimeout.", nil)
+	}
+	if *timeout < 0 || *timeout > maxVisibilityTimeout {
+		return invalidParameter("Value %d for parameter VisibilityTimeout is invalid. Reason: Must be between 0 and %d.", *timeout, maxVisibilityTimeout)
+	}
+	m := s.handles[handle]
+	if m == nil || !strings.HasPrefix(handle, receiptHandlePrefix(q.name)) {
+		return awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "The input receipt handle \""+handle+"\" is not a valid receipt handle.", nil)
+	}
+	now := s.Now()
+	if m.deleted || m.handle != handle || !m.inflight(now) {
+		return awserr.New(sqs.ErrCodeMessageNotInflight, "Message does not exist or is not available for visibility timeout change.", nil)
+	}
+	m.visibleAt = now.Add(time.Duration(*timeout) * time.Second)
+	return nil
+}
+
+// ChangeMessageVisibilityBatch sets the visibility timeout of up to 10 messages.
+func (s *Service) ChangeMessageVisibilityBatch(input *sqs.ChangeMessageVisibilityBatchInput) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	ids := []*string{}
+	for _, e := range input.Entries {
+		ids = append(ids, e.Id)
+	}
+	if err := validateBatch(ids); err != nil {
+		return nil, err
+	}
+	out := &sqs.ChangeMessageVisibilityBatchOutput{}
+	for _, e := range input.Entries {
+		if err := s.changeVisibility(q, aws.StringValue(e.ReceiptHandle), e.VisibilityTimeout); err != nil {
+			out.Failed = append(out.Failed, batchError(e.Id, err))
+			continue
+		}
+		out.Successful = append(out.Successful, &sqs.ChangeMessageVisibilityBatchResultEntry{Id: e.Id})
+	}
+	return out, nil
+}
+
+// validateBatch checks the number and uniqueness of batch entry IDs.
+func validateBatch(ids []*string) error {
+	if len(ids) == 0 {
+		return awserr.New(sqs.ErrCodeEmptyBatchRequest, "There should be at least one entry in the request.", nil)
+	}
+	if len(ids) > maxBatchEntries {
+		return awserr.New(sqs.ErrCodeTooManyEntriesInBatchRequest, fmt.Sprintf("Maximum number of entries per request are %d. You have sent %d.", maxBatchEntries, len(ids)), nil)
+	}
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[aws.StringValue(id)] {
+			return awserr.New(sqs.ErrCodeBatchEntryIdsNotDistinct, "Id "+aws.StringValue(id)+" repeated.", nil)
+		}
+		seen[aws.StringValue(id)] = true
+	}
+	return nil
+}
+
+func batchError(id *string, err error) *sqs.BatchResultErrorEntry {
+	aerr := err.(awserr.Error)
+	return &sqs.BatchResultErrorEntry{
+		Id:          id,
+		Code:        aws.String(aerr.Code()),
+		Message:     aws.String(aerr.Message()),
+		SenderFault: aws.Bool(true),
+	}
+}
+
+// messageID returns a message ID in UUID format.
+func messageID(n int64) string {
+	return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+}
+
+func receiptHandlePrefix(queue string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(queue)) + "."
+}
+
+// receiptHandle returns a receipt handle for the nth receive of a message.
+func receiptHandle(queue, id string, n int64) string {
+	return receiptHandlePrefix(queue) + base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d", id, n)))
+}
+
+// attributeSize validates a message attribute and returns its size in bytes.
+func attributeSize(name string, dataType, str *string, bin []byte) (int, error) {
+	dt := aws.StringValue(dataType)
+	if name == "" {
+		return 0, invalidParameter("The request must contain non-empty message attribute name.")
+	}
+	base := strings.SplitN(dt, ".", 2)[0]
+	switch base {
+	case "String", "Number":
+		if aws.StringValue(str) == "" {
+			return 0, invalidParameter("Message (user) attribute '%s' must contain a non-empty value of type '%s'.", name, base)
+		}
+		if base == "Number" {
+			if _, err := strconv.ParseFloat(*str, 64); err != nil {
+				return 0, invalidParameter("Can't cast the value of message (user) attribute '%s' to a number.", name)
+			}
+		}
+		return len(name) + len(dt) + len(*str), nil
+	case "Binary":
+		if len(bin) == 0 {
+			return 0, invalidParameter("Message (user) attribute '%s' must contain a non-empty value of type 'Binary'.", name)
+		}
+		return len(name) + len(dt) + len(bin), nil
+	}
+	return 0, invalidParameter("The type of message (user) attribute '%s' is invalid. You must use only the following supported type prefixes: Binary, Number, String.", name)
+}
+
+func md5Hex(b []byte) string {
+	sum := md5.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// md5OfAttributes computes MD5OfMessageAttributes as SQS does: attributes are
+// sorted by name and each name, data type, transport type and value is
+// encoded with a 4 byte length prefix.
+func md5OfAttributes(attrs map[string]*sqs.MessageAttributeValue) string {
+	buf := []byte{}
+	for _, name := range sortedKeys(attrs) {
+		av := attrs[name]
+		buf = appendAttribute(buf, name, aws.StringValue(av.DataType), av.StringValue, av.BinaryValue)
+	}
+	if len(buf) == 0 {
+		return ""
+	}
+	return md5Hex(buf)
+}
+
+// md5OfSystemAttributes computes MD5OfMessageSystemAttributes.
+func md5OfSystemAttributes(attrs map[string]*sqs.MessageSystemAttributeValue) string {
+	buf := []byte{}
+	for _, name := range sortedKeys(attrs) {
+		av := attrs[name]
+		buf = appendAttribute(buf, name, aws.StringValue(av.DataType), av.StringValue, av.BinaryValue)
+	}
+	if len(buf) == 0 {
+		return ""
+	}
+	return md5Hex(buf)
+}
+
+func appendAttribute(buf []byte, name, dataType string, str *string, bin []byte) []byte {
+	buf = appendLengthPrefixed(buf, []byte(name))
+	buf = appendLengthPrefixed(buf, []byte(dataType))
+	if str != nil {
+		buf = append(buf, 1)
+		return appendLengthPrefixed(buf, []byte(*str))
+	}
+	buf = append(buf, 2)
+	return appendLengthPrefixed(buf, bin)
+}
+
+func appendLengthPrefixed(buf, b []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+	return append(buf, b...)
+}
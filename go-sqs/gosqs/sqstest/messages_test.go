@@ -0,0 +1,333 @@
+package sqstest
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/ggarcia209/go-aws/go-sqs/gosqs"
+)
+
+// receive receives up to max messages with the default 30 second visibility timeout.
+func receive(t *testing.T, messages *gosqs.SqsMessages, url, attemptID string, max int64) []gosqs.Message {
+	t.Helper()
+	options := gosqs.RecMsgDefault
+	options.QueueURL = url
+	options.MaxNumberOfMessages = max
+	options.ReceiveRequestAttemptId = attemptID
+	msgs, err := messages.ReceiveMessage(options)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	return msgs
+}
+
+func bodies(msgs []gosqs.Message) []string {
+	out := []string{}
+	for _, m := range msgs {
+		out = append(out, m.Body)
+	}
+	return out
+}
+
+func TestVisibilityTimeout(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, _ := New(clock)
+	url, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	sent, err := messages.SendMessage(gosqs.SendMsgOptions{
+		QueueURL:          url,
+		MessageBody:       "msg-test001",
+		MessageAttributes: gosqs.CreateMsgAttributes([]gosqs.MsgAV{gosqs.CreateMsgAttribute("department", "String", "IT-Eng")}),
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	first := receive(t, messages, url, "", 1)
+	if len(first) != 1 || first[0].MessageId != sent.MessageId || first[0].MD5OfBody != sent.MD5OfMessageBody ||
+		first[0].MD5OfMessagefAttributes != sent.MD5OfMessageAttributes || first[0].Attributes["ApproximateReceiveCount"] != "1" {
+		t.Fatalf("FAIL - DATA: %+v; sent: %+v", first, sent)
+	}
+	// invisible until the timeout expires
+	clock.Advance(29 * time.Second)
+	if got := receive(t, messages, url, "", 1); len(got) != 0 {
+		t.Errorf("FAIL: received in flight message: %+v", got)
+	}
+	clock.Advance(time.Second)
+	second := receive(t, messages, url, "", 1)
+	if len(second) != 1 || second[0].ReceiptHandle == first[0].ReceiptHandle || second[0].Attributes["ApproximateReceiveCount"] != "2" {
+		t.Fatalf("FAIL - DATA: %+v", second)
+	}
+
+	// a stale handle succeeds on a standard queue but does not delete the message
+	if err := messages.DeleteMessage(url, first[0].ReceiptHandle); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+	clock.Advance(30 * time.Second)
+	third := receive(t, messages, url, "", 1)
+	if len(third) != 1 {
+		t.Fatalf("FAIL: message deleted with stale handle")
+	}
+
+	// extend, then delete with the current handle
+	resp, err := messages.ChangeMessageVisibilityBatch(gosqs.BatchUpdateVisibilityTimeoutRequest{
+		QueueURL:       url,
+		MessageIDs:     []string{"m0", "m1"},
+		ReceiptHandles: []string{third[0].ReceiptHandle, second[0].ReceiptHandle},
+		TimeoutSeconds: 120,
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(resp.Successful) != 1 || len(resp.Failed) != 1 || resp.Failed[0].ErrorCode != sqs.ErrCodeMessageNotInflight {
+		t.Errorf("FAIL - DATA: %+v", resp)
+	}
+	clock.Advance(60 * time.Second)
+	if got := receive(t, messages, url, "", 1); len(got) != 0 {
+		t.Errorf("FAIL: visibility timeout not extended: %+v", got)
+	}
+	if err := messages.DeleteMessage(url, third[0].ReceiptHandle); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	clock.Advance(60 * time.Second)
+	if got := receive(t, messages, url, "", 1); len(got) != 0 {
+		t.Errorf("FAIL: deleted message received: %+v", got)
+	}
+	if err := messages.DeleteMessage(url, "invalid"); err == nil {
+		t.Errorf("FAIL: invalid handle accepted")
+	}
+}
+
+func TestDelaySeconds(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, _ := New(clock)
+	options := gosqs.QueueDefault
+	options.DelaySeconds = "10"
+	url, err := queues.CreateQueue("test-001", options, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	// the wrapper always sends DelaySeconds, which overrides the queue default
+	if _, err := messages.SendMessage(gosqs.SendMsgOptions{QueueURL: url, MessageBody: "a", DelaySeconds: 5}); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if _, err := messages.SendMessage(gosqs.SendMsgOptions{QueueURL: url, MessageBody: "b", DelaySeconds: 20}); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	var tests = []struct {
+		advance time.Duration
+		want    int
+	}{
+		{advance: 0, want: 0},
+		{advance: 5 * time.Second, want: 1},
+		{advance: 14 * time.Second, want: 0},
+		{advance: time.Second, want: 1},
+	}
+	for i, test := range tests {
+		clock.Advance(test.advance)
+		if got := receive(t, messages, url, "", 10); len(got) != test.want {
+			t.Errorf("FAIL: step %d: %d messages; want: %d", i, len(got), test.want)
+		}
+	}
+}
+
+func TestFifoOrdering(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, _ := New(clock)
+	url, err := queues.CreateQueue("test-001.fifo", fifoOptions, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	sends := []struct{ group, body string }{
+		{"A", "a1"}, {"B", "b1"}, {"A", "a2"}, {"B", "b2"}, {"A", "a3"},
+	}
+	for i, s := range sends {
+		if _, err := messages.SendMessage(gosqs.SendMsgOptions{
+			QueueURL:               url,
+			MessageBody:            s.body,
+			MessageGroupId:         s.group,
+			MessageDeduplicationId: fmt.Sprintf("dedup-%d", i),
+		}); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+
+	// a group is blocked while one of its messages is in flight
+	first := receive(t, messages, url, "attempt-1", 1)
+	second := receive(t, messages, url, "attempt-2", 10)
+	if got := bodies(first); len(got) != 1 || got[0] != "a1" {
+		t.Errorf("FAIL - DATA: %v; want: [a1]", got)
+	}
+	if got := fmt.Sprint(bodies(second)); got != "[b1 b2]" {
+		t.Errorf("FAIL - DATA: %v; want: [b1 b2]", got)
+	}
+	if got := receive(t, messages, url, "attempt-3", 10); len(got) != 0 {
+		t.Errorf("FAIL - DATA: %v; want: []", bodies(got))
+	}
+
+	// a retried attempt returns the same messages and handles
+	retry := receive(t, messages, url, "attempt-1", 1)
+	if len(retry) != 1 || retry[0].ReceiptHandle != first[0].ReceiptHandle {
+		t.Errorf("FAIL - DATA: %+v; want: %+v", retry, first)
+	}
+
+	if err := messages.DeleteMessage(url, first[0].ReceiptHandle); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if got := fmt.Sprint(bodies(receive(t, messages, url, "attempt-4", 10))); got != "[a2 a3]" {
+		t.Errorf("FAIL - DATA: %v; want: [a2 a3]", got)
+	}
+
+	// a stale handle is rejected on FIFO queues
+	clock.Advance(30 * time.Second)
+	again := receive(t, messages, url, "attempt-5", 10)
+	if len(again) != 4 {
+		t.Fatalf("FAIL: %d messages; want: 4", len(again))
+	}
+	if err := messages.DeleteMessage(url, second[0].ReceiptHandle); errCode(err) != sqs.ErrCodeReceiptHandleIsInvalid {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodeReceiptHandleIsInvalid)
+	}
+}
+
+func TestFifoDeduplication(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, svc := New(clock)
+	options := fifoOptions
+	options.ContentBasedDeduplication = "true"
+	url, err := queues.CreateQueue("test-001.fifo", options, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	var tests = []struct {
+		advance time.Duration
+		body    string
+		dedupID string
+		dupOf   int // index of the original send, or -1
+	}{
+		{body: "a", dedupID: "d1", dupOf: -1},
+		{body: "b", dedupID: "d1", dupOf: 0},
+		{advance: 4 * time.Minute, body: "c", dedupID: "d2", dupOf: -1},
+		{advance: time.Minute, body: "d", dedupID: "d1", dupOf: -1}, // window expired
+		{body: "d", dedupID: "d2", dupOf: 2},
+	}
+	ids := []string{}
+	for i, test := range tests {
+		clock.Advance(test.advance)
+		resp, err := messages.SendMessage(gosqs.SendMsgOptions{
+			QueueURL:               url,
+			MessageBody:            test.body,
+			MessageGroupId:         "g",
+			MessageDeduplicationId: test.dedupID,
+		})
+		if err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+		ids = append(ids, resp.MessageId)
+		if test.dupOf >= 0 && resp.MessageId != ids[test.dupOf] {
+			t.Errorf("FAIL: send %d: %s; want duplicate of %s", i, resp.MessageId, ids[test.dupOf])
+		}
+		if test.dupOf < 0 && i > 0 && resp.MessageId == ids[i-1] {
+			t.Errorf("FAIL: send %d deduplicated", i)
+		}
+	}
+	if got := fmt.Sprint(bodies(receive(t, messages, url, "attempt-1", 10))); got != "[a c d]" {
+		t.Errorf("FAIL - DATA: %v; want: [a c d]", got)
+	}
+
+	// the wrapper always sets MessageGroupId; the service requires it
+	_, err = svc.SendMessage(&sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x")})
+	if errCode(err) != "MissingParameter" {
+		t.Errorf("FAIL: %v; want: MissingParameter", err)
+	}
+	// content based deduplication uses the body
+	for i := 0; i < 2; i++ {
+		if _, err := svc.SendMessage(&sqs.SendMessageInput{QueueUrl: aws.String(url), MessageBody: aws.String("x"), MessageGroupId: aws.String("h")}); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+	if got := receive(t, messages, url, "attempt-2", 10); len(got) != 1 {
+		t.Errorf("FAIL - DATA: %v; want: [x]", bodies(got))
+	}
+}
+
+func TestRedrivePolicy(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, _ := New(clock)
+	dlq, err := queues.CreateQueue("test-001-dlq", gosqs.QueueDefault, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	options := gosqs.QueueDefault
+	options.RedrivePolicy = `{"deadLetterTargetArn":"` + ARNPrefix + `test-001-dlq","maxReceiveCount":"2"}`
+	url, err := queues.CreateQueue("test-001", options, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	sent, err := messages.SendMessage(gosqs.SendMsgOptions{QueueURL: url, MessageBody: "poison"})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if got := receive(t, messages, url, "", 1); len(got) != 1 {
+			t.Fatalf("FAIL: receive %d: %d messages; want: 1", i, len(got))
+		}
+		clock.Advance(30 * time.Second)
+	}
+	if got := receive(t, messages, url, "", 1); len(got) != 0 {
+		t.Errorf("FAIL: received after maxReceiveCount: %+v", got)
+	}
+	got := receive(t, messages, dlq, "", 1)
+	if len(got) != 1 || got[0].MessageId != sent.MessageId || got[0].Attributes["ApproximateReceiveCount"] != "1" {
+		t.Errorf("FAIL - DATA: %+v", got)
+	}
+}
+
+func TestDeleteMessageBatch(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, _ := New(clock)
+	url, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := messages.SendMessage(gosqs.SendMsgOptions{QueueURL: url, MessageBody: fmt.Sprint(i)}); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+	msgs := receive(t, messages, url, "", 10)
+	req := gosqs.DeleteMessageBatchRequest{QueueURL: url}
+	for _, m := range msgs {
+		req.MessageIDs = append(req.MessageIDs, m.MessageId)
+		req.ReceiptHandles = append(req.ReceiptHandles, m.ReceiptHandle)
+	}
+	req.MessageIDs = append(req.MessageIDs, "bad")
+	req.ReceiptHandles = append(req.ReceiptHandles, "bad-handle")
+
+	resp, err := messages.DeleteMessageBatch(req)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if len(resp.Successful) != 3 || len(resp.Failed) != 1 ||
+		resp.Failed[0].ReceiptHandle != "bad-handle" || resp.Failed[0].ErrorCode != sqs.ErrCodeReceiptHandleIsInvalid {
+		t.Errorf("FAIL - DATA: %+v", resp)
+	}
+	clock.Advance(time.Minute)
+	if got := receive(t, messages, url, "", 10); len(got) != 0 {
+		t.Errorf("FAIL: deleted messages received: %v", bodies(got))
+	}
+
+	// errors from the service are returned without a partial response
+	req.QueueURL = URLPrefix + "missing"
+	if _, err := messages.DeleteMessageBatch(req); errCode(err) != sqs.ErrCodeQueueDoesNotExist {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodeQueueDoesNotExist)
+	}
+}
@@ -0,0 +1,144 @@
+package sqstest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/ggarcia209/go-aws/go-sqs/gosqs"
+)
+
+var (
+	_ gosqs.SqsQueuesLogic   = (*gosqs.SqsQueues)(nil)
+	_ gosqs.SqsMessagesLogic = (*gosqs.SqsMessages)(nil)
+)
+
+var epoch = time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC)
+
+var fifoOptions = gosqs.QueueOptions{
+	DelaySeconds:                  "0",
+	MaximumMessageSize:            "262144",
+	MessageRetentionPeriod:        "345600",
+	ReceiveMessageWaitTimeSeconds: "0",
+	VisibilityTimeout:             "30",
+	KmsDataKeyReusePeriodSeconds:  "300",
+	FifoQueue:                     "true",
+	ContentBasedDeduplication:     "false",
+	DeduplicationScope:            "queue",
+	FifoThroughputLimit:           "perQueue",
+}
+
+// errCode returns the AWS error code wrapped by err.
+func errCode(err error) string {
+	var aerr awserr.Error
+	if errors.As(err, &aerr) {
+		return aerr.Code()
+	}
+	return ""
+}
+
+func TestCreateQueue(t *testing.T) {
+	var tests = []struct {
+		name     string
+		options  gosqs.QueueOptions
+		wantCode string
+	}{
+		{name: "test-001", options: gosqs.QueueDefault},
+		{name: "test-001", options: gosqs.QueueDefault}, // identical attributes
+		{name: "test-002.fifo", options: fifoOptions},
+		{name: "test-003", options: fifoOptions, wantCode: "InvalidParameterValue"},
+		{name: "test-004.fifo", options: gosqs.QueueDefault, wantCode: "InvalidParameterValue"},
+		{name: "test-005", options: gosqs.QueueOptions{VisibilityTimeout: "43201"}, wantCode: sqs.ErrCodeInvalidAttributeValue},
+		{name: "test-006", options: gosqs.QueueOptions{RedrivePolicy: `{"deadLetterTargetArn":"` + ARNPrefix + `missing","maxReceiveCount":3}`}, wantCode: sqs.ErrCodeInvalidAttributeValue},
+		{name: "test 007", options: gosqs.QueueDefault, wantCode: "InvalidParameterValue"},
+	}
+
+	queues, _, _ := New(NewClock(epoch))
+	for _, test := range tests {
+		url, err := queues.CreateQueue(test.name, test.options, nil)
+		if code := errCode(err); code != test.wantCode {
+			t.Errorf("FAIL: %s: %v; want: %s", test.name, err, test.wantCode)
+			continue
+		}
+		if err == nil && url != URLPrefix+test.name {
+			t.Errorf("FAIL - DATA: %s; want: %s", url, URLPrefix+test.name)
+		}
+	}
+
+	// same name with different attributes
+	options := gosqs.QueueDefault
+	options.VisibilityTimeout = "60"
+	if _, err := queues.CreateQueue("test-001", options, nil); errCode(err) != sqs.ErrCodeQueueNameExists {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodeQueueNameExists)
+	}
+}
+
+func TestDeleteQueue(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, _, _ := New(clock)
+
+	url, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if got, err := queues.GetQueueURL("test-001"); err != nil || got != url {
+		t.Errorf("FAIL: %s, %v; want: %s", got, err, url)
+	}
+	if err := queues.DeleteQueue(url); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if err := queues.DeleteQueue(url); err == nil || err.Error() != gosqs.ErrAWSNonExistentQueue {
+		t.Errorf("FAIL: %v; want: %s", err, gosqs.ErrAWSNonExistentQueue)
+	}
+	if _, err := queues.GetQueueURL("test-001"); errCode(err) != sqs.ErrCodeQueueDoesNotExist {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodeQueueDoesNotExist)
+	}
+
+	// the name can be reused after 60 seconds
+	if _, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil); errCode(err) != sqs.ErrCodeQueueDeletedRecently {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodeQueueDeletedRecently)
+	}
+	clock.Advance(time.Minute)
+	if _, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+}
+
+func TestPurgeQueue(t *testing.T) {
+	clock := NewClock(epoch)
+	queues, messages, svc := New(clock)
+
+	url, err := queues.CreateQueue("test-001", gosqs.QueueDefault, nil)
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	for _, body := range []string{"a", "b", "c"} {
+		if _, err := messages.SendMessage(gosqs.SendMsgOptions{QueueURL: url, MessageBody: body}); err != nil {
+			t.Fatalf("FAIL: %v", err)
+		}
+	}
+	if err := queues.PurgeQueue(url); err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	out, err := svc.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+		QueueUrl:       aws.String(url),
+		AttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameApproximateNumberOfMessages}),
+	})
+	if err != nil {
+		t.Fatalf("FAIL: %v", err)
+	}
+	if n := aws.StringValue(out.Attributes[sqs.QueueAttributeNameApproximateNumberOfMessages]); n != "0" {
+		t.Errorf("FAIL: %s messages; want: 0", n)
+	}
+
+	if err := queues.PurgeQueue(url); errCode(err) != sqs.ErrCodePurgeQueueInProgress {
+		t.Errorf("FAIL: %v; want: %s", err, sqs.ErrCodePurgeQueueInProgress)
+	}
+	clock.Advance(time.Minute)
+	if err := queues.PurgeQueue(url); err != nil {
+		t.Errorf("FAIL: %v", err)
+	}
+}
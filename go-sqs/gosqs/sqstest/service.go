@@ -0,0 +1,480 @@
+// Package sqstest provides an in-memory implementation of the SQS API
+// for testing code built on the gosqs package without a live AWS account.
+// Time is read from Service.Now, which can be driven by a Clock for deterministic tests.
+// This file contains the Service type and queue level operations.
+package sqstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/ggarcia209/go-aws/go-sqs/gosqs"
+)
+
+const (
+	// URLPrefix is prepended to queue names to form queue URLs.
+	URLPrefix = "https://sqs.local.amazonaws.com/000000000000/"
+	// ARNPrefix is prepended to queue names to form queue ARNs.
+	ARNPrefix = "arn:aws:sqs:local:000000000000:"
+
+	errCodeInvalidParameterValue = "InvalidParameterValue"
+	errCodeMissingParameter      = "MissingParameter"
+
+	// purgeInterval is the minimum time between PurgeQueue calls on a queue.
+	purgeInterval = 60 * time.Second
+	// recreateInterval is the minimum time before a deleted queue's name can be reused.
+	recreateInterval = 60 * time.Second
+)
+
+// queueDefaults contains the settable attributes of a new queue and their default values.
+var queueDefaults = map[string]string{
+	sqs.QueueAttributeNameDelaySeconds:                  "0",
+	sqs.QueueAttributeNameMaximumMessageSize:            "262144",
+	sqs.QueueAttributeNameMessageRetentionPeriod:        "345600",
+	sqs.QueueAttributeNamePolicy:                        "",
+	sqs.QueueAttributeNameReceiveMessageWaitTimeSeconds: "0",
+	sqs.QueueAttributeNameRedrivePolicy:                 "",
+	sqs.QueueAttributeNameVisibilityTimeout:             "30",
+	sqs.QueueAttributeNameKmsMasterKeyId:                "",
+	sqs.QueueAttributeNameKmsDataKeyReusePeriodSeconds:  "300",
+}
+
+// fifoDefaults contains the additional attributes of a new FIFO queue.
+var fifoDefaults = map[string]string{
+	sqs.QueueAttributeNameFifoQueue:                 "true",
+	sqs.QueueAttributeNameContentBasedDeduplication: "false",
+	sqs.QueueAttributeNameDeduplicationScope:        "queue",
+	sqs.QueueAttributeNameFifoThroughputLimit:       "perQueue",
+}
+
+// attributeRanges contains the valid range of numeric queue attributes.
+var attributeRanges = map[string][2]int{
+	sqs.QueueAttributeNameDelaySeconds:                  {0, 900},
+	sqs.QueueAttributeNameMaximumMessageSize:            {1024, 262144},
+	sqs.QueueAttributeNameMessageRetentionPeriod:        {60, 1209600},
+	sqs.QueueAttributeNameReceiveMessageWaitTimeSeconds: {0, 20},
+	sqs.QueueAttributeNameVisibilityTimeout:             {0, 43200},
+	sqs.QueueAttributeNameKmsDataKeyReusePeriodSeconds:  {60, 86400},
+}
+
+// Clock is a manually advanced clock.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a Clock set to t.
+func NewClock(t time.Time) *Clock {
+	return &Clock{now: t}
+}
+
+// Now returns the clock's current time.
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Service is an in-memory implementation of sqsiface.SQSAPI.
+// ReceiveMessage never blocks; WaitTimeSeconds is accepted but ignored.
+// Operations not implemented by Service panic.
+type Service struct {
+	sqsiface.SQSAPI
+
+	mu      sync.Mutex
+	queues  map[string]*queue // keyed by name
+	deleted map[string]time.Time
+	handles map[string]*message
+	seq     int64
+
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// NewService returns an empty Service.
+func NewService() *Service {
+	return &Service{
+		queues:  make(map[string]*queue),
+		deleted: make(map[string]time.Time),
+		handles: make(map[string]*message),
+		Now:     time.Now,
+	}
+}
+
+// New returns gosqs clients backed by a new Service whose time is read from clock.
+func New(clock *Clock) (*gosqs.SqsQueues, *gosqs.SqsMessages, *Service) {
+	s := NewService()
+	s.Now = clock.Now
+	return gosqs.NewSqsQueuesWithClient(s), gosqs.NewSqsMessagesWithClient(s), s
+}
+
+// queue is an in-memory queue.
+type queue struct {
+	name     string
+	attrs    map[string]string
+	tags     map[string]string
+	created  time.Time
+	modified time.Time
+	purged   time.Time
+
+	// messages are stored in the order they were sent
+	messages []*message
+	// dedup maps deduplication IDs to the message first sent with them
+	dedup map[string]*message
+	// attempts maps FIFO receive request attempt IDs to the messages they returned
+	attempts map[string]*receiveAttempt
+
+	redrive *redrivePolicy
+}
+
+type redrivePolicy struct {
+	deadLetterTargetArn string
+	maxReceiveCount     int
+}
+
+type receiveAttempt struct {
+	at       time.Time
+	messages []*message
+	handles  []string
+}
+
+func (q *queue) url() string { return URLPrefix + q.name }
+
+func (q *queue) arn() string { return ARNPrefix + q.name }
+
+func (q *queue) fifo() bool { return q.attrs[sqs.QueueAttributeNameFifoQueue] == "true" }
+
+// intAttr returns the value of a numeric attribute.
+func (q *queue) intAttr(name string) int {
+	n, _ := strconv.Atoi(q.attrs[name])
+	return n
+}
+
+func queueNotFound() error {
+	return awserr.New(sqs.ErrCodeQueueDoesNotExist, "The specified queue does not exist for this wsdl version.", nil)
+}
+
+func invalidParameter(format string, args ...any) error {
+	return awserr.New(errCodeInvalidParameterValue, fmt.Sprintf(format, args...), nil)
+}
+
+// queueByURL returns the queue at url. s.mu must be held.
+func (s *Service) queueByURL(url *string) (*queue, error) {
+	if aws.StringValue(url) == "" {
+		return nil, awserr.New(errCodeMissingParameter, "The request must contain the parameter QueueUrl.", nil)
+	}
+	name := strings.TrimPrefix(*url, URLPrefix)
+	q := s.queues[name]
+	if q == nil || q.url() != *url {
+		return nil, queueNotFound()
+	}
+	s.expire(q)
+	return q, nil
+}
+
+// queueByARN returns the queue with the given ARN, or nil. s.mu must be held.
+func (s *Service) queueByARN(arn string) *queue {
+	q := s.queues[strings.TrimPrefix(arn, ARNPrefix)]
+	if q == nil || q.arn() != arn {
+		return nil
+	}
+	return q
+}
+
+// validateAttributes checks settable queue attributes and returns the normalized set.
+// Empty values are treated as unset. s.mu must be held.
+func (s *Service) validateAttributes(q *queue, attrs map[string]*string, creating bool) (map[string]string, *redrivePolicy, error) {
+	out := make(map[string]string)
+	redrive := q.redrive
+	for _, name := range sortedKeys(attrs) {
+		v := aws.StringValue(attrs[name])
+		if v == "" {
+			continue
+		}
+		if r, ok := attributeRanges[name]; ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < r[0] || n > r[1] {
+				return nil, nil, awserr.New(sqs.ErrCodeInvalidAttributeValue, fmt.Sprintf("Invalid value for the parameter %s.", name), nil)
+			}
+			out[name] = v
+			continue
+		}
+		switch name {
+		case sqs.QueueAttributeNameFifoQueue, sqs.QueueAttributeNameDeduplicationScope, sqs.QueueAttributeNameFifoThroughputLimit:
+			if !creating && name == sqs.QueueAttributeNameFifoQueue {
+				return nil, nil, awserr.New(sqs.ErrCodeInvalidAttributeName, "FifoQueue cannot be changed after the queue is created.", nil)
+			}
+			out[name] = v
+		case sqs.QueueAttributeNameContentBasedDeduplication, sqs.QueueAttributeNamePolicy, sqs.QueueAttributeNameKmsMasterKeyId,
+			sqs.QueueAttributeNameRedriveAllowPolicy, sqs.QueueAttributeNameSqsManagedSseEnabled:
+			out[name] = v
+		case sqs.QueueAttributeNameRedrivePolicy:
+			rp, err := s.parseRedrivePolicy(v)
+			if err != nil {
+				return nil, nil, err
+			}
+			redrive = rp
+			out[name] = v
+		default:
+			return nil, nil, awserr.New(sqs.ErrCodeInvalidAttributeName, fmt.Sprintf("Unknown Attribute %s.", name), nil)
+		}
+	}
+	return out, redrive, nil
+}
+
+// parseRedrivePolicy parses a RedrivePolicy attribute. The dead-letter queue must exist.
+func (s *Service) parseRedrivePolicy(v string) (*redrivePolicy, error) {
+	var raw struct {
+		DeadLetterTargetArn string          `json:"deadLetterTargetArn"`
+		MaxReceiveCount     json.RawMessage `json:"maxReceiveCount"`
+	}
+	if err := json.Unmarshal([]byte(v), &raw); err != nil {
+		return nil, awserr.New(sqs.ErrCodeInvalidAttributeValue, "Invalid value for the parameter RedrivePolicy. Reason: Redrive policy is not a valid JSON map.", nil)
+	}
+	n, err := strconv.Atoi(strings.Trim(string(raw.MaxReceiveCount), `"`))
+	if err != nil || n < 1 || n > 1000 {
+		return nil, awserr.New(sqs.ErrCodeInvalidAttributeValue, "Invalid value for the parameter RedrivePolicy. Reason: Invalid value for maxReceiveCount.", nil)
+	}
+	if s.queueByARN(raw.DeadLetterTargetArn) == nil {
+		return nil, awserr.New(sqs.ErrCodeInvalidAttributeValue, "Value "+raw.DeadLetterTargetArn+" for parameter RedrivePolicy is invalid. Reason: Dead letter target does not exist.", nil)
+	}
+	return &redrivePolicy{deadLetterTargetArn: raw.DeadLetterTargetArn, maxReceiveCount: n}, nil
+}
+
+// CreateQueue creates a queue. Creating an existing queue with identical
+// attributes returns its URL.
+func (s *Service) CreateQueue(input *sqs.CreateQueueInput) (*sqs.CreateQueueOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := aws.StringValue(input.QueueName)
+	base := strings.TrimSuffix(name, ".fifo")
+	if base == "" || len(name) > 80 || strings.IndexFunc(base, func(r rune) bool {
+		return !(r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'))
+	}) >= 0 {
+		return nil, invalidParameter("Can only include alphanumeric characters, hyphens, or underscores. 1 to 80 in length")
+	}
+	now := s.Now()
+	if at, ok := s.deleted[name]; ok && now.Sub(at) < recreateInterval {
+		return nil, awserr.New(sqs.ErrCodeQueueDeletedRecently, "You must wait 60 seconds after deleting a queue before you can create another with the same name.", nil)
+	}
+
+	q := &queue{
+		name:     name,
+		attrs:    make(map[string]string),
+		tags:     make(map[string]string),
+		created:  now,
+		modified: now,
+		dedup:    make(map[string]*message),
+		attempts: make(map[string]*receiveAttempt),
+	}
+	attrs, redrive, err := s.validateAttributes(q, input.Attributes, true)
+	if err != nil {
+		return nil, err
+	}
+	fifo := attrs[sqs.QueueAttributeNameFifoQueue] == "true"
+	if fifo != strings.HasSuffix(name, ".fifo") {
+		return nil, invalidParameter("The name of a FIFO queue can only include alphanumeric characters, hyphens, or underscores, must end with .fifo suffix.")
+	}
+	for k, v := range queueDefaults {
+		q.attrs[k] = v
+	}
+	if fifo {
+		for k, v := range fifoDefaults {
+			q.attrs[k] = v
+		}
+	} else {
+		for k := range attrs {
+			if _, ok := fifoDefaults[k]; ok && k != sqs.QueueAttributeNameFifoQueue {
+				return nil, awserr.New(sqs.ErrCodeInvalidAttributeName, fmt.Sprintf("Unknown Attribute %s.", k), nil)
+			}
+		}
+		delete(attrs, sqs.QueueAttributeNameFifoQueue)
+	}
+	for k, v := range attrs {
+		q.attrs[k] = v
+	}
+	if redrive != nil {
+		if dlq := s.queueByARN(redrive.deadLetterTargetArn); dlq.fifo() != fifo {
+			return nil, invalidParameter("Value for parameter RedrivePolicy is invalid. Reason: Dead-letter queue must be the same type of queue as the source.")
+		}
+	}
+	q.redrive = redrive
+	for k, v := range input.Tags {
+		q.tags[k] = aws.StringValue(v)
+	}
+
+	if existing := s.queues[name]; existing != nil {
+		for k, v := range q.attrs {
+			if existing.attrs[k] != v {
+				return nil, awserr.New(sqs.ErrCodeQueueNameExists, "A queue already exists with the same name and a different value for attribute "+k, nil)
+			}
+		}
+		return &sqs.CreateQueueOutput{QueueUrl: aws.String(existing.url())}, nil
+	}
+	s.queues[name] = q
+	return &sqs.CreateQueueOutput{QueueUrl: aws.String(q.url())}, nil
+}
+
+// GetQueueUrl returns the URL of the named queue.
+func (s *Service) GetQueueUrl(input *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q := s.queues[aws.StringValue(input.QueueName)]
+	if q == nil {
+		return nil, queueNotFound()
+	}
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(q.url())}, nil
+}
+
+// ListQueues lists queue URLs in name order.
+func (s *Service) ListQueues(input *sqs.ListQueuesInput) (*sqs.ListQueuesOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := &sqs.ListQueuesOutput{}
+	for _, name := range sortedKeys(s.queues) {
+		if strings.HasPrefix(name, aws.StringValue(input.QueueNamePrefix)) {
+			out.QueueUrls = append(out.QueueUrls, aws.String(s.queues[name].url()))
+		}
+	}
+	return out, nil
+}
+
+// DeleteQueue deletes a queue and its messages.
+func (s *Service) DeleteQueue(input *sqs.DeleteQueueInput) (*sqs.DeleteQueueOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range q.messages {
+		m.deleted = true
+	}
+	delete(s.queues, q.name)
+	s.deleted[q.name] = s.Now()
+	return &sqs.DeleteQueueOutput{}, nil
+}
+
+// PurgeQueue deletes every message in a queue. A queue can be purged once every 60 seconds.
+func (s *Service) PurgeQueue(input *sqs.PurgeQueueInput) (*sqs.PurgeQueueOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	if !q.purged.IsZero() && now.Sub(q.purged) < purgeInterval {
+		return nil, awserr.New(sqs.ErrCodePurgeQueueInProgress, "Only one PurgeQueue operation on "+q.name+" is allowed every 60 seconds.", nil)
+	}
+	for _, m := range q.messages {
+		m.deleted = true
+	}
+	q.messages = nil
+	q.purged = now
+	return &sqs.PurgeQueueOutput{}, nil
+}
+
+// GetQueueAttributes returns queue attributes, including approximate message counts.
+func (s *Service) GetQueueAttributes(input *sqs.GetQueueAttributesInput) (*sqs.GetQueueAttributesOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	now := s.Now()
+	all := make(map[string]string)
+	for k, v := range q.attrs {
+		if v != "" {
+			all[k] = v
+		}
+	}
+	var visible, inflight, delayed int
+	for _, m := range q.messages {
+		switch {
+		case m.inflight(now):
+			inflight++
+		case now.Before(m.visibleAt):
+			delayed++
+		default:
+			visible++
+		}
+	}
+	all[sqs.QueueAttributeNameApproximateNumberOfMessages] = strconv.Itoa(visible)
+	all[sqs.QueueAttributeNameApproximateNumberOfMessagesNotVisible] = strconv.Itoa(inflight)
+	all[sqs.QueueAttributeNameApproximateNumberOfMessagesDelayed] = strconv.Itoa(delayed)
+	all[sqs.QueueAttributeNameCreatedTimestamp] = strconv.FormatInt(q.created.Unix(), 10)
+	all[sqs.QueueAttributeNameLastModifiedTimestamp] = strconv.FormatInt(q.modified.Unix(), 10)
+	all[sqs.QueueAttributeNameQueueArn] = q.arn()
+
+	out := &sqs.GetQueueAttributesOutput{Attributes: make(map[string]*string)}
+	for _, n := range input.AttributeNames {
+		name := aws.StringValue(n)
+		if name == sqs.QueueAttributeNameAll {
+			for k, v := range all {
+				out.Attributes[k] = aws.String(v)
+			}
+			continue
+		}
+		if v, ok := all[name]; ok {
+			out.Attributes[name] = aws.String(v)
+		}
+	}
+	return out, nil
+}
+
+// SetQueueAttributes updates queue attributes.
+func (s *Service) SetQueueAttributes(input *sqs.SetQueueAttributesInput) (*sqs.SetQueueAttributesOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, err := s.queueByURL(input.QueueUrl)
+	if err != nil {
+		return nil, err
+	}
+	attrs, redrive, err := s.validateAttributes(q, input.Attributes, false)
+	if err != nil {
+		return nil, err
+	}
+	if redrive != nil && s.queueByARN(redrive.deadLetterTargetArn).fifo() != q.fifo() {
+		return nil, invalidParameter("Value for parameter RedrivePolicy is invalid. Reason: Dead-letter queue must be the same type of queue as the source.")
+	}
+	for k, v := range attrs {
+		q.attrs[k] = v
+	}
+	q.redrive = redrive
+	q.modified = s.Now()
+	return &sqs.SetQueueAttributesOutput{}, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -70,3 +70,16 @@ func NewSessionWithDefaultConfig() Session {
 
 	return sesh
 }
+
+// NewSessionWithConfig creates a session with the given configuration,
+// such as a custom endpoint and static credentials for local testing.
+func NewSessionWithConfig(config *aws.Config) Session {
+	s := session.Must(session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigDisable,
+		Config:            *config,
+	}))
+
+	sesh := Session{session: s}
+
+	return sesh
+}
@@ -0,0 +1,68 @@
+// Package godynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains objects for implementing an exponential backoff
+// algorithm for DynamoDB error handling.
+package godynamo
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// FailConfig stores parameters for the exponential backoff algorithm.
+// Attempt, Elapsed, MaxRetiresReached should always be initialized to 0, 0, false.
+type FailConfig struct {
+	Base              float64
+	Cap               float64
+	Attempt           float64
+	Elapsed           float64
+	MaxRetriesReached bool
+}
+
+// DefaultFailConfig returns the default configuration for the exponential backoff alogrithm
+// with a base wait time of 50 miliseconds, and max wait time of 1 minute (60000 ms).
+func DefaultFailConfig() *FailConfig {
+	return &FailConfig{Base: 50, Cap: 60000}
+}
+
+// ExponentialBackoff implements the exponential backoff algorithm for request retries
+// and sets fc.MaxRetriesReached when the max number of retries has been reached
+// (fc.Elapsed >= fc.Cap). Returns ctx's error if ctx is done before the wait ends.
+func (fc *FailConfig) ExponentialBackoff(ctx context.Context) error {
+	if fc.Elapsed >= fc.Cap {
+		fc.MaxRetriesReached = true // max retries reached
+		return nil
+	}
+
+	fc.Attempt += 1.0
+	// exponential backoff with full jitter
+	wait := 0.0
+	if n := int(fc.Base * math.Pow(2.0, fc.Attempt)); n > 0 {
+		wait = float64(rand.Intn(n))
+	}
+
+	if fc.Elapsed+wait > fc.Cap {
+		// wait until cap is reached
+		wait = fc.Cap - fc.Elapsed
+	}
+
+	timer := time.NewTimer(time.Duration(wait) * time.Millisecond)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+	}
+	fc.Elapsed += wait
+	return nil
+}
+
+// Reset resets Attempt and Elapsed fields.
+func (fc *FailConfig) Reset() {
+	fc.Attempt = 0
+	fc.Elapsed = 0
+	fc.MaxRetriesReached = false
+}
@@ -0,0 +1,734 @@
+// Package godynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file contains CRUD operations for working with DynamoDB.
+package godynamo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+const ErrRequestThrottled = "ERR_REQUEST_THROTTLED"
+
+//go:generate mockgen -destination=../mocks/godynamomock/dynamo.go -package=godynamomock . DynamoDbLogic
+type DynamoDbLogic interface {
+	ListTables(ctx context.Context) ([]string, int, error)
+	CreateTable(ctx context.Context, table *Table) error
+	DeleteTable(ctx context.Context, tableName string) error
+	CreateItem(ctx context.Context, item interface{}, tableName string) error
+	GetItem(ctx context.Context, q *Query, tableName string, item interface{}, expr Expression) (interface{}, error)
+	UpdateItem(ctx context.Context, q *Query, tableName string, expr Expression) error
+	DeleteItem(ctx context.Context, q *Query, tableName string) error
+	BatchWriteCreate(ctx context.Context, tableName string, fc *FailConfig, items []interface{}) error
+	BatchWriteDelete(ctx context.Context, tableName string, fc *FailConfig, queries []*Query) error
+	BatchGet(ctx context.Context, tableName string, fc *FailConfig, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error)
+	ScanItems(ctx context.Context, tableName string, model any, startKey any, expr Expression, perPage *int32) (*ScanResults, error)
+	QueryItems(ctx context.Context, tableName string, model any, startKey any, expr Expression, perPage *int32) (*QueryResults, error)
+	TxWrite(ctx context.Context, items []TransactionItem, requestToken string) ([]TransactionItem, error)
+}
+
+type DynamoDB struct {
+	svc        *dynamodb.Client
+	tables     map[string]*Table
+	failConfig *FailConfig
+}
+
+func NewDynamoDB(config goaws.AwsConfig, tables []*Table, failConfig *FailConfig) *DynamoDB {
+	tm := make(map[string]*Table)
+	for _, t := range tables {
+		tm[t.TableName] = t
+	}
+	return &DynamoDB{
+		svc:        NewDynamoDBClient(config.Config),
+		tables:     tm,
+		failConfig: failConfig,
+	}
+}
+
+func NewDynamoDBClient(config aws.Config) *dynamodb.Client {
+	return dynamodb.NewFromConfig(config)
+}
+
+// ListTables lists the tables in the database.
+func (d *DynamoDB) ListTables(ctx context.Context) ([]string, int, error) {
+	names := []string{}
+	t := 0
+	input := &dynamodb.ListTablesInput{}
+
+	for {
+		// Get the list of tables
+		result, err := d.svc.ListTables(ctx, input)
+		if err != nil {
+			return nil, 0, fmt.Errorf("d.svc.ListTables: %w", err)
+		}
+
+		names = append(names, result.TableNames...)
+		t += len(result.TableNames)
+
+		// assign the last read tablename as the start for our next call to the ListTables function
+		// the maximum number of table names returned in a call is 100 (default), which requires us to make
+		// multiple calls to the ListTables function to retrieve all table names
+		input.ExclusiveStartTableName = result.LastEvaluatedTableName
+
+		if result.LastEvaluatedTableName == nil {
+			break
+		}
+	}
+	return names, t, nil
+}
+
+// CreateTable creates a new table with the parameters passed to the Table struct.
+// NOTE: CreateTable creates Table in * On-Demand * billing mode.
+func (d *DynamoDB) CreateTable(ctx context.Context, table *Table) error {
+	input := &dynamodb.CreateTableInput{
+		AttributeDefinitions: []types.AttributeDefinition{
+			{ // Primary Key
+				AttributeName: aws.String(table.PrimaryKeyName),
+				AttributeType: types.ScalarAttributeType(table.PrimaryKeyType),
+			},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+		KeySchema: []types.KeySchemaElement{
+			{
+				AttributeName: aws.String(table.PrimaryKeyName),
+				KeyType:       types.KeyTypeHash,
+			},
+		},
+		TableName: aws.String(table.TableName),
+	}
+	if table.SortKeyName != "" {
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{
+			AttributeName: aws.String(table.SortKeyName),
+			AttributeType: types.ScalarAttributeType(table.SortKeyType),
+		})
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{
+			AttributeName: aws.String(table.SortKeyName),
+			KeyType:       types.KeyTypeRange,
+		})
+	}
+
+	if _, err := d.svc.CreateTable(ctx, input); err != nil {
+		return fmt.Errorf("d.svc.CreateTable: %w", handleErr(err))
+	}
+
+	d.tables[table.TableName] = table
+
+	return nil
+}
+
+// CreateItem puts a new item in the table.
+func (d *DynamoDB) CreateItem(ctx context.Context, item interface{}, tableName string) error {
+	// check if table exists
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return fmt.Errorf("attributevalue.MarshalMap: %w", err)
+	}
+
+	input := &dynamodb.PutItemInput{
+		Item:      av,
+		TableName: aws.String(tableName),
+	}
+
+	if _, err = d.svc.PutItem(ctx, input); err != nil {
+		return fmt.Errorf("d.svc.PutItem: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// GetItem reads an item from the database into item, which must be a non-nil pointer.
+// Returns item unchanged if the object is not found.
+func (d *DynamoDB) GetItem(ctx context.Context, q *Query, tableName string, item interface{}, expr Expression) (interface{}, error) {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	key, err := keyMaker(q, t)
+	if err != nil {
+		return nil, fmt.Errorf("keyMaker: %w", err)
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(t.TableName),
+		Key:       key,
+	}
+	if expr.Projection() != nil {
+		input.ExpressionAttributeNames = expr.Names()
+		input.ProjectionExpression = expr.Projection()
+	}
+
+	result, err := d.svc.GetItem(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.GetItem: %w", handleErr(err))
+	}
+
+	if err = attributevalue.UnmarshalMap(result.Item, item); err != nil {
+		return nil, fmt.Errorf("attributevalue.UnmarshalMap: %w", err)
+	}
+
+	return item, nil
+}
+
+// UpdateItem updates the specified item's attribute defined in the
+// Query object with the UpdateValue defined in the Query.
+func (d *DynamoDB) UpdateItem(ctx context.Context, q *Query, tableName string, expr Expression) error {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	key, err := keyMaker(q, t)
+	if err != nil {
+		return fmt.Errorf("keyMaker: %w", err)
+	}
+
+	input := &dynamodb.UpdateItemInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		TableName:                 aws.String(t.TableName),
+		Key:                       key,
+		ReturnValues:              types.ReturnValueUpdatedNew,
+		UpdateExpression:          expr.Update(),
+		ConditionExpression:       expr.Condition(),
+	}
+
+	if _, err := d.svc.UpdateItem(ctx, input); err != nil {
+		return fmt.Errorf("d.svc.UpdateItem: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// DeleteTable deletes the selected table.
+func (d *DynamoDB) DeleteTable(ctx context.Context, tableName string) error {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	input := &dynamodb.DeleteTableInput{
+		TableName: aws.String(t.TableName),
+	}
+	if _, err := d.svc.DeleteTable(ctx, input); err != nil {
+		return fmt.Errorf("d.svc.DeleteTable: %w", handleErr(err))
+	}
+
+	delete(d.tables, tableName)
+
+	return nil
+}
+
+// DeleteItem deletes the specified item defined in the Query
+func (d *DynamoDB) DeleteItem(ctx context.Context, q *Query, tableName string) error {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	key, err := keyMaker(q, t)
+	if err != nil {
+		return fmt.Errorf("keyMaker: %w", err)
+	}
+
+	input := &dynamodb.DeleteItemInput{
+		Key:       key,
+		TableName: aws.String(t.TableName),
+	}
+
+	if _, err := d.svc.DeleteItem(ctx, input); err != nil {
+		return fmt.Errorf("d.svc.DeleteItem: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// BatchWriteCreate writes a list of items to the database.
+func (d *DynamoDB) BatchWriteCreate(ctx context.Context, tableName string, fc *FailConfig, items []interface{}) error {
+	if len(items) > 25 {
+		return ErrCollectionSizeExceeded
+	}
+
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	wrs := []types.WriteRequest{}
+
+	// create PutRequests for each item
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+
+		// marshal each item
+		av, err := attributevalue.MarshalMap(item)
+		if err != nil {
+			return fmt.Errorf("attributevalue.MarshalMap: %w", err)
+		}
+		// create put request, reformat as write request, and add to list
+		wrs = append(wrs, types.WriteRequest{PutRequest: &types.PutRequest{Item: av}})
+	}
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{t.TableName: wrs},
+	}
+
+	if err := d.batchWrite(ctx, input, fc); err != nil {
+		return fmt.Errorf("d.batchWrite: %w", err)
+	}
+
+	return nil
+}
+
+// BatchWriteDelete deletes a list of items from the database.
+func (d *DynamoDB) BatchWriteDelete(ctx context.Context, tableName string, fc *FailConfig, queries []*Query) error {
+	if len(queries) > 25 {
+		return ErrCollectionSizeExceeded
+	}
+
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return NewTableNotFoundErr(tableName)
+	}
+
+	wrs := []types.WriteRequest{}
+
+	// create DeleteRequests for each query
+	for _, q := range queries {
+		if q == nil {
+			continue
+		}
+
+		key, err := keyMaker(q, t)
+		if err != nil {
+			return fmt.Errorf("keyMaker: %w", err)
+		}
+		// create delete request, reformat as write request, and add to list
+		wrs = append(wrs, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+	}
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{t.TableName: wrs},
+	}
+
+	if err := d.batchWrite(ctx, input, fc); err != nil {
+		return fmt.Errorf("d.batchWrite: %w", err)
+	}
+
+	return nil
+}
+
+// batchWrite executes the BatchWriteItem request and retries throttled requests and
+// unprocessed items with exponential backoff until all items are processed.
+func (d *DynamoDB) batchWrite(ctx context.Context, input *dynamodb.BatchWriteItemInput, fc *FailConfig) error {
+	fc = d.newFailConfig(fc)
+
+	for {
+		result, err := d.svc.BatchWriteItem(ctx, input)
+		if err != nil {
+			err = handleErr(err)
+			if !errors.Is(err, ErrRateLimitExceeded) {
+				return fmt.Errorf("d.svc.BatchWriteItem: %w", err)
+			}
+		} else {
+			if len(result.UnprocessedItems) == 0 {
+				return nil
+			}
+			input = &dynamodb.BatchWriteItemInput{
+				RequestItems: result.UnprocessedItems,
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fc.ExponentialBackoff(ctx); err != nil { // waits
+			return err
+		}
+		if fc.MaxRetriesReached {
+			return fmt.Errorf("d.svc.BatchWriteItem: %w", ErrRateLimitExceeded)
+		}
+	}
+}
+
+// BatchGet retrieves a list of items from the database
+// refObjs must be non-nil pointers of the same type,
+// 1 for each query/object returned.
+// Items are matched to their queries by key and returned in query order:
+// the item of queries[i] is unmarshalled into refObjs[i] and returned at
+// index i, or nil is returned at index i if the item does not exist.
+// Queries repeating a key read the item once, into each of their refObjs.
+//   - Returns err if len(queries) != len(refObjs).
+func (d *DynamoDB) BatchGet(ctx context.Context, tableName string, fc *FailConfig, queries []*Query, refObjs []interface{}, expr Expression) ([]interface{}, error) {
+	if len(queries) > 100 {
+		return nil, ErrCollectionSizeExceeded
+	}
+
+	if len(queries) != len(refObjs) {
+		return nil, ErrReferenceObjectsCount
+	}
+
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	fc = d.newFailConfig(fc)
+
+	items := make([]interface{}, len(queries))
+	keys := []map[string]types.AttributeValue{}
+	indexes := make(map[string][]int) // query indexes of each key
+
+	// create Get requests for each query; queries repeating a key read the same item
+	for i, q := range queries {
+		if q == nil {
+			continue
+		}
+		key, err := keyMaker(q, t)
+		if err != nil {
+			return nil, fmt.Errorf("keyMaker: %w", err)
+		}
+		k := t.keyString(key)
+		if _, ok := indexes[k]; !ok {
+			keys = append(keys, key)
+		}
+		indexes[k] = append(indexes[k], i)
+	}
+	if len(keys) == 0 {
+		return items, nil
+	}
+
+	// the key attributes are projected to match items to their queries
+	ka := types.KeysAndAttributes{Keys: keys}
+	proj, names, added := projectionWithKeys(t, expr)
+	if proj != nil {
+		ka.ExpressionAttributeNames = names
+		ka.ProjectionExpression = proj
+	}
+
+	input := &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{t.TableName: ka},
+	}
+
+	for {
+		result, err := d.svc.BatchGetItem(ctx, input)
+		if err != nil {
+			err = handleErr(err)
+			if !errors.Is(err, ErrRateLimitExceeded) {
+				return nil, fmt.Errorf("d.svc.BatchGetItem: %w", err)
+			}
+		} else {
+			for _, r := range result.Responses[t.TableName] {
+				for _, i := range indexes[t.keyString(r)] {
+					ref := refObjs[i]
+					if err := attributevalue.UnmarshalMap(withoutAttributes(r, added), ref); err != nil {
+						return nil, fmt.Errorf("attributevalue.UnmarshalMap: %w", err)
+					}
+					items[i] = ref
+				}
+			}
+
+			if len(result.UnprocessedKeys) == 0 {
+				return items, nil
+			}
+			input = &dynamodb.BatchGetItemInput{
+				RequestItems: result.UnprocessedKeys,
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := fc.ExponentialBackoff(ctx); err != nil { // waits
+			return nil, err
+		}
+		if fc.MaxRetriesReached {
+			return nil, fmt.Errorf("d.svc.BatchGetItem: %w", ErrRateLimitExceeded)
+		}
+	}
+}
+
+type ScanResults struct {
+	Results []any                           `json:"results"`
+	PerPage int32                           `json:"per_page,omitempty"`
+	LastKey map[string]types.AttributeValue `json:"last_key,omitempty"`
+}
+
+// ScanItems scans the given Table for items matching the given expression parameters.
+// A new value of model's type is allocated for each item returned.
+func (d *DynamoDB) ScanItems(ctx context.Context, tableName string, model any, startKey any, expr Expression, perPage *int32) (*ScanResults, error) {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	// Build the scan input parameters
+	input := &dynamodb.ScanInput{
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		TableName:                 aws.String(t.TableName),
+		Limit:                     perPage,
+	}
+
+	if startKey != nil {
+		av, err := marshalStartKey(startKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshalStartKey: %w", err)
+		}
+		input.ExclusiveStartKey = av
+	}
+
+	// Make the DynamoDB Scan API call
+	result, err := d.svc.Scan(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.Scan: %w", handleErr(err))
+	}
+
+	items, err := unmarshalItems(result.Items, model)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalItems: %w", err)
+	}
+
+	scanResult := &ScanResults{
+		Results: items,
+		LastKey: result.LastEvaluatedKey,
+	}
+
+	if perPage != nil {
+		scanResult.PerPage = *perPage
+	}
+	return scanResult, nil
+}
+
+type QueryResults struct {
+	Results []any                           `json:"results"`
+	PerPage int32                           `json:"per_page,omitempty"`
+	LastKey map[string]types.AttributeValue `json:"last_key,omitempty"`
+}
+
+// QueryItems queries the given Table for items matching the given expression parameters.
+// A new value of model's type is allocated for each item returned.
+func (d *DynamoDB) QueryItems(ctx context.Context, tableName string, model any, startKey any, expr Expression, perPage *int32) (*QueryResults, error) {
+	// get table
+	t := d.tables[tableName]
+	if t == nil {
+		return nil, NewTableNotFoundErr(tableName)
+	}
+
+	// Build the query input parameters
+	input := &dynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		TableName:                 aws.String(t.TableName),
+		Limit:                     perPage,
+	}
+
+	if startKey != nil {
+		av, err := marshalStartKey(startKey)
+		if err != nil {
+			return nil, fmt.Errorf("marshalStartKey: %w", err)
+		}
+		input.ExclusiveStartKey = av
+	}
+
+	// Make the DynamoDB Query API call
+	result, err := d.svc.Query(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("d.svc.Query: %w", handleErr(err))
+	}
+
+	items, err := unmarshalItems(result.Items, model)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalItems: %w", err)
+	}
+
+	queryResult := &QueryResults{
+		Results: items,
+		LastKey: result.LastEvaluatedKey,
+	}
+
+	if perPage != nil {
+		queryResult.PerPage = *perPage
+	}
+
+	return queryResult, nil
+}
+
+// newFailConfig returns a reset copy of fc, of the DynamoDB object's FailConfig
+// if fc is nil, or of DefaultFailConfig if neither was provided, so FailConfigs
+// shared by concurrent operations are never modified.
+func (d *DynamoDB) newFailConfig(fc *FailConfig) *FailConfig {
+	if fc == nil {
+		fc = d.failConfig
+	}
+	if fc == nil {
+		fc = DefaultFailConfig()
+	}
+	c := *fc
+	c.Reset()
+	return &c
+}
+
+// projectionWithKeys returns the projection expression and names of expr with
+// the table's key attributes added, or nil if expr has no projection.
+func projectionWithKeys(t *Table, expr Expression) (*string, map[string]string, []string) {
+	proj := expr.Projection()
+	if proj == nil {
+		return nil, nil, nil
+	}
+	names := make(map[string]string)
+	for k, v := range expr.Names() {
+		names[k] = v
+	}
+
+	// top level attributes of the projection
+	projected := make(map[string]bool)
+	for _, path := range strings.Split(*proj, ",") {
+		attr := strings.TrimSpace(path)
+		if i := strings.IndexAny(attr, ".["); i >= 0 {
+			attr = attr[:i]
+		}
+		if name, ok := names[attr]; ok {
+			attr = name
+		}
+		projected[attr] = true
+	}
+
+	projection, added := *proj, []string{}
+	add := func(placeholder, attr string) {
+		if attr == "" || projected[attr] {
+			return
+		}
+		names[placeholder] = attr
+		projection += ", " + placeholder
+		added = append(added, attr)
+	}
+	add("#partitionKey", t.PrimaryKeyName)
+	add("#sortKey", t.SortKeyName)
+	return &projection, names, added
+}
+
+// withoutAttributes returns a copy of av without the given attributes, or av
+// itself if there are none.
+func withoutAttributes(av map[string]types.AttributeValue, attrs []string) map[string]types.AttributeValue {
+	if len(attrs) == 0 {
+		return av
+	}
+	item := make(map[string]types.AttributeValue, len(av))
+	for k, v := range av {
+		item[k] = v
+	}
+	for _, attr := range attrs {
+		delete(item, attr)
+	}
+	return item
+}
+
+// marshalStartKey accepts either a LastKey value returned from a previous
+// Scan/Query call or any Go value that marshals to an AttributeValue map.
+func marshalStartKey(startKey any) (map[string]types.AttributeValue, error) {
+	if av, ok := startKey.(map[string]types.AttributeValue); ok {
+		return av, nil
+	}
+	av, err := attributevalue.MarshalMap(startKey)
+	if err != nil {
+		return nil, fmt.Errorf("attributevalue.MarshalMap: %w", err)
+	}
+	return av, nil
+}
+
+// unmarshalItems unmarshals each item into a new value of model's type.
+// Pointer models return pointers to new values; all other models return values.
+func unmarshalItems(avs []map[string]types.AttributeValue, model any) ([]any, error) {
+	items := make([]any, 0, len(avs))
+	if model == nil {
+		model = map[string]any{}
+	}
+
+	mt := reflect.TypeOf(model)
+	isPtr := mt.Kind() == reflect.Pointer
+	if isPtr {
+		mt = mt.Elem()
+	}
+
+	for _, av := range avs {
+		item := reflect.New(mt)
+		if err := attributevalue.UnmarshalMap(av, item.Interface()); err != nil {
+			return nil, fmt.Errorf("attributevalue.UnmarshalMap: %w", err)
+		}
+		if isPtr {
+			items = append(items, item.Interface())
+			continue
+		}
+		items = append(items, item.Elem().Interface())
+	}
+
+	return items, nil
+}
+
+func handleErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var throughputErr *types.ProvisionedThroughputExceededException
+	var requestLimitErr *types.RequestLimitExceeded
+	var notFoundErr *types.ResourceNotFoundException
+	var inUseErr *types.ResourceInUseException
+	var collectionSizeErr *types.ItemCollectionSizeLimitExceededException
+	var conditionErr *types.ConditionalCheckFailedException
+
+	switch {
+	case errors.As(err, &throughputErr), errors.As(err, &requestLimitErr):
+		return ErrRateLimitExceeded
+	case errors.As(err, &notFoundErr):
+		return ErrResourceNotFound
+	case errors.As(err, &inUseErr):
+		return ErrResourceInUse
+	case errors.As(err, &collectionSizeErr):
+		return ErrCollectionSizeExceeded
+	case errors.As(err, &conditionErr):
+		return NewConditionCheckFailedErr(conditionErr.ErrorMessage())
+	default:
+		return err
+	}
+}
+
+// marshalMap marshals an interface object into an AttributeValue map
+func marshalMap(input interface{}) (map[string]types.AttributeValue, error) {
+	marshal, err := attributevalue.MarshalMap(input)
+	if err != nil {
+		return nil, fmt.Errorf("attributevalue.MarshalMap: %w", err)
+	}
+	return marshal, nil
+}
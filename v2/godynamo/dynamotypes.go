@@ -0,0 +1,154 @@
+// Package godynamo contains controls and objects for DynamoDB CRUD operations.
+// Operations in this package are abstracted from all other application logic
+// and are designed to be used with any DynamoDB table and any object schema.
+// This file defines the Table and Query objects, and functions for creating them.
+// It also defines functions for creating DynamoDB AttributeValue objects and database keys in map format.
+package godynamo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const ErrConditionalCheck = "ERR_CONDITIONAL_CHECK"
+
+// Table represents a table and holds basic information about it.
+// This object is used to access the Dynamo Table requested for each CRUD op.
+type Table struct {
+	TableName      string
+	PrimaryKeyName string
+	PrimaryKeyType string
+	SortKeyName    string
+	SortKeyType    string
+}
+
+// Query holds the search values for both the Partition and Sort Keys.
+// Query also holds data for updating a specific item in the UpdateFieldName column.
+type Query struct {
+	PrimaryValue    interface{}
+	SortValue       interface{}
+	UpdateFieldName string
+	UpdateExprKey   string
+	UpdateValue     interface{}
+}
+
+// New creates a new query by setting the Partition Key and Sort Key values.
+func (q *Query) New(pv, sv interface{}) { q.PrimaryValue, q.SortValue = pv, sv }
+
+// UpdateCurrent sets the update fields for the current item.
+func (q *Query) UpdateCurrent(fieldName string, value interface{}) {
+	q.UpdateFieldName, q.UpdateValue = fieldName, value
+}
+
+// UpdateNew selects a new item for an update.
+func (q *Query) UpdateNew(pv, sv, fieldName string, value interface{}) {
+	q.PrimaryValue, q.SortValue, q.UpdateFieldName, q.UpdateValue = pv, sv, fieldName, value
+}
+
+// Reset clears all fields.
+func (q *Query) Reset() {
+	q.PrimaryValue, q.SortValue, q.UpdateValue, q.UpdateExprKey, q.UpdateFieldName = nil, nil, nil, "", ""
+}
+
+// CreateNewTableObj creates a new Table struct.
+// The Table's key's Go types must be declared as strings.
+// ex: t := CreateNewTableObj("my_table", "Year", "int", "MovieName", "string")
+func CreateNewTableObj(tableName, pKeyName, pType, sKeyName, sType string) *Table {
+	typeMap := map[string]string{
+		"[]byte":   "B",
+		"[][]byte": "BS",
+		"bool":     "BOOL",
+		"list":     "L",
+		"map":      "M",
+		"int":      "N",
+		"[]int":    "NS",
+		"null":     "NULL",
+		"string":   "S",
+		"[]string": "SS",
+	}
+
+	pt := typeMap[pType]
+	st := typeMap[sType]
+
+	return &Table{tableName, pKeyName, pt, sKeyName, st}
+}
+
+// CreateNewQueryObj creates a new Query struct.
+// pval, sval == Primary/Partition key, Sort Key
+func CreateNewQueryObj(pval, sval interface{}) *Query {
+	return &Query{PrimaryValue: pval, SortValue: sval}
+}
+
+// createAV converts a Go value to a DynamoDB AttributeValue.
+func createAV(val interface{}) (types.AttributeValue, error) {
+	av, err := attributevalue.Marshal(val)
+	if err != nil {
+		return nil, fmt.Errorf("attributevalue.Marshal: %w", err)
+	}
+	return av, nil
+}
+
+// keyMaker creates a map of Partition and Sort Keys.
+// Returns ErrMissingKey if q has no value for a key attribute.
+func keyMaker(q *Query, t *Table) (map[string]types.AttributeValue, error) {
+	keys := make(map[string]types.AttributeValue)
+	pk, err := keyAV(t.PrimaryKeyName, q.PrimaryValue)
+	if err != nil {
+		return nil, err
+	}
+	keys[t.PrimaryKeyName] = pk
+	if t.SortKeyName == "" {
+		return keys, nil
+	}
+	sk, err := keyAV(t.SortKeyName, q.SortValue)
+	if err != nil {
+		return nil, err
+	}
+	keys[t.SortKeyName] = sk
+	return keys, nil
+}
+
+// keyAV converts the value of the named key attribute to an AttributeValue.
+// Returns ErrMissingKey if val is nil or a nil pointer.
+func keyAV(name string, val interface{}) (types.AttributeValue, error) {
+	av, err := createAV(val)
+	if err != nil {
+		return nil, fmt.Errorf("key %s: %w", name, err)
+	}
+	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingKey, name)
+	}
+	return av, nil
+}
+
+// keyString encodes the Partition and Sort Key attributes of the item av as a
+// string, so items returned by DynamoDB can be matched to the keys requested.
+func (t *Table) keyString(av map[string]types.AttributeValue) string {
+	var b strings.Builder
+	for _, name := range []string{t.PrimaryKeyName, t.SortKeyName} {
+		if name == "" {
+			continue
+		}
+		switch v := av[name].(type) {
+		case *types.AttributeValueMemberS:
+			fmt.Fprintf(&b, "S%d:%s", len(v.Value), v.Value)
+		case *types.AttributeValueMemberN:
+			n := v.Value
+			if r, ok := new(big.Rat).SetString(n); ok {
+				n = r.RatString()
+			}
+			fmt.Fprintf(&b, "N%s", n)
+		case *types.AttributeValueMemberB:
+			fmt.Fprintf(&b, "B%s", base64.StdEncoding.EncodeToString(v.Value))
+		default:
+			b.WriteString("-")
+		}
+		b.WriteByte(';')
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package goses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2"
+	"github.com/aws/aws-sdk-go-v2/service/sesv2/types"
+	"github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+// CharSet repsents the charset type for email messages (UTF-8)
+const CharSet = "UTF-8"
+
+//go:generate mockgen -destination=../mocks/gosesmock/email.go -package=gosesmock . SesLogic
+type SesLogic interface {
+	ListVerifiedIdentities(ctx context.Context) ([]string, error)
+	SendEmail(ctx context.Context, req SendEmailRequest) (string, error)
+}
+
+type SES struct {
+	svc *sesv2.Client
+}
+
+func NewSES(config goaws.AwsConfig) *SES {
+	return &SES{
+		svc: NewSESClient(config.Config),
+	}
+}
+
+func NewSESClient(config aws.Config) *sesv2.Client {
+	return sesv2.NewFromConfig(config)
+}
+
+// ListVerifiedIdentities lists the SES verified email addresses for the account.
+func (s *SES) ListVerifiedIdentities(ctx context.Context) ([]string, error) {
+	verifiedIds := make([]string, 0)
+
+	paginator := sesv2.NewListEmailIdentitiesPaginator(s.svc, &sesv2.ListEmailIdentitiesInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("paginator.NextPage: %w", err)
+		}
+		for _, id := range page.EmailIdentities {
+			if id.IdentityType != types.IdentityTypeEmailAddress {
+				continue
+			}
+			if id.VerificationStatus == types.VerificationStatusSuccess {
+				verifiedIds = append(verifiedIds, aws.ToString(id.IdentityName))
+			}
+		}
+	}
+
+	return verifiedIds, nil
+}
+
+// SendEmail sends a new email message and returns the SES message ID.
+// The message is sent as HTML when HtmlBody is set, with TextBody as the
+// plain text alternative, or as plain text only when HtmlBody is empty.
+func (s *SES) SendEmail(ctx context.Context, req SendEmailRequest) (string, error) {
+	if len(req.To) == 0 && len(req.Cc) == 0 && len(req.Bcc) == 0 {
+		return "", ErrNoRecipients
+	}
+	if req.From == "" {
+		return "", ErrNoSender
+	}
+	if req.TextBody == "" && req.HtmlBody == "" {
+		return "", ErrNoBody
+	}
+
+	body := &types.Body{}
+	if req.TextBody != "" {
+		body.Text = &types.Content{
+			Charset: aws.String(CharSet),
+			Data:    aws.String(req.TextBody),
+		}
+	}
+	if req.HtmlBody != "" {
+		body.Html = &types.Content{
+			Charset: aws.String(CharSet),
+			Data:    aws.String(req.HtmlBody),
+		}
+	}
+
+	// Assemble the email.
+	input := &sesv2.SendEmailInput{
+		Destination: &types.Destination{
+			ToAddresses:  req.To,
+			CcAddresses:  req.Cc,
+			BccAddresses: req.Bcc,
+		},
+		Content: &types.EmailContent{
+			Simple: &types.Message{
+				Body: body,
+				Subject: &types.Content{
+					Charset: aws.String(CharSet),
+					Data:    aws.String(req.Subject),
+				},
+			},
+		},
+		ReplyToAddresses: req.ReplyTo,
+		FromEmailAddress: aws.String(req.From),
+		EmailTags:        newMessageTags(req.Tags),
+	}
+	if req.ConfigSetName != "" {
+		input.ConfigurationSetName = aws.String(req.ConfigSetName)
+	}
+
+	// Attempt to send the email.
+	result, err := s.svc.SendEmail(ctx, input)
+	if err != nil {
+		var rejected *types.MessageRejected
+		if errors.As(err, &rejected) {
+			return "", fmt.Errorf("s.svc.SendEmail: %w: %s", ErrMessageRejected, rejected.ErrorMessage())
+		}
+		return "", fmt.Errorf("s.svc.SendEmail: %w", err)
+	}
+
+	return aws.ToString(result.MessageId), nil
+}
+
+// newMessageTags converts a map of tags to a list of MessageTags sorted by name.
+func newMessageTags(tags map[string]string) []types.MessageTag {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgTags := make([]types.MessageTag, 0, len(tags))
+	for _, name := range names {
+		msgTags = append(msgTags, types.MessageTag{
+			Name:  aws.String(name),
+			Value: aws.String(tags[name]),
+		})
+	}
+	return msgTags
+}
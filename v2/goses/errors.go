@@ -0,0 +1,14 @@
+package goses
+
+import "errors"
+
+var (
+	// ErrNoRecipients is returned when a SendEmailRequest has no To, Cc or Bcc addresses.
+	ErrNoRecipients = errors.New("no recipients")
+	// ErrNoSender is returned when a SendEmailRequest has no From address.
+	ErrNoSender = errors.New("no sender")
+	// ErrNoBody is returned when a SendEmailRequest has neither a text nor an HTML body.
+	ErrNoBody = errors.New("no message body")
+	// ErrMessageRejected is returned when SES rejects the message.
+	ErrMessageRejected = errors.New("message rejected")
+)
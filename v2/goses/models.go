@@ -0,0 +1,17 @@
+package goses
+
+// SendEmailRequest contains the parameters for the SendEmail operation.
+// At least one of To, Cc or Bcc, the From address, and one of TextBody
+// or HtmlBody must be set.
+type SendEmailRequest struct {
+	To            []string          `json:"to"`
+	Cc            []string          `json:"cc,omitempty"`
+	Bcc           []string          `json:"bcc,omitempty"`
+	ReplyTo       []string          `json:"reply_to,omitempty"`
+	From          string            `json:"from"`
+	Subject       string            `json:"subject"`
+	TextBody      string            `json:"text_body,omitempty"`
+	HtmlBody      string            `json:"html_body,omitempty"`
+	ConfigSetName string            `json:"config_set_name,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
@@ -0,0 +1,10 @@
+package gosns
+
+import "errors"
+
+var (
+	// ErrInvalidProtocol is returned when an invalid protocol is passed to Subscribe.
+	ErrInvalidProtocol = errors.New("INVALID_SUBSCRIPTION_PROTOCOL")
+	// ErrNotFound is returned when the requested topic or subscription does not exist.
+	ErrNotFound = errors.New("resource not found")
+)
@@ -0,0 +1,10 @@
+package gosns
+
+// Subscription wraps the types.Subscription type.
+type Subscription struct {
+	SubscriptionArn string `json:"subscription_arn"`
+	TopicArn        string `json:"topic_arn"`
+	Endpoint        string `json:"endpoint"`
+	Protocol        string `json:"protocol"`
+	Owner           string `json:"owner"`
+}
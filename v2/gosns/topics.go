@@ -0,0 +1,197 @@
+package gosns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+// validProtocols contains the protocols accepted by Subscribe.
+var validProtocols = map[string]bool{
+	"http":        true,
+	"https":       true,
+	"email":       true,
+	"email-json":  true,
+	"sms":         true,
+	"sqs":         true,
+	"application": true,
+	"lambda":      true,
+	"firehose":    true,
+}
+
+//go:generate mockgen -destination=../mocks/gosnsmock/topics.go -package=gosnsmock . SnsLogic
+type SnsLogic interface {
+	ListTopics(ctx context.Context) ([]string, error)
+	CreateTopic(ctx context.Context, name string) (string, error)
+	DeleteTopic(ctx context.Context, topicArn string) error
+	GetTopicAttributes(ctx context.Context, topicArn string) (map[string]string, error)
+	SetTopicAttributes(ctx context.Context, topicArn, name, value string) error
+	Subscribe(ctx context.Context, endpoint, protocol, topicArn string) (string, error)
+	Unsubscribe(ctx context.Context, subscriptionArn string) error
+	ListSubscriptionsByTopic(ctx context.Context, topicArn string) ([]Subscription, error)
+	Publish(ctx context.Context, msgStr, topicArn string) (string, error)
+}
+
+type SNS struct {
+	svc *sns.Client
+}
+
+func NewSNS(config goaws.AwsConfig) *SNS {
+	return &SNS{
+		svc: NewSnsClient(config.Config),
+	}
+}
+
+func NewSnsClient(config aws.Config) *sns.Client {
+	return sns.NewFromConfig(config)
+}
+
+// ListTopics returns a list of all SNS topics' ARNs in the AWS account.
+func (s *SNS) ListTopics(ctx context.Context) ([]string, error) {
+	arns := []string{}
+
+	paginator := sns.NewListTopicsPaginator(s.svc, &sns.ListTopicsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("paginator.NextPage: %w", handleErr(err))
+		}
+		for _, t := range page.Topics {
+			arns = append(arns, aws.ToString(t.TopicArn))
+		}
+	}
+
+	return arns, nil
+}
+
+// CreateTopic creates a new SNS topic with the given name and returns the topic ARN.
+func (s *SNS) CreateTopic(ctx context.Context, name string) (string, error) {
+	result, err := s.svc.CreateTopic(ctx, &sns.CreateTopicInput{
+		Name: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s.svc.CreateTopic: %w", handleErr(err))
+	}
+
+	return aws.ToString(result.TopicArn), nil
+}
+
+// DeleteTopic deletes the topic and all of its subscriptions.
+func (s *SNS) DeleteTopic(ctx context.Context, topicArn string) error {
+	if _, err := s.svc.DeleteTopic(ctx, &sns.DeleteTopicInput{
+		TopicArn: aws.String(topicArn),
+	}); err != nil {
+		return fmt.Errorf("s.svc.DeleteTopic: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// GetTopicAttributes returns all of the properties of the given topic.
+func (s *SNS) GetTopicAttributes(ctx context.Context, topicArn string) (map[string]string, error) {
+	result, err := s.svc.GetTopicAttributes(ctx, &sns.GetTopicAttributesInput{
+		TopicArn: aws.String(topicArn),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.svc.GetTopicAttributes: %w", handleErr(err))
+	}
+
+	return result.Attributes, nil
+}
+
+// SetTopicAttributes sets the named attribute of the given topic to a new value.
+func (s *SNS) SetTopicAttributes(ctx context.Context, topicArn, name, value string) error {
+	if _, err := s.svc.SetTopicAttributes(ctx, &sns.SetTopicAttributesInput{
+		TopicArn:       aws.String(topicArn),
+		AttributeName:  aws.String(name),
+		AttributeValue: aws.String(value),
+	}); err != nil {
+		return fmt.Errorf("s.svc.SetTopicAttributes: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// Subscribe creates a new subscription for an endpoint and returns the subscription ARN.
+func (s *SNS) Subscribe(ctx context.Context, endpoint, protocol, topicArn string) (string, error) {
+	if !validProtocols[protocol] {
+		return "", ErrInvalidProtocol
+	}
+
+	result, err := s.svc.Subscribe(ctx, &sns.SubscribeInput{
+		Endpoint:              aws.String(endpoint),
+		Protocol:              aws.String(protocol),
+		ReturnSubscriptionArn: true, // Return the ARN, even if user has yet to confirm
+		TopicArn:              aws.String(topicArn),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s.svc.Subscribe: %w", handleErr(err))
+	}
+
+	return aws.ToString(result.SubscriptionArn), nil
+}
+
+// Unsubscribe deletes the subscription with the given ARN.
+func (s *SNS) Unsubscribe(ctx context.Context, subscriptionArn string) error {
+	if _, err := s.svc.Unsubscribe(ctx, &sns.UnsubscribeInput{
+		SubscriptionArn: aws.String(subscriptionArn),
+	}); err != nil {
+		return fmt.Errorf("s.svc.Unsubscribe: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// ListSubscriptionsByTopic returns all subscriptions to the given topic.
+func (s *SNS) ListSubscriptionsByTopic(ctx context.Context, topicArn string) ([]Subscription, error) {
+	subs := []Subscription{}
+
+	paginator := sns.NewListSubscriptionsByTopicPaginator(s.svc, &sns.ListSubscriptionsByTopicInput{
+		TopicArn: aws.String(topicArn),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("paginator.NextPage: %w", handleErr(err))
+		}
+		for _, sub := range page.Subscriptions {
+			subs = append(subs, Subscription{
+				SubscriptionArn: aws.ToString(sub.SubscriptionArn),
+				TopicArn:        aws.ToString(sub.TopicArn),
+				Endpoint:        aws.ToString(sub.Endpoint),
+				Protocol:        aws.ToString(sub.Protocol),
+				Owner:           aws.ToString(sub.Owner),
+			})
+		}
+	}
+
+	return subs, nil
+}
+
+// Publish publishes a new message to a Topic and returns the message ID
+// of the published message.
+func (s *SNS) Publish(ctx context.Context, msgStr, topicArn string) (string, error) {
+	result, err := s.svc.Publish(ctx, &sns.PublishInput{
+		Message:  aws.String(msgStr),
+		TopicArn: aws.String(topicArn),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s.svc.Publish: %w", handleErr(err))
+	}
+
+	return aws.ToString(result.MessageId), nil
+}
+
+// handleErr maps SNS API errors to the package's error values.
+func handleErr(err error) error {
+	var notFound *types.NotFoundException
+	if errors.As(err, &notFound) {
+		return ErrNotFound
+	}
+	return err
+}
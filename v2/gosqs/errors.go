@@ -0,0 +1,21 @@
+package gosqs
+
+import "errors"
+
+var (
+	// ErrInvalidParameter is returned when ReceiptHandles/VisibilityTimeout are expired or invalid.
+	ErrInvalidParameter = errors.New("InvalidParameterValue")
+	// ErrMissingParameter is returned when an empty ReceiptHandle value is passed to DeleteMessage,
+	// or when a message is sent to a FIFO queue without a MessageGroupId.
+	ErrMissingParameter = errors.New("MissingParameter")
+	// ErrNonExistentQueue is returned when the requested queue does not exist.
+	ErrNonExistentQueue = errors.New("AWS.SimpleQueueService.NonExistentQueue")
+	// ErrTooManyRequests is returned when a batch request is made with > 10 entries.
+	ErrTooManyRequests = errors.New("TOO_MANY_REQUESTS")
+	// ErrInvalidRequest is returned when the number of message IDs != the number of receipt handles in a batch request.
+	ErrInvalidRequest = errors.New("IDS_NOT_EQUAL_HANDLES")
+	// ErrEmptyRequest is returned when a batch request is made with no entries.
+	ErrEmptyRequest = errors.New("EMPTY_REQUEST")
+	// ErrInvalidQueueURL is returned when a batch request is passed with an empty QueueURL field.
+	ErrInvalidQueueURL = errors.New("INVALID_QUEUE_URL")
+)
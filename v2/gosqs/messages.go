@@ -0,0 +1,381 @@
+package gosqs
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go"
+	"github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+// SendMsgDefault contains the default options for the sqs.SendMessageInput object.
+var SendMsgDefault = SendMsgOptions{
+	DelaySeconds:            int32(0),
+	MessageAttributes:       nil,
+	MessageBody:             "",
+	MessageDeduplicationId:  "",
+	MessageGroupId:          "",
+	MessageSystemAttributes: nil,
+	QueueURL:                "",
+}
+
+// RecMsgDefault contains the default values for the sqs.ReceiveMessageInput object.
+var RecMsgDefault = RecMsgOptions{
+	AttributeNames:          []types.MessageSystemAttributeName{types.MessageSystemAttributeNameAll},
+	MaxNumberOfMessages:     int32(1),
+	MessageAttributeNames:   []string{"All"},
+	QueueURL:                "",
+	ReceiveRequestAttemptId: "",
+	VisibilityTimeout:       int32(30),
+	WaitTimeSeconds:         int32(0),
+}
+
+// CreateMsgAttributes creates a MessageAttributeValue map from a list of MsgAV objects.
+// Limited to StringValue types; BinaryValue not supported.
+func CreateMsgAttributes(attributes []MsgAV) map[string]types.MessageAttributeValue {
+	msgAttr := make(map[string]types.MessageAttributeValue)
+	for _, av := range attributes {
+		msgAttr[av.Key] = types.MessageAttributeValue{
+			DataType:    aws.String(av.DataType),
+			StringValue: aws.String(av.Value),
+		}
+	}
+	return msgAttr
+}
+
+// CreateMsgSystemAttributes creates a MessageSystemAttributeValue map from a list of MsgAV objects
+// Limited to StringValue types; BinaryValue not supported
+func CreateMsgSystemAttributes(attributes []MsgAV) map[string]types.MessageSystemAttributeValue {
+	msgSysAttr := make(map[string]types.MessageSystemAttributeValue)
+	for _, av := range attributes {
+		msgSysAttr[av.Key] = types.MessageSystemAttributeValue{
+			DataType:    aws.String(av.DataType),
+			StringValue: aws.String(av.Value),
+		}
+	}
+	return msgSysAttr
+}
+
+// CreateMsgAttribute constructs a MsgAV object from the given parameters
+func CreateMsgAttribute(key, dataType, value string) MsgAV {
+	return MsgAV{
+		Key:      key,
+		DataType: dataType,
+		Value:    value,
+	}
+}
+
+//go:generate mockgen -destination=../mocks/gosqsmock/messages.go -package=gosqsmock . SqsMessagesLogic
+type SqsMessagesLogic interface {
+	SendMessage(ctx context.Context, options SendMsgOptions) (SendMsgResponse, error)
+	ReceiveMessage(ctx context.Context, options RecMsgOptions) ([]Message, error)
+	DeleteMessage(ctx context.Context, url, handle string) error
+	DeleteMessageBatch(ctx context.Context, req DeleteMessageBatchRequest) (DeleteMessageBatchResponse, error)
+	ChangeMessageVisibilityBatch(ctx context.Context, req BatchUpdateVisibilityTimeoutRequest) (BatchUpdateVisibilityTimeoutResponse, error)
+}
+
+type SqsMessages struct {
+	svc *sqs.Client
+}
+
+func NewSqsMessages(config goaws.AwsConfig) *SqsMessages {
+	return &SqsMessages{
+		svc: NewSqsClient(config.Config),
+	}
+}
+
+// SendMessage sends a new message to a queue per the options argument.
+// Messages sent to FIFO Queues require a MessageGroupId, and an MD5 checksum
+// of the message body is used as the MessageDeduplicationId if not set.
+func (s *SqsMessages) SendMessage(ctx context.Context, options SendMsgOptions) (SendMsgResponse, error) {
+	// ensure values are valid
+	if options.DelaySeconds < 0 {
+		options.DelaySeconds = 0
+	}
+	if options.DelaySeconds > 900 {
+		options.DelaySeconds = 900
+	}
+	input := &sqs.SendMessageInput{
+		DelaySeconds:            options.DelaySeconds,
+		MessageAttributes:       options.MessageAttributes,
+		MessageBody:             aws.String(options.MessageBody),
+		MessageSystemAttributes: options.MessageSystemAttributes,
+		QueueUrl:                aws.String(options.QueueURL),
+	}
+	// set FIFO queue options
+	if checkFifo(options.QueueURL) {
+		if options.MessageGroupId == "" {
+			return SendMsgResponse{}, fmt.Errorf("MessageGroupId: %w", ErrMissingParameter)
+		}
+		input.MessageGroupId = aws.String(options.MessageGroupId)
+		if options.MessageDeduplicationId != "" {
+			input.MessageDeduplicationId = aws.String(options.MessageDeduplicationId)
+		} else {
+			input.MessageDeduplicationId = aws.String(GenerateDedupeID(options.MessageBody))
+		}
+	}
+
+	out, err := s.svc.SendMessage(ctx, input)
+	if err != nil {
+		return SendMsgResponse{}, fmt.Errorf("s.svc.SendMessage: %w", handleErr(err))
+	}
+
+	return wrapSendMsgOutput(out), nil
+}
+
+// ReceiveMessage receives a message from a queue per the options argument.
+// The ReceiveRequestAttemptId of FIFO queues is only sent if set, as a
+// retried receive must reuse the attempt ID of the failed receive.
+func (s *SqsMessages) ReceiveMessage(ctx context.Context, options RecMsgOptions) ([]Message, error) {
+	msgs := []Message{}
+
+	// ensure values are valid
+	if options.MaxNumberOfMessages < 1 {
+		options.MaxNumberOfMessages = 1
+	}
+	if options.MaxNumberOfMessages > 10 {
+		options.MaxNumberOfMessages = 10
+	}
+	if options.VisibilityTimeout < 0 {
+		options.VisibilityTimeout = 0
+	}
+	if options.VisibilityTimeout > 43200 {
+		options.VisibilityTimeout = 43200
+	}
+	if options.WaitTimeSeconds < 1 {
+		options.WaitTimeSeconds = 1
+	}
+	if options.WaitTimeSeconds > 20 {
+		options.WaitTimeSeconds = 20
+	}
+
+	input := &sqs.ReceiveMessageInput{
+		MessageSystemAttributeNames: options.AttributeNames,
+		MaxNumberOfMessages:         options.MaxNumberOfMessages,
+		MessageAttributeNames:       options.MessageAttributeNames,
+		QueueUrl:                    aws.String(options.QueueURL),
+		VisibilityTimeout:           options.VisibilityTimeout,
+		WaitTimeSeconds:             options.WaitTimeSeconds,
+	}
+	// set ReceiveRequestAttemptID for FIFO queues if set
+	if checkFifo(options.QueueURL) && options.ReceiveRequestAttemptId != "" {
+		input.ReceiveRequestAttemptId = aws.String(options.ReceiveRequestAttemptId)
+	}
+
+	msgResult, err := s.svc.ReceiveMessage(ctx, input)
+	if err != nil {
+		return msgs, fmt.Errorf("s.svc.ReceiveMessage: %w", handleErr(err))
+	}
+	for _, msg := range msgResult.Messages {
+		msgs = append(msgs, convertMessage(msg))
+	}
+	return msgs, nil
+}
+
+func wrapSendMsgOutput(out *sqs.SendMessageOutput) SendMsgResponse {
+	return SendMsgResponse{
+		MD5OfMessageAttributes:       aws.ToString(out.MD5OfMessageAttributes),
+		MD5OfMessageBody:             aws.ToString(out.MD5OfMessageBody),
+		MD5OfMessageSystemAttributes: aws.ToString(out.MD5OfMessageSystemAttributes),
+		MessageId:                    aws.ToString(out.MessageId),
+		SequenceNumber:               aws.ToString(out.SequenceNumber),
+	}
+}
+
+// convert types.Message type to Message struct
+func convertMessage(msg types.Message) Message {
+	attributes := make(map[string]string)
+	for k, v := range msg.Attributes {
+		attributes[k] = v
+	}
+	msgAttributes := make(map[string]MsgAV)
+	for k, v := range msg.MessageAttributes {
+		msgAttributes[k] = MsgAV{
+			Key:      k,
+			DataType: aws.ToString(v.DataType),
+			Value:    aws.ToString(v.StringValue),
+		}
+	}
+	return Message{
+		Attributes:             attributes,
+		Body:                   aws.ToString(msg.Body),
+		MD5OfBody:              aws.ToString(msg.MD5OfBody),
+		MD5OfMessageAttributes: aws.ToString(msg.MD5OfMessageAttributes),
+		MessageAttributes:      msgAttributes,
+		MessageId:              aws.ToString(msg.MessageId),
+		ReceiptHandle:          aws.ToString(msg.ReceiptHandle),
+	}
+}
+
+// determine if FIFO queue from url (".fifo")
+func checkFifo(url string) bool {
+	return strings.HasSuffix(url, ".fifo")
+}
+
+// GenerateDedupeID generates a MD5 hash from the given string.
+func GenerateDedupeID(msgBody string) string {
+	hash := md5.Sum([]byte(msgBody))
+	return hex.EncodeToString(hash[:])
+}
+
+// DeleteMessage deletes a message from the specified queue (by url) with the
+// given handle.
+func (s *SqsMessages) DeleteMessage(ctx context.Context, url, handle string) error {
+	if _, err := s.svc.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(url),
+		ReceiptHandle: aws.String(handle),
+	}); err != nil {
+		return fmt.Errorf("s.svc.DeleteMessage: %w", handleErr(err))
+	}
+	return nil
+}
+
+// DeleteMessageBatch deletes a batch of messages
+func (s *SqsMessages) DeleteMessageBatch(ctx context.Context, req DeleteMessageBatchRequest) (DeleteMessageBatchResponse, error) {
+	if len(req.ReceiptHandles) > 10 {
+		return DeleteMessageBatchResponse{}, ErrTooManyRequests
+	}
+	if len(req.MessageIDs) != len(req.ReceiptHandles) {
+		return DeleteMessageBatchResponse{}, ErrInvalidRequest
+	}
+	if req.QueueURL == "" {
+		return DeleteMessageBatchResponse{}, ErrInvalidQueueURL
+	}
+
+	handles := make(map[string]string)
+	entries := []types.DeleteMessageBatchRequestEntry{}
+	for i, handle := range req.ReceiptHandles {
+		msgID := req.MessageIDs[i]
+		entries = append(entries, types.DeleteMessageBatchRequestEntry{
+			Id:            aws.String(msgID),
+			ReceiptHandle: aws.String(handle),
+		})
+		handles[msgID] = handle
+	}
+
+	result, err := s.svc.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+		Entries:  entries,
+		QueueUrl: aws.String(req.QueueURL),
+	})
+	if err != nil {
+		return DeleteMessageBatchResponse{}, fmt.Errorf("s.svc.DeleteMessageBatch: %w", handleErr(err))
+	}
+
+	return wrapBatchDeleteOutput(result, handles), nil
+}
+
+// wrap sqs.DeleteMessageBatchOutput object
+func wrapBatchDeleteOutput(output *sqs.DeleteMessageBatchOutput, handles map[string]string) DeleteMessageBatchResponse {
+	wrap := DeleteMessageBatchResponse{
+		Successful: []BatchDeleteResultEntry{},
+		Failed:     []BatchDeleteErrEntry{},
+	}
+
+	for _, entry := range output.Successful {
+		wrap.Successful = append(wrap.Successful, BatchDeleteResultEntry{
+			MessageID: aws.ToString(entry.Id),
+		})
+	}
+	for _, entry := range output.Failed {
+		msgID := aws.ToString(entry.Id)
+		wrap.Failed = append(wrap.Failed, BatchDeleteErrEntry{
+			ErrorCode:     aws.ToString(entry.Code),
+			MessageID:     msgID,
+			ReceiptHandle: handles[msgID],
+			ErrorMessage:  aws.ToString(entry.Message),
+			SenderFault:   entry.SenderFault,
+		})
+	}
+	return wrap
+}
+
+// ChangeMessageVisibilityBatch updates the visibility timeout for a batch of messages
+// represented by the given MessageIds and ReceiptHandles. Assumes msgIDs[i] and handles[i] args
+// are in order and correspond to the same message.
+func (s *SqsMessages) ChangeMessageVisibilityBatch(ctx context.Context, req BatchUpdateVisibilityTimeoutRequest) (BatchUpdateVisibilityTimeoutResponse, error) {
+	if len(req.MessageIDs) != len(req.ReceiptHandles) {
+		return BatchUpdateVisibilityTimeoutResponse{}, ErrInvalidRequest
+	}
+	if len(req.MessageIDs) == 0 {
+		return BatchUpdateVisibilityTimeoutResponse{}, ErrEmptyRequest
+	}
+	if len(req.MessageIDs) > 10 {
+		return BatchUpdateVisibilityTimeoutResponse{}, ErrTooManyRequests
+	}
+	if req.QueueURL == "" {
+		return BatchUpdateVisibilityTimeoutResponse{}, ErrInvalidQueueURL
+	}
+
+	entries := []types.ChangeMessageVisibilityBatchRequestEntry{}
+	for i, id := range req.MessageIDs {
+		entries = append(entries, types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                aws.String(id),
+			ReceiptHandle:     aws.String(req.ReceiptHandles[i]),
+			VisibilityTimeout: req.TimeoutSeconds,
+		})
+	}
+
+	output, err := s.svc.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+		Entries:  entries,
+		QueueUrl: aws.String(req.QueueURL),
+	})
+	if err != nil {
+		return BatchUpdateVisibilityTimeoutResponse{}, fmt.Errorf("s.svc.ChangeMessageVisibilityBatch: %w", handleErr(err))
+	}
+
+	return wrapBatchUpdateVisibilityTimeoutOutput(output), nil
+}
+
+// wrap sqs.ChangeMessageVisibilityBatchOutput object
+func wrapBatchUpdateVisibilityTimeoutOutput(output *sqs.ChangeMessageVisibilityBatchOutput) BatchUpdateVisibilityTimeoutResponse {
+	wrap := BatchUpdateVisibilityTimeoutResponse{
+		Successful: []BatchUpdateVisibilityTimeoutEntry{},
+		Failed:     []BatchUpdateVisibilityTimeoutErrEntry{},
+	}
+
+	for _, entry := range output.Successful {
+		wrap.Successful = append(wrap.Successful, BatchUpdateVisibilityTimeoutEntry{
+			MessageID: aws.ToString(entry.Id),
+		})
+	}
+	for _, entry := range output.Failed {
+		wrap.Failed = append(wrap.Failed, BatchUpdateVisibilityTimeoutErrEntry{
+			ErrorCode:    aws.ToString(entry.Code),
+			MessageId:    aws.ToString(entry.Id),
+			ErrorMessage: aws.ToString(entry.Message),
+			SenderFault:  entry.SenderFault,
+		})
+	}
+	return wrap
+}
+
+// handleErr maps SQS API errors to the package's error values.
+func handleErr(err error) error {
+	var notExist *types.QueueDoesNotExist
+	var invalidHandle *types.ReceiptHandleIsInvalid
+	var apiErr smithy.APIError
+
+	switch {
+	case errors.As(err, &notExist):
+		return ErrNonExistentQueue
+	case errors.As(err, &invalidHandle):
+		return ErrInvalidParameter
+	case errors.As(err, &apiErr):
+		switch apiErr.ErrorCode() {
+		case "InvalidParameterValue":
+			return ErrInvalidParameter
+		case "MissingParameter":
+			return ErrMissingParameter
+		}
+		return err
+	default:
+		return err
+	}
+}
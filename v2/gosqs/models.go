@@ -0,0 +1,128 @@
+package gosqs
+
+import "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+// QueueOptions contains struct fields for setting custom options when creating a new SQS queue
+type QueueOptions struct {
+	DelaySeconds                  string `json:"delay_seconds"`
+	MaximumMessageSize            string `json:"maximum_message_size"`
+	MessageRetentionPeriod        string `json:"message_retention_period"`
+	Policy                        string `json:"policy"` // IAM Policy
+	ReceiveMessageWaitTimeSeconds string `json:"receive_message_wait_time_seconds"`
+	RedrivePolicy                 string `json:"redrive_policy"`
+	VisibilityTimeout             string `json:"visibility_timeout"`
+	KmsMasterKeyId                string `json:"kms_master_key_id"`
+	KmsDataKeyReusePeriodSeconds  string `json:"kms_data_key_reuse_period_seconds"`
+	FifoQueue                     string `json:"fifo_queue"`
+	ContentBasedDeduplication     string `json:"content_based_deduplication"`
+	DeduplicationScope            string `json:"deduplication_scope"`
+	FifoThroughputLimit           string `json:"fifo_throughput_limit"`
+}
+
+// SendMsgOptions is used to pass send message options to the sqs.SendMessageInput object.
+type SendMsgOptions struct {
+	DelaySeconds            int32                                        `json:"delay_seconds"`
+	MessageAttributes       map[string]types.MessageAttributeValue       `json:"message_attributes,omitempty"`
+	MessageBody             string                                       `json:"message_body"`
+	MessageDeduplicationId  string                                       `json:"message_deduplication_id"`
+	MessageGroupId          string                                       `json:"message_group_id"`
+	MessageSystemAttributes map[string]types.MessageSystemAttributeValue `json:"message_system_attributes,omitempty"`
+	QueueURL                string                                       `json:"queue_url"`
+}
+
+// SendMsgResponse wraps the sqs.SendMessageOutput object
+type SendMsgResponse struct {
+	MD5OfMessageAttributes       string `json:"md5_of_message_attributes"`
+	MD5OfMessageBody             string `json:"md5_of_message_body"`
+	MD5OfMessageSystemAttributes string `json:"md5_of_message_system_attributes"`
+	MessageId                    string `json:"message_id"`
+	SequenceNumber               string `json:"sequence_number"`
+}
+
+// RecMsgOptions is used to pass receive message options to the sqs.ReceiveMessageInput object.
+type RecMsgOptions struct {
+	AttributeNames          []types.MessageSystemAttributeName `json:"attribute_names"`
+	MaxNumberOfMessages     int32                              `json:"max_number_of_messages"`
+	MessageAttributeNames   []string                           `json:"message_attribute_names"`
+	QueueURL                string                             `json:"queue_url"`
+	ReceiveRequestAttemptId string                             `json:"receive_request_attempt_id"`
+	VisibilityTimeout       int32                              `json:"visibility_timeout"`
+	WaitTimeSeconds         int32                              `json:"wait_time_seconds"`
+}
+
+// Message wraps the types.Message type.
+type Message struct {
+	Attributes             map[string]string `json:"attributes"`
+	Body                   string            `json:"body"`
+	MD5OfBody              string            `json:"md5_of_body"`
+	MD5OfMessageAttributes string            `json:"md5_of_message_attributes"`
+	MessageAttributes      map[string]MsgAV  `json:"message_attributes"`
+	MessageId              string            `json:"message_id"`
+	ReceiptHandle          string            `json:"receipt_handle"`
+}
+
+// MsgAV represents a single types.MessageAttributeValue or types.MessageSystemAttributeValue object.
+// Limited to StringValue types; BinaryValue not supported.
+type MsgAV struct {
+	Key      string `json:"key"`
+	DataType string `json:"data_type"`
+	Value    string `json:"value"`
+}
+
+// DeleteMessageBatchRequest is used to create a new BatchDelete request.
+// len(MessageIDs) must equal len(ReceiptHandles). DeleteMessageBatch
+// assumes the order of MessageIDs corresponds to the order ReceiptHandles.
+type DeleteMessageBatchRequest struct {
+	QueueURL       string   `json:"queue_url"`
+	MessageIDs     []string `json:"message_ids"`
+	ReceiptHandles []string `json:"receipt_handles"`
+}
+
+// DeleteMessageBatchResponse wraps the sqs.DeleteMessageBatchOutput type.
+type DeleteMessageBatchResponse struct {
+	Failed     []BatchDeleteErrEntry    `json:"failed"`
+	Successful []BatchDeleteResultEntry `json:"successful"`
+}
+
+// BatchDeleteErrEntry wraps the types.BatchResultErrorEntry type.
+type BatchDeleteErrEntry struct {
+	ErrorCode     string `json:"error_code"`
+	MessageID     string `json:"message_id"`
+	ReceiptHandle string `json:"receipt_handle"` // not in types.BatchResultErrorEntry type - added for utility
+	ErrorMessage  string `json:"error_message"`
+	SenderFault   bool   `json:"sender_fault"`
+}
+
+// BatchDeleteResultEntry wraps the types.DeleteMessageBatchResultEntry type.
+type BatchDeleteResultEntry struct {
+	MessageID string `json:"message_id"`
+}
+
+// BatchUpdateVisibilityTimeoutRequest is used as input to the
+// ChangeMessageVisibilityBatch function.
+type BatchUpdateVisibilityTimeoutRequest struct {
+	QueueURL       string   `json:"queue_url"`
+	MessageIDs     []string `json:"message_ids"`
+	ReceiptHandles []string `json:"receipt_handles"`
+	TimeoutSeconds int32    `json:"timeout_seconds"`
+}
+
+// BatchUpdateVisibilityTimeoutResponse wraps the sqs.ChangeMessageVisibilityBatchOutput type.
+type BatchUpdateVisibilityTimeoutResponse struct {
+	Failed     []BatchUpdateVisibilityTimeoutErrEntry `json:"failed"`
+	Successful []BatchUpdateVisibilityTimeoutEntry    `json:"successful"`
+}
+
+// BatchUpdateVisibilityTimeoutErrEntry wraps the types.BatchResultErrorEntry object
+// returned from ChangeMessageVisibilityBatch operations.
+type BatchUpdateVisibilityTimeoutErrEntry struct {
+	ErrorCode    string `json:"code"`
+	MessageId    string `json:"id"`
+	ErrorMessage string `json:"message"`
+	SenderFault  bool   `json:"sender_fault"`
+}
+
+// BatchUpdateVisibilityTimeoutEntry wraps the types.ChangeMessageVisibilityBatchResultEntry object.
+type BatchUpdateVisibilityTimeoutEntry struct {
+	MessageID string `json:"message_id"`
+}
@@ -0,0 +1,123 @@
+package gosqs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/ggarcia209/go-aws/v2/goaws"
+)
+
+// QueueDefault contains the default attribute values for new SQS Queue objects
+var QueueDefault = QueueOptions{
+	DelaySeconds:                  "0",
+	MaximumMessageSize:            "262144",
+	MessageRetentionPeriod:        "345600",
+	Policy:                        "",
+	ReceiveMessageWaitTimeSeconds: "0",
+	RedrivePolicy:                 "",
+	VisibilityTimeout:             "30",
+	KmsMasterKeyId:                "",
+	KmsDataKeyReusePeriodSeconds:  "300",
+	FifoQueue:                     "false",
+	ContentBasedDeduplication:     "false",
+	// * high throughput preview *
+	// only available in us-east-1, us-east-2, us-west-2, eu-west-1
+	DeduplicationScope:  "queue",
+	FifoThroughputLimit: "perQueue",
+	// *  *
+}
+
+//go:generate mockgen -destination=../mocks/gosqsmock/queues.go -package=gosqsmock . SqsQueuesLogic
+type SqsQueuesLogic interface {
+	CreateQueue(ctx context.Context, name string, options QueueOptions, tags map[string]string) (string, error)
+	GetQueueURL(ctx context.Context, name string) (string, error)
+	DeleteQueue(ctx context.Context, url string) error
+	PurgeQueue(ctx context.Context, url string) error
+}
+
+type SqsQueues struct {
+	svc *sqs.Client
+}
+
+func NewSqsQueues(config goaws.AwsConfig) *SqsQueues {
+	return &SqsQueues{
+		svc: NewSqsClient(config.Config),
+	}
+}
+
+func NewSqsClient(config aws.Config) *sqs.Client {
+	return sqs.NewFromConfig(config)
+}
+
+// CreateQueue creates a new SQS queue per the given name, options, & tags arguments and returns the url of the queue and/or error
+func (s *SqsQueues) CreateQueue(ctx context.Context, name string, options QueueOptions, tags map[string]string) (string, error) {
+	input := &sqs.CreateQueueInput{
+		QueueName: aws.String(name),
+		Attributes: map[string]string{
+			string(types.QueueAttributeNameDelaySeconds):                  options.DelaySeconds,
+			string(types.QueueAttributeNameMaximumMessageSize):            options.MaximumMessageSize,
+			string(types.QueueAttributeNameMessageRetentionPeriod):        options.MessageRetentionPeriod,
+			string(types.QueueAttributeNamePolicy):                        options.Policy,
+			string(types.QueueAttributeNameReceiveMessageWaitTimeSeconds): options.ReceiveMessageWaitTimeSeconds,
+			string(types.QueueAttributeNameRedrivePolicy):                 options.RedrivePolicy,
+			string(types.QueueAttributeNameVisibilityTimeout):             options.VisibilityTimeout,
+			string(types.QueueAttributeNameKmsMasterKeyId):                options.KmsMasterKeyId,
+			string(types.QueueAttributeNameKmsDataKeyReusePeriodSeconds):  options.KmsDataKeyReusePeriodSeconds,
+		},
+	}
+	// set FIFO Queue options
+	if options.FifoQueue == "true" {
+		input.Attributes[string(types.QueueAttributeNameFifoQueue)] = "true"
+		input.Attributes[string(types.QueueAttributeNameContentBasedDeduplication)] = options.ContentBasedDeduplication
+		input.Attributes[string(types.QueueAttributeNameDeduplicationScope)] = options.DeduplicationScope
+		input.Attributes[string(types.QueueAttributeNameFifoThroughputLimit)] = options.FifoThroughputLimit
+	}
+	// set tags
+	if len(tags) > 0 {
+		input.Tags = tags
+	}
+
+	result, err := s.svc.CreateQueue(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("s.svc.CreateQueue: %w", handleErr(err))
+	}
+
+	return aws.ToString(result.QueueUrl), nil
+}
+
+// GetQueueURL retrives the URL for the given queue name
+func (s *SqsQueues) GetQueueURL(ctx context.Context, name string) (string, error) {
+	result, err := s.svc.GetQueueUrl(ctx, &sqs.GetQueueUrlInput{
+		QueueName: aws.String(name),
+	})
+	if err != nil {
+		return "", fmt.Errorf("s.svc.GetQueueUrl: %w", handleErr(err))
+	}
+
+	return aws.ToString(result.QueueUrl), nil
+}
+
+// DeleteQueue deletes the queue at the given URL
+func (s *SqsQueues) DeleteQueue(ctx context.Context, url string) error {
+	if _, err := s.svc.DeleteQueue(ctx, &sqs.DeleteQueueInput{
+		QueueUrl: aws.String(url),
+	}); err != nil {
+		return fmt.Errorf("s.svc.DeleteQueue: %w", handleErr(err))
+	}
+
+	return nil
+}
+
+// PurgeQueue purges the specified queue.
+func (s *SqsQueues) PurgeQueue(ctx context.Context, url string) error {
+	if _, err := s.svc.PurgeQueue(ctx, &sqs.PurgeQueueInput{
+		QueueUrl: aws.String(url),
+	}); err != nil {
+		return fmt.Errorf("s.svc.PurgeQueue: %w", handleErr(err))
+	}
+
+	return nil
+}
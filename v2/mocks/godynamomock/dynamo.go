@@ -0,0 +1,231 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ggarcia209/go-aws/v2/godynamo (interfaces: DynamoDbLogic)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/godynamomock/dynamo.go -package=godynamomock . DynamoDbLogic
+//
+
+// Package godynamomock is a generated GoMock package.
+package godynamomock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	godynamo "github.com/ggarcia209/go-aws/v2/godynamo"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDynamoDbLogic is a mock of DynamoDbLogic interface.
+type MockDynamoDbLogic struct {
+	ctrl     *gomock.Controller
+	recorder *MockDynamoDbLogicMockRecorder
+	isgomock struct{}
+}
+
+// MockDynamoDbLogicMockRecorder is the mock recorder for MockDynamoDbLogic.
+type MockDynamoDbLogicMockRecorder struct {
+	mock *MockDynamoDbLogic
+}
+
+// NewMockDynamoDbLogic creates a new mock instance.
+func NewMockDynamoDbLogic(ctrl *gomock.Controller) *MockDynamoDbLogic {
+	mock := &MockDynamoDbLogic{ctrl: ctrl}
+	mock.recorder = &MockDynamoDbLogicMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDynamoDbLogic) EXPECT() *MockDynamoDbLogicMockRecorder {
+	return m.recorder
+}
+
+// BatchGet mocks base method.
+func (m *MockDynamoDbLogic) BatchGet(ctx context.Context, tableName string, fc *godynamo.FailConfig, queries []*godynamo.Query, refObjs []any, expr godynamo.Expression) ([]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchGet", ctx, tableName, fc, queries, refObjs, expr)
+	ret0, _ := ret[0].([]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchGet indicates an expected call of BatchGet.
+func (mr *MockDynamoDbLogicMockRecorder) BatchGet(ctx, tableName, fc, queries, refObjs, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchGet", reflect.TypeOf((*MockDynamoDbLogic)(nil).BatchGet), ctx, tableName, fc, queries, refObjs, expr)
+}
+
+// BatchWriteCreate mocks base method.
+func (m *MockDynamoDbLogic) BatchWriteCreate(ctx context.Context, tableName string, fc *godynamo.FailConfig, items []any) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchWriteCreate", ctx, tableName, fc, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchWriteCreate indicates an expected call of BatchWriteCreate.
+func (mr *MockDynamoDbLogicMockRecorder) BatchWriteCreate(ctx, tableName, fc, items any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchWriteCreate", reflect.TypeOf((*MockDynamoDbLogic)(nil).BatchWriteCreate), ctx, tableName, fc, items)
+}
+
+// BatchWriteDelete mocks base method.
+func (m *MockDynamoDbLogic) BatchWriteDelete(ctx context.Context, tableName string, fc *godynamo.FailConfig, queries []*godynamo.Query) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchWriteDelete", ctx, tableName, fc, queries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BatchWriteDelete indicates an expected call of BatchWriteDelete.
+func (mr *MockDynamoDbLogicMockRecorder) BatchWriteDelete(ctx, tableName, fc, queries any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchWriteDelete", reflect.TypeOf((*MockDynamoDbLogic)(nil).BatchWriteDelete), ctx, tableName, fc, queries)
+}
+
+// CreateItem mocks base method.
+func (m *MockDynamoDbLogic) CreateItem(ctx context.Context, item any, tableName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateItem", ctx, item, tableName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateItem indicates an expected call of CreateItem.
+func (mr *MockDynamoDbLogicMockRecorder) CreateItem(ctx, item, tableName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateItem", reflect.TypeOf((*MockDynamoDbLogic)(nil).CreateItem), ctx, item, tableName)
+}
+
+// CreateTable mocks base method.
+func (m *MockDynamoDbLogic) CreateTable(ctx context.Context, table *godynamo.Table) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTable", ctx, table)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTable indicates an expected call of CreateTable.
+func (mr *MockDynamoDbLogicMockRecorder) CreateTable(ctx, table any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTable", reflect.TypeOf((*MockDynamoDbLogic)(nil).CreateTable), ctx, table)
+}
+
+// DeleteItem mocks base method.
+func (m *MockDynamoDbLogic) DeleteItem(ctx context.Context, q *godynamo.Query, tableName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteItem", ctx, q, tableName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteItem indicates an expected call of DeleteItem.
+func (mr *MockDynamoDbLogicMockRecorder) DeleteItem(ctx, q, tableName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteItem", reflect.TypeOf((*MockDynamoDbLogic)(nil).DeleteItem), ctx, q, tableName)
+}
+
+// DeleteTable mocks base method.
+func (m *MockDynamoDbLogic) DeleteTable(ctx context.Context, tableName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTable", ctx, tableName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTable indicates an expected call of DeleteTable.
+func (mr *MockDynamoDbLogicMockRecorder) DeleteTable(ctx, tableName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTable", reflect.TypeOf((*MockDynamoDbLogic)(nil).DeleteTable), ctx, tableName)
+}
+
+// GetItem mocks base method.
+func (m *MockDynamoDbLogic) GetItem(ctx context.Context, q *godynamo.Query, tableName string, item any, expr godynamo.Expression) (any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetItem", ctx, q, tableName, item, expr)
+	ret0, _ := ret[0].(any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetItem indicates an expected call of GetItem.
+func (mr *MockDynamoDbLogicMockRecorder) GetItem(ctx, q, tableName, item, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetItem", reflect.TypeOf((*MockDynamoDbLogic)(nil).GetItem), ctx, q, tableName, item, expr)
+}
+
+// ListTables mocks base method.
+func (m *MockDynamoDbLogic) ListTables(ctx context.Context) ([]string, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTables", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListTables indicates an expected call of ListTables.
+func (mr *MockDynamoDbLogicMockRecorder) ListTables(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTables", reflect.TypeOf((*MockDynamoDbLogic)(nil).ListTables), ctx)
+}
+
+// QueryItems mocks base method.
+func (m *MockDynamoDbLogic) QueryItems(ctx context.Context, tableName string, model, startKey any, expr godynamo.Expression, perPage *int32) (*godynamo.QueryResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryItems", ctx, tableName, model, startKey, expr, perPage)
+	ret0, _ := ret[0].(*godynamo.QueryResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryItems indicates an expected call of QueryItems.
+func (mr *MockDynamoDbLogicMockRecorder) QueryItems(ctx, tableName, model, startKey, expr, perPage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryItems", reflect.TypeOf((*MockDynamoDbLogic)(nil).QueryItems), ctx, tableName, model, startKey, expr, perPage)
+}
+
+// ScanItems mocks base method.
+func (m *MockDynamoDbLogic) ScanItems(ctx context.Context, tableName string, model, startKey any, expr godynamo.Expression, perPage *int32) (*godynamo.ScanResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanItems", ctx, tableName, model, startKey, expr, perPage)
+	ret0, _ := ret[0].(*godynamo.ScanResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScanItems indicates an expected call of ScanItems.
+func (mr *MockDynamoDbLogicMockRecorder) ScanItems(ctx, tableName, model, startKey, expr, perPage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanItems", reflect.TypeOf((*MockDynamoDbLogic)(nil).ScanItems), ctx, tableName, model, startKey, expr, perPage)
+}
+
+// TxWrite mocks base method.
+func (m *MockDynamoDbLogic) TxWrite(ctx context.Context, items []godynamo.TransactionItem, requestToken string) ([]godynamo.TransactionItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxWrite", ctx, items, requestToken)
+	ret0, _ := ret[0].([]godynamo.TransactionItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxWrite indicates an expected call of TxWrite.
+func (mr *MockDynamoDbLogicMockRecorder) TxWrite(ctx, items, requestToken any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxWrite", reflect.TypeOf((*MockDynamoDbLogic)(nil).TxWrite), ctx, items, requestToken)
+}
+
+// UpdateItem mocks base method.
+func (m *MockDynamoDbLogic) UpdateItem(ctx context.Context, q *godynamo.Query, tableName string, expr godynamo.Expression) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateItem", ctx, q, tableName, expr)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateItem indicates an expected call of UpdateItem.
+func (mr *MockDynamoDbLogicMockRecorder) UpdateItem(ctx, q, tableName, expr any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateItem", reflect.TypeOf((*MockDynamoDbLogic)(nil).UpdateItem), ctx, q, tableName, expr)
+}
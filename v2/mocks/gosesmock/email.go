@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ggarcia209/go-aws/v2/goses (interfaces: SesLogic)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/gosesmock/email.go -package=gosesmock . SesLogic
+//
+
+// Package gosesmock is a generated GoMock package.
+package gosesmock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	goses "github.com/ggarcia209/go-aws/v2/goses"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSesLogic is a mock of SesLogic interface.
+type MockSesLogic struct {
+	ctrl     *gomock.Controller
+	recorder *MockSesLogicMockRecorder
+	isgomock struct{}
+}
+
+// MockSesLogicMockRecorder is the mock recorder for MockSesLogic.
+type MockSesLogicMockRecorder struct {
+	mock *MockSesLogic
+}
+
+// NewMockSesLogic creates a new mock instance.
+func NewMockSesLogic(ctrl *gomock.Controller) *MockSesLogic {
+	mock := &MockSesLogic{ctrl: ctrl}
+	mock.recorder = &MockSesLogicMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSesLogic) EXPECT() *MockSesLogicMockRecorder {
+	return m.recorder
+}
+
+// ListVerifiedIdentities mocks base method.
+func (m *MockSesLogic) ListVerifiedIdentities(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVerifiedIdentities", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVerifiedIdentities indicates an expected call of ListVerifiedIdentities.
+func (mr *MockSesLogicMockRecorder) ListVerifiedIdentities(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVerifiedIdentities", reflect.TypeOf((*MockSesLogic)(nil).ListVerifiedIdentities), ctx)
+}
+
+// SendEmail mocks base method.
+func (m *MockSesLogic) SendEmail(ctx context.Context, req goses.SendEmailRequest) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmail", ctx, req)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendEmail indicates an expected call of SendEmail.
+func (mr *MockSesLogicMockRecorder) SendEmail(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockSesLogic)(nil).SendEmail), ctx, req)
+}
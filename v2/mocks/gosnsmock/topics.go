@@ -0,0 +1,174 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ggarcia209/go-aws/v2/gosns (interfaces: SnsLogic)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/gosnsmock/topics.go -package=gosnsmock . SnsLogic
+//
+
+// Package gosnsmock is a generated GoMock package.
+package gosnsmock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gosns "github.com/ggarcia209/go-aws/v2/gosns"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSnsLogic is a mock of SnsLogic interface.
+type MockSnsLogic struct {
+	ctrl     *gomock.Controller
+	recorder *MockSnsLogicMockRecorder
+	isgomock struct{}
+}
+
+// MockSnsLogicMockRecorder is the mock recorder for MockSnsLogic.
+type MockSnsLogicMockRecorder struct {
+	mock *MockSnsLogic
+}
+
+// NewMockSnsLogic creates a new mock instance.
+func NewMockSnsLogic(ctrl *gomock.Controller) *MockSnsLogic {
+	mock := &MockSnsLogic{ctrl: ctrl}
+	mock.recorder = &MockSnsLogicMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSnsLogic) EXPECT() *MockSnsLogicMockRecorder {
+	return m.recorder
+}
+
+// CreateTopic mocks base method.
+func (m *MockSnsLogic) CreateTopic(ctx context.Context, name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTopic", ctx, name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTopic indicates an expected call of CreateTopic.
+func (mr *MockSnsLogicMockRecorder) CreateTopic(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTopic", reflect.TypeOf((*MockSnsLogic)(nil).CreateTopic), ctx, name)
+}
+
+// DeleteTopic mocks base method.
+func (m *MockSnsLogic) DeleteTopic(ctx context.Context, topicArn string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTopic", ctx, topicArn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTopic indicates an expected call of DeleteTopic.
+func (mr *MockSnsLogicMockRecorder) DeleteTopic(ctx, topicArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTopic", reflect.TypeOf((*MockSnsLogic)(nil).DeleteTopic), ctx, topicArn)
+}
+
+// GetTopicAttributes mocks base method.
+func (m *MockSnsLogic) GetTopicAttributes(ctx context.Context, topicArn string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopicAttributes", ctx, topicArn)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTopicAttributes indicates an expected call of GetTopicAttributes.
+func (mr *MockSnsLogicMockRecorder) GetTopicAttributes(ctx, topicArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopicAttributes", reflect.TypeOf((*MockSnsLogic)(nil).GetTopicAttributes), ctx, topicArn)
+}
+
+// ListSubscriptionsByTopic mocks base method.
+func (m *MockSnsLogic) ListSubscriptionsByTopic(ctx context.Context, topicArn string) ([]gosns.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptionsByTopic", ctx, topicArn)
+	ret0, _ := ret[0].([]gosns.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptionsByTopic indicates an expected call of ListSubscriptionsByTopic.
+func (mr *MockSnsLogicMockRecorder) ListSubscriptionsByTopic(ctx, topicArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptionsByTopic", reflect.TypeOf((*MockSnsLogic)(nil).ListSubscriptionsByTopic), ctx, topicArn)
+}
+
+// ListTopics mocks base method.
+func (m *MockSnsLogic) ListTopics(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTopics", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTopics indicates an expected call of ListTopics.
+func (mr *MockSnsLogicMockRecorder) ListTopics(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTopics", reflect.TypeOf((*MockSnsLogic)(nil).ListTopics), ctx)
+}
+
+// Publish mocks base method.
+func (m *MockSnsLogic) Publish(ctx context.Context, msgStr, topicArn string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", ctx, msgStr, topicArn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockSnsLogicMockRecorder) Publish(ctx, msgStr, topicArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockSnsLogic)(nil).Publish), ctx, msgStr, topicArn)
+}
+
+// SetTopicAttributes mocks base method.
+func (m *MockSnsLogic) SetTopicAttributes(ctx context.Context, topicArn, name, value string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTopicAttributes", ctx, topicArn, name, value)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTopicAttributes indicates an expected call of SetTopicAttributes.
+func (mr *MockSnsLogicMockRecorder) SetTopicAttributes(ctx, topicArn, name, value any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTopicAttributes", reflect.TypeOf((*MockSnsLogic)(nil).SetTopicAttributes), ctx, topicArn, name, value)
+}
+
+// Subscribe mocks base method.
+func (m *MockSnsLogic) Subscribe(ctx context.Context, endpoint, protocol, topicArn string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, endpoint, protocol, topicArn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockSnsLogicMockRecorder) Subscribe(ctx, endpoint, protocol, topicArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockSnsLogic)(nil).Subscribe), ctx, endpoint, protocol, topicArn)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockSnsLogic) Unsubscribe(ctx context.Context, subscriptionArn string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, subscriptionArn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockSnsLogicMockRecorder) Unsubscribe(ctx, subscriptionArn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockSnsLogic)(nil).Unsubscribe), ctx, subscriptionArn)
+}
@@ -0,0 +1,116 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ggarcia209/go-aws/v2/gosqs (interfaces: SqsMessagesLogic)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/gosqsmock/messages.go -package=gosqsmock . SqsMessagesLogic
+//
+
+// Package gosqsmock is a generated GoMock package.
+package gosqsmock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gosqs "github.com/ggarcia209/go-aws/v2/gosqs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSqsMessagesLogic is a mock of SqsMessagesLogic interface.
+type MockSqsMessagesLogic struct {
+	ctrl     *gomock.Controller
+	recorder *MockSqsMessagesLogicMockRecorder
+	isgomock struct{}
+}
+
+// MockSqsMessagesLogicMockRecorder is the mock recorder for MockSqsMessagesLogic.
+type MockSqsMessagesLogicMockRecorder struct {
+	mock *MockSqsMessagesLogic
+}
+
+// NewMockSqsMessagesLogic creates a new mock instance.
+func NewMockSqsMessagesLogic(ctrl *gomock.Controller) *MockSqsMessagesLogic {
+	mock := &MockSqsMessagesLogic{ctrl: ctrl}
+	mock.recorder = &MockSqsMessagesLogicMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSqsMessagesLogic) EXPECT() *MockSqsMessagesLogicMockRecorder {
+	return m.recorder
+}
+
+// ChangeMessageVisibilityBatch mocks base method.
+func (m *MockSqsMessagesLogic) ChangeMessageVisibilityBatch(ctx context.Context, req gosqs.BatchUpdateVisibilityTimeoutRequest) (gosqs.BatchUpdateVisibilityTimeoutResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeMessageVisibilityBatch", ctx, req)
+	ret0, _ := ret[0].(gosqs.BatchUpdateVisibilityTimeoutResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeMessageVisibilityBatch indicates an expected call of ChangeMessageVisibilityBatch.
+func (mr *MockSqsMessagesLogicMockRecorder) ChangeMessageVisibilityBatch(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeMessageVisibilityBatch", reflect.TypeOf((*MockSqsMessagesLogic)(nil).ChangeMessageVisibilityBatch), ctx, req)
+}
+
+// DeleteMessage mocks base method.
+func (m *MockSqsMessagesLogic) DeleteMessage(ctx context.Context, url, handle string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessage", ctx, url, handle)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMessage indicates an expected call of DeleteMessage.
+func (mr *MockSqsMessagesLogicMockRecorder) DeleteMessage(ctx, url, handle any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessage", reflect.TypeOf((*MockSqsMessagesLogic)(nil).DeleteMessage), ctx, url, handle)
+}
+
+// DeleteMessageBatch mocks base method.
+func (m *MockSqsMessagesLogic) DeleteMessageBatch(ctx context.Context, req gosqs.DeleteMessageBatchRequest) (gosqs.DeleteMessageBatchResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMessageBatch", ctx, req)
+	ret0, _ := ret[0].(gosqs.DeleteMessageBatchResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteMessageBatch indicates an expected call of DeleteMessageBatch.
+func (mr *MockSqsMessagesLogicMockRecorder) DeleteMessageBatch(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMessageBatch", reflect.TypeOf((*MockSqsMessagesLogic)(nil).DeleteMessageBatch), ctx, req)
+}
+
+// ReceiveMessage mocks base method.
+func (m *MockSqsMessagesLogic) ReceiveMessage(ctx context.Context, options gosqs.RecMsgOptions) ([]gosqs.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReceiveMessage", ctx, options)
+	ret0, _ := ret[0].([]gosqs.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReceiveMessage indicates an expected call of ReceiveMessage.
+func (mr *MockSqsMessagesLogicMockRecorder) ReceiveMessage(ctx, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReceiveMessage", reflect.TypeOf((*MockSqsMessagesLogic)(nil).ReceiveMessage), ctx, options)
+}
+
+// SendMessage mocks base method.
+func (m *MockSqsMessagesLogic) SendMessage(ctx context.Context, options gosqs.SendMsgOptions) (gosqs.SendMsgResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMessage", ctx, options)
+	ret0, _ := ret[0].(gosqs.SendMsgResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMessage indicates an expected call of SendMessage.
+func (mr *MockSqsMessagesLogicMockRecorder) SendMessage(ctx, options any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessage", reflect.TypeOf((*MockSqsMessagesLogic)(nil).SendMessage), ctx, options)
+}
@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/ggarcia209/go-aws/v2/gosqs (interfaces: SqsQueuesLogic)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/gosqsmock/queues.go -package=gosqsmock . SqsQueuesLogic
+//
+
+// Package gosqsmock is a generated GoMock package.
+package gosqsmock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gosqs "github.com/ggarcia209/go-aws/v2/gosqs"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSqsQueuesLogic is a mock of SqsQueuesLogic interface.
+type MockSqsQueuesLogic struct {
+	ctrl     *gomock.Controller
+	recorder *MockSqsQueuesLogicMockRecorder
+	isgomock struct{}
+}
+
+// MockSqsQueuesLogicMockRecorder is the mock recorder for MockSqsQueuesLogic.
+type MockSqsQueuesLogicMockRecorder struct {
+	mock *MockSqsQueuesLogic
+}
+
+// NewMockSqsQueuesLogic creates a new mock instance.
+func NewMockSqsQueuesLogic(ctrl *gomock.Controller) *MockSqsQueuesLogic {
+	mock := &MockSqsQueuesLogic{ctrl: ctrl}
+	mock.recorder = &MockSqsQueuesLogicMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSqsQueuesLogic) EXPECT() *MockSqsQueuesLogicMockRecorder {
+	return m.recorder
+}
+
+// CreateQueue mocks base method.
+func (m *MockSqsQueuesLogic) CreateQueue(ctx context.Context, name string, options gosqs.QueueOptions, tags map[string]string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQueue", ctx, name, options, tags)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQueue indicates an expected call of CreateQueue.
+func (mr *MockSqsQueuesLogicMockRecorder) CreateQueue(ctx, name, options, tags any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQueue", reflect.TypeOf((*MockSqsQueuesLogic)(nil).CreateQueue), ctx, name, options, tags)
+}
+
+// DeleteQueue mocks base method.
+func (m *MockSqsQueuesLogic) DeleteQueue(ctx context.Context, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteQueue", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteQueue indicates an expected call of DeleteQueue.
+func (mr *MockSqsQueuesLogicMockRecorder) DeleteQueue(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteQueue", reflect.TypeOf((*MockSqsQueuesLogic)(nil).DeleteQueue), ctx, url)
+}
+
+// GetQueueURL mocks base method.
+func (m *MockSqsQueuesLogic) GetQueueURL(ctx context.Context, name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueueURL", ctx, name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueueURL indicates an expected call of GetQueueURL.
+func (mr *MockSqsQueuesLogicMockRecorder) GetQueueURL(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueueURL", reflect.TypeOf((*MockSqsQueuesLogic)(nil).GetQueueURL), ctx, name)
+}
+
+// PurgeQueue mocks base method.
+func (m *MockSqsQueuesLogic) PurgeQueue(ctx context.Context, url string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeQueue", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeQueue indicates an expected call of PurgeQueue.
+func (mr *MockSqsQueuesLogicMockRecorder) PurgeQueue(ctx, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeQueue", reflect.TypeOf((*MockSqsQueuesLogic)(nil).PurgeQueue), ctx, url)
+}